@@ -0,0 +1,77 @@
+package components
+
+import (
+	"github.com/erroneousboat/termui"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+// Browser lists the public channels the user can join, it replaces the
+// Channels component in the sidebar when browsing. Channels are loaded
+// a page at a time, and can be filtered by a search term.
+type Browser struct {
+	*Channels
+
+	Loaded     []ChannelItem // all the channels that have been loaded
+	NextCursor string        // cursor of the next page to load
+	Complete   bool          // whether all the pages have been loaded
+	Term       string        // the term the loaded channels are filtered by
+}
+
+// CreateBrowserComponent is the constructor for the Browser component
+func CreateBrowserComponent(height int) *Browser {
+	browser := &Browser{
+		Channels: &Channels{
+			List: termui.NewList(),
+		},
+		Loaded: make([]ChannelItem, 0),
+	}
+
+	browser.List.BorderLabel = "Browse channels"
+	browser.List.Height = height
+
+	browser.SelectedChannel = 0
+	browser.Offset = 0
+	browser.CursorPosition = browser.List.InnerBounds().Min.Y
+
+	return browser
+}
+
+// AddPage will add a page of loaded channels to the Browser, nextCursor
+// is the cursor of the page that follows, which is empty for the last page
+func (b *Browser) AddPage(channels []ChannelItem, nextCursor string) {
+	b.Loaded = append(b.Loaded, channels...)
+	b.NextCursor = nextCursor
+	b.Complete = nextCursor == ""
+	b.filter()
+}
+
+// Filter will only show the loaded channels of which the name matches the
+// term, an empty term will show all the loaded channels
+func (b *Browser) Filter(term string) {
+	b.Term = term
+	b.filter()
+	b.MoveCursorTop()
+}
+
+// RemoveChannel will remove the channel with channelID from the Browser
+func (b *Browser) RemoveChannel(channelID string) {
+	for i, channel := range b.Loaded {
+		if channel.ID == channelID {
+			b.Loaded = append(b.Loaded[:i], b.Loaded[i+1:]...)
+			break
+		}
+	}
+
+	b.Channels.RemoveChannel(channelID)
+}
+
+func (b *Browser) filter() {
+	channels := make([]ChannelItem, 0)
+	for _, channel := range b.Loaded {
+		if b.Term == "" || fuzzy.MatchFold(b.Term, channel.Name) {
+			channels = append(channels, channel)
+		}
+	}
+
+	b.SetChannels(channels)
+}
@@ -3,6 +3,8 @@ package components
 import (
 	"fmt"
 	"html"
+	"strings"
+	"time"
 
 	"github.com/erroneousboat/termui"
 	"github.com/lithammer/fuzzysearch/fuzzy"
@@ -16,6 +18,11 @@ const (
 	IconIM           = "●"
 	IconMpIM         = "☰"
 	IconNotification = "*"
+	IconMention      = "@"
+	IconExtShared    = "⇄"
+	IconSnooze       = "z"
+	IconDeactivated  = "⊘"
+	IconStar         = "★"
 
 	PresenceAway   = "away"
 	PresenceActive = "active"
@@ -26,83 +33,283 @@ const (
 	ChannelTypeMpIM    = "mpim"
 )
 
+// channelTypeOrder is the order in which the types of channels are
+// shown in the Channels component, after the starred channels
+var channelTypeOrder = map[string]int{
+	ChannelTypeChannel: 0,
+	ChannelTypeGroup:   1,
+	ChannelTypeMpIM:    2,
+	ChannelTypeIM:      3,
+}
+
 type ChannelItem struct {
 	ID           string
 	Name         string
 	Topic        string
+	Purpose      string
+	RealName     string
+	IsExtShared  bool
+	SharedTeams  []string
+	Workspaces   []string
 	Type         string
 	UserID       string
 	Presence     string
 	Notification bool
+	Mention      bool
+
+	// UnreadCount is the number of unread messages, it's 0 when it isn't
+	// known
+	UnreadCount int
+
+	// Muted channels are dimmed, and don't show badges. Snoozed channels
+	// don't show badges until SnoozedUntil.
+	Muted        bool
+	SnoozedUntil time.Time
+
+	// Deactivated is set for direct messages with a user that has been
+	// deactivated
+	Deactivated bool
+
+	// Starred channels are shown in a group of their own, before the
+	// channels of every type
+	Starred bool
+
+	// Position is the custom position of the channel among the channels
+	// of its type, channels without one (0) are ordered by name after the
+	// ones that have one
+	Position int
+
+	StylePrefix  string
+	StyleIcon    string
+	StyleText    string
+	StyleUnread  string
+	StyleMention string
+	StyleMuted   string
+
+	// StyleHeader and StyleBorder override the style of the header and
+	// the border of the Chat pane when the channel is selected
+	StyleHeader string
+	StyleBorder string
+
+	// IconUnread and IconMention replace the IconNotification and
+	// IconMention badges when they're set
+	IconUnread  string
+	IconMention string
+}
+
+// Before returns whether the channel is ordered before other, they're
+// expected to be in the same group, see group
+func (c ChannelItem) Before(other ChannelItem) bool {
+	// Starred channels of several types are in the same group
+	if c.Type != other.Type {
+		return channelTypeOrder[c.Type] < channelTypeOrder[other.Type]
+	}
+	if c.Position != other.Position {
+		if c.Position == 0 || other.Position == 0 {
+			return c.Position != 0
+		}
+		return c.Position < other.Position
+	}
+	return c.Name < other.Name
+}
 
-	StylePrefix string
-	StyleIcon   string
-	StyleText   string
+// group returns the order of the group in which the channel is shown,
+// starred channels are shown before the channels of every type
+func (c ChannelItem) group() int {
+	if c.Starred {
+		return -1
+	}
+	return channelTypeOrder[c.Type]
 }
 
 // ToString will set the label of the channel, how it will be
 // displayed on screen. Based on the type, different icons are
 // shown, as well as an optional notification icon.
 func (c ChannelItem) ToString() string {
-	var prefix string
-	if c.Notification {
+	// Channels in which the user is mentioned get a badge that is
+	// distinct from the one of channels that only have unread messages
+	prefix, stylePrefix := " ", c.StylePrefix
+	styleIcon, styleText := c.StyleIcon, c.StyleText
+	if c.Muted {
+		if c.StyleMuted != "" {
+			styleIcon, styleText = c.StyleMuted, c.StyleMuted
+		}
+	} else if c.IsSnoozed() {
+		prefix = IconSnooze
+	} else if c.Mention {
+		prefix, stylePrefix = IconMention, c.StyleMention
+		if c.IconMention != "" {
+			prefix = c.IconMention
+		}
+	} else if c.Notification {
 		prefix = IconNotification
-	} else {
-		prefix = " "
+		if c.IconUnread != "" {
+			prefix = c.IconUnread
+		}
+		if c.StyleUnread != "" {
+			stylePrefix = c.StyleUnread
+		}
+	} else if c.Starred {
+		prefix = IconStar
+	}
+
+	name := c.Name
+	if c.UnreadCount > 0 && c.HasUnread() {
+		name = fmt.Sprintf("%s (%d)", name, c.UnreadCount)
+	}
+
+	label := fmt.Sprintf(
+		"[%s](%s) [%s](%s) [%s](%s)",
+		prefix, stylePrefix,
+		c.GetIcon(), styleIcon,
+		name, styleText,
+	)
+
+	return label
+}
+
+// HasUnread returns whether the channel has unread messages, that aren't
+// hidden because the channel is muted or snoozed
+func (c ChannelItem) HasUnread() bool {
+	return (c.Notification || c.Mention) && !c.IsSilenced()
+}
+
+// IsSnoozed returns whether the channel is snoozed
+func (c ChannelItem) IsSnoozed() bool {
+	return time.Now().Before(c.SnoozedUntil)
+}
+
+// IsSilenced returns whether the channel doesn't create notifications,
+// because it's muted or snoozed
+func (c ChannelItem) IsSilenced() bool {
+	return c.Muted || c.IsSnoozed()
+}
+
+// GetIcon returns the icon of the channel, based on its type and the
+// presence of the user for direct messages
+func (c ChannelItem) GetIcon() string {
+	// Channels shared with external organizations get a distinct
+	// icon, to prevent accidental internal-only messages
+	if c.IsExtShared && (c.Type == ChannelTypeChannel || c.Type == ChannelTypeGroup) {
+		return IconExtShared
 	}
 
-	var icon string
 	switch c.Type {
 	case ChannelTypeChannel:
-		icon = IconChannel
+		return IconChannel
 	case ChannelTypeGroup:
-		icon = IconGroup
+		return IconGroup
 	case ChannelTypeMpIM:
-		icon = IconMpIM
+		return IconMpIM
 	case ChannelTypeIM:
+		if c.Deactivated {
+			return IconDeactivated
+		}
+
 		switch c.Presence {
 		case PresenceActive:
-			icon = IconOnline
+			return IconOnline
 		case PresenceAway:
-			icon = IconOffline
+			return IconOffline
 		default:
-			icon = IconIM
+			return IconIM
 		}
 	}
 
-	label := fmt.Sprintf(
-		"[%s](%s) [%s](%s) [%s](%s)",
-		prefix, c.StylePrefix,
-		icon, c.StyleIcon,
-		c.Name, c.StyleText,
-	)
+	return ""
+}
 
-	return label
+// matchesDescription will check whether the topic, purpose or real name
+// of the channel contain the term, case insensitive
+func (c ChannelItem) matchesDescription(term string) bool {
+	if term == "" {
+		return false
+	}
+
+	term = strings.ToLower(term)
+	for _, s := range []string{c.Topic, c.Purpose, c.RealName} {
+		if strings.Contains(strings.ToLower(html.UnescapeString(s)), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetName will return the name of the channel, together with the
+// workspaces and organizations it's shared with
+func (c ChannelItem) GetName() string {
+	name := c.Name
+	if len(c.Workspaces) > 0 {
+		name = fmt.Sprintf("%s [%s]", name, strings.Join(c.Workspaces, ", "))
+	}
+	if len(c.SharedTeams) > 0 {
+		name = fmt.Sprintf("%s %s %s",
+			name, IconExtShared, strings.Join(c.SharedTeams, ", "),
+		)
+	}
+	return name
 }
 
 // GetChannelName will return a formatted representation of the
 // name of the channel
 func (c ChannelItem) GetChannelName() string {
+	name := c.GetName()
+
 	var channelName string
 	if c.Topic != "" {
 		channelName = fmt.Sprintf("%s - %s",
-			html.UnescapeString(c.Name),
+			html.UnescapeString(name),
 			html.UnescapeString(c.Topic),
 		)
 	} else {
-		channelName = c.Name
+		channelName = name
 	}
 	return channelName
 }
 
+// The filters that restrict the channels that are shown in the Channels
+// component
+const (
+	FilterAll      = "all"
+	FilterChannels = "channels"
+	FilterIMs      = "ims"
+	FilterUnread   = "unread"
+)
+
+var channelFilters = map[string]func(ChannelItem) bool{
+	FilterChannels: func(c ChannelItem) bool {
+		return c.Type == ChannelTypeChannel || c.Type == ChannelTypeGroup
+	},
+	FilterIMs: func(c ChannelItem) bool {
+		return c.Type == ChannelTypeIM || c.Type == ChannelTypeMpIM
+	},
+	FilterUnread: func(c ChannelItem) bool {
+		return c.HasUnread()
+	},
+}
+
 // Channels is the definition of a Channels component
 type Channels struct {
 	ChannelItems    []ChannelItem
 	List            *termui.List
 	SelectedChannel int // index of which channel is selected from the List
-	Offset          int // from what offset are channels rendered
+	Offset          int // from what offset are visible channels rendered
 	CursorPosition  int // the y position of the 'cursor'
 
+	// Filter restricts the channels that are shown, the selected channel
+	// is always shown. Direct messages with deactivated users are only
+	// shown with ShowDeactivated.
+	Filter          string
+	ShowDeactivated bool
+
+	// HiddenTypes are the types of channels that aren't shown, see the
+	// ChannelType constants
+	HiddenTypes map[string]bool
+
+	// Title is shown in the border of the sidebar, e.g. the name of the
+	// workspace
+	Title string
+
 	SearchMatches  []int // index of the search matches
 	SearchPosition int   // current position of a search match
 }
@@ -110,10 +317,12 @@ type Channels struct {
 // CreateChannels is the constructor for the Channels component
 func CreateChannelsComponent(height int) *Channels {
 	channels := &Channels{
-		List: termui.NewList(),
+		List:        termui.NewList(),
+		HiddenTypes: make(map[string]bool),
+		Title:       "Channels",
 	}
 
-	channels.List.BorderLabel = "Channels"
+	channels.List.BorderLabel = channels.Title
 	channels.List.Height = height
 
 	channels.SelectedChannel = 0
@@ -127,7 +336,13 @@ func CreateChannelsComponent(height int) *Channels {
 func (c *Channels) Buffer() termui.Buffer {
 	buf := c.List.Buffer()
 
-	for i, item := range c.ChannelItems[c.Offset:] {
+	// The visible channels change with the filter, keep the selected
+	// channel in view
+	visible := c.visibleChannels()
+	c.scrollToSelected(visible)
+
+	for i, index := range visible[c.Offset:] {
+		item := c.ChannelItems[index]
 
 		y := c.List.InnerBounds().Min.Y + i
 
@@ -205,29 +420,185 @@ func (c *Channels) SetChannels(channels []ChannelItem) {
 	c.ChannelItems = channels
 }
 
+// AddChannel will insert a channel, keeping the channels sorted by group,
+// type and name. It returns the index of the added channel.
+func (c *Channels) AddChannel(channel ChannelItem) int {
+	index := len(c.ChannelItems)
+	for i, item := range c.ChannelItems {
+		if item.group() > channel.group() ||
+			(item.group() == channel.group() && channel.Before(item)) {
+			index = i
+			break
+		}
+	}
+
+	c.ChannelItems = append(c.ChannelItems, ChannelItem{})
+	copy(c.ChannelItems[index+1:], c.ChannelItems[index:])
+	c.ChannelItems[index] = channel
+
+	return index
+}
+
+// RemoveChannel will remove the channel with channelID
+func (c *Channels) RemoveChannel(channelID string) {
+	for i, channel := range c.ChannelItems {
+		if channel.ID == channelID {
+			c.ChannelItems = append(c.ChannelItems[:i], c.ChannelItems[i+1:]...)
+
+			// The selected channel stays selected when a channel
+			// above it is removed
+			if i < c.SelectedChannel {
+				c.SelectedChannel--
+			}
+			break
+		}
+	}
+
+	if c.SelectedChannel > len(c.ChannelItems)-1 && c.SelectedChannel > 0 {
+		c.MoveCursorBottom()
+	}
+}
+
+// AddChannels will add the channels that aren't in the list yet, the
+// selected channel stays selected
+func (c *Channels) AddChannels(channels []ChannelItem) {
+	var selected string
+	if len(c.ChannelItems) > 0 {
+		selected = c.GetSelectedChannel().ID
+	}
+
+	current := make(map[string]bool)
+	for _, channel := range c.ChannelItems {
+		current[channel.ID] = true
+	}
+
+	for _, channel := range channels {
+		if !current[channel.ID] {
+			c.AddChannel(channel)
+		}
+	}
+
+	c.GotoChannel(selected)
+}
+
+// UpdateChannels will add the channels that aren't in the list yet, and
+// remove the ones that are no longer present in channels. Channels that are
+// in both keep their state, and the selected channel stays selected when it
+// is still present. It returns whether it is, otherwise the channel that
+// took its place is selected.
+func (c *Channels) UpdateChannels(channels []ChannelItem) bool {
+	var selected string
+	if len(c.ChannelItems) > 0 {
+		selected = c.GetSelectedChannel().ID
+	}
+
+	present := make(map[string]bool)
+	for _, channel := range channels {
+		present[channel.ID] = true
+	}
+
+	var removed []string
+	for _, channel := range c.ChannelItems {
+		if !present[channel.ID] {
+			removed = append(removed, channel.ID)
+		}
+	}
+	for _, channelID := range removed {
+		c.RemoveChannel(channelID)
+	}
+
+	c.AddChannels(channels)
+	return c.GotoChannel(selected)
+}
+
+// SetStarred will star or unstar the channel with channelID, which moves it
+// to or from the group of starred channels. The selected channel stays
+// selected.
+func (c *Channels) SetStarred(channelID string, starred bool) {
+	index := c.FindChannel(channelID)
+	if index == -1 || c.ChannelItems[index].Starred == starred {
+		return
+	}
+
+	selected := c.GetSelectedChannel().ID
+
+	channel := c.ChannelItems[index]
+	channel.Starred = starred
+	c.ChannelItems = append(c.ChannelItems[:index], c.ChannelItems[index+1:]...)
+	c.AddChannel(channel)
+
+	c.GotoChannel(selected)
+}
+
 func (c *Channels) MarkAsRead(channelID int) {
 	c.ChannelItems[channelID].Notification = false
+	c.ChannelItems[channelID].Mention = false
+	c.ChannelItems[channelID].UnreadCount = 0
+}
+
+// SetUnreadCount will set the number of unread messages of a channel
+func (c *Channels) SetUnreadCount(channelID string, count int) {
+	if index := c.FindChannel(channelID); index != -1 {
+		c.ChannelItems[index].UnreadCount = count
+	}
+}
+
+// IncrementUnreadCount will add a message to the number of unread messages
+// of a channel
+func (c *Channels) IncrementUnreadCount(channelID string) {
+	if index := c.FindChannel(channelID); index != -1 {
+		c.ChannelItems[index].UnreadCount++
+	}
 }
 
 func (c *Channels) MarkAsUnread(channelID string) {
-	index := c.FindChannel(channelID)
-	c.ChannelItems[index].Notification = true
+	if index := c.FindChannel(channelID); index != -1 {
+		c.ChannelItems[index].Notification = true
+	}
+}
+
+// MarkAsMentioned will mark the channel as unread, with a message that
+// mentions the user
+func (c *Channels) MarkAsMentioned(channelID string) {
+	if index := c.FindChannel(channelID); index != -1 {
+		c.ChannelItems[index].Notification = true
+		c.ChannelItems[index].Mention = true
+	}
+}
+
+// GetUnreadSummary returns the number of channels with unread messages, and
+// the number of channels in which the user is mentioned
+func (c *Channels) GetUnreadSummary() (int, int) {
+	var unread, mentions int
+	for _, channel := range c.ChannelItems {
+		if !channel.HasUnread() {
+			continue
+		}
+		if channel.Notification {
+			unread++
+		}
+		if channel.Mention {
+			mentions++
+		}
+	}
+	return unread, mentions
 }
 
 func (c *Channels) SetPresence(channelID string, presence string) {
-	index := c.FindChannel(channelID)
-	c.ChannelItems[index].Presence = presence
+	if index := c.FindChannel(channelID); index != -1 {
+		c.ChannelItems[index].Presence = presence
+	}
 }
 
+// FindChannel returns the index of the channel with channelID, it's -1 when
+// the channel isn't present in the channels component
 func (c *Channels) FindChannel(channelID string) int {
-	var index int
 	for i, channel := range c.ChannelItems {
 		if channel.ID == channelID {
-			index = i
-			break
+			return i
 		}
 	}
-	return index
+	return -1
 }
 
 // SetSelectedChannel sets the SelectedChannel given the index
@@ -240,90 +611,211 @@ func (c *Channels) GetSelectedChannel() ChannelItem {
 	return c.ChannelItems[c.SelectedChannel]
 }
 
-// MoveCursorUp will decrease the SelectedChannel by 1
+// MoveCursorUp will move the cursor to the visible channel above the
+// selected channel
 func (c *Channels) MoveCursorUp() {
-	if c.SelectedChannel > 0 {
-		c.SetSelectedChannel(c.SelectedChannel - 1)
-		c.ScrollUp()
+	visible := c.visibleChannels()
+	if pos := position(visible, c.SelectedChannel); pos > 0 {
+		c.GotoPosition(visible[pos-1])
 	}
 }
 
-// MoveCursorDown will increase the SelectedChannel by 1
+// MoveCursorDown will move the cursor to the visible channel below the
+// selected channel
 func (c *Channels) MoveCursorDown() {
-	if c.SelectedChannel < len(c.ChannelItems)-1 {
-		c.SetSelectedChannel(c.SelectedChannel + 1)
-		c.ScrollDown()
+	visible := c.visibleChannels()
+	if pos := position(visible, c.SelectedChannel); pos < len(visible)-1 {
+		c.GotoPosition(visible[pos+1])
 	}
 }
 
 // MoveCursorTop will move the cursor to the top of the channels
 func (c *Channels) MoveCursorTop() {
-	c.SetSelectedChannel(0)
-	c.CursorPosition = c.List.InnerBounds().Min.Y
-	c.Offset = 0
+	if visible := c.visibleChannels(); len(visible) > 0 {
+		c.GotoPosition(visible[0])
+	}
 }
 
 // MoveCursorBottom will move the cursor to the bottom of the channels
 func (c *Channels) MoveCursorBottom() {
-	c.SetSelectedChannel(len(c.ChannelItems) - 1)
+	if visible := c.visibleChannels(); len(visible) > 0 {
+		c.GotoPosition(visible[len(visible)-1])
+	}
+}
 
-	offset := len(c.ChannelItems) - (c.List.InnerBounds().Max.Y - 1)
+// SetFilter will restrict the channels that are shown, see the Filter
+// constants. When the selected channel doesn't pass the filter the first
+// one that does is selected.
+func (c *Channels) SetFilter(filter string) error {
+	if _, ok := channelFilters[filter]; !ok && filter != FilterAll {
+		return fmt.Errorf("unknown filter: %s", filter)
+	}
 
-	if offset < 0 {
-		c.Offset = 0
-		c.CursorPosition = c.SelectedChannel + 1
-	} else {
-		c.Offset = offset
-		c.CursorPosition = c.List.InnerBounds().Max.Y - 1
+	c.Filter = filter
+	c.setBorderLabel()
+	c.selectVisible()
+
+	return nil
+}
+
+// SetTypeHidden will hide or show the channels of channelType, see the
+// ChannelType constants. When the selected channel is hidden the first
+// channel that is shown is selected.
+func (c *Channels) SetTypeHidden(channelType string, hidden bool) error {
+	if _, ok := channelTypeOrder[channelType]; !ok {
+		return fmt.Errorf("unknown channel type: %s", channelType)
 	}
+
+	c.HiddenTypes[channelType] = hidden
+	c.setBorderLabel()
+	c.selectVisible()
+
+	return nil
+}
+
+// SetTitle will set the Title that is shown in the border of the sidebar
+func (c *Channels) SetTitle(title string) {
+	c.Title = title
+	c.setBorderLabel()
 }
 
-// ScrollUp enables us to scroll through the channel list when it overflows
-func (c *Channels) ScrollUp() {
-	// Is cursor at the top of the channel view?
-	if c.CursorPosition == c.List.InnerBounds().Min.Y {
-		if c.Offset > 0 {
-			c.Offset--
+// IsTypeHidden returns whether the channels of channelType are hidden
+func (c *Channels) IsTypeHidden(channelType string) bool {
+	return c.HiddenTypes[channelType]
+}
+
+// setBorderLabel will set the label to the Title, the Filter and the types
+// of channels that are hidden, e.g. "Channels (unread, -im)"
+func (c *Channels) setBorderLabel() {
+	var parts []string
+	if c.Filter != FilterAll && c.Filter != "" {
+		parts = append(parts, c.Filter)
+	}
+
+	types := []string{
+		ChannelTypeChannel, ChannelTypeGroup, ChannelTypeMpIM, ChannelTypeIM,
+	}
+	for _, channelType := range types {
+		if c.HiddenTypes[channelType] {
+			parts = append(parts, "-"+channelType)
 		}
+	}
+
+	if len(parts) == 0 {
+		c.List.BorderLabel = c.Title
 	} else {
-		c.CursorPosition--
+		c.List.BorderLabel = fmt.Sprintf("%s (%s)", c.Title, strings.Join(parts, ", "))
 	}
 }
 
-// ScrollDown enables us to scroll through the channel list when it overflows
-func (c *Channels) ScrollDown() {
-	// Is the cursor at the bottom of the channel view?
-	if c.CursorPosition == c.List.InnerBounds().Max.Y-1 {
-		if c.Offset < len(c.ChannelItems)-1 {
-			c.Offset++
+// selectVisible will select the first channel that is shown, when the
+// selected channel isn't
+func (c *Channels) selectVisible() {
+	if len(c.ChannelItems) == 0 || c.isVisible(c.GetSelectedChannel()) {
+		return
+	}
+
+	for i, item := range c.ChannelItems {
+		if c.isVisible(item) {
+			c.GotoPosition(i)
+			break
 		}
-	} else {
-		c.CursorPosition++
 	}
 }
 
+// isVisible returns whether the channel passes the Filter, and its type
+// isn't hidden
+func (c *Channels) isVisible(item ChannelItem) bool {
+	if item.Deactivated && !c.ShowDeactivated {
+		return false
+	}
+
+	if c.HiddenTypes[item.Type] {
+		return false
+	}
+
+	filter, ok := channelFilters[c.Filter]
+	return !ok || filter(item)
+}
+
+// visibleChannels returns the indexes of the channels that are shown
+func (c *Channels) visibleChannels() []int {
+	visible := make([]int, 0, len(c.ChannelItems))
+	for i, item := range c.ChannelItems {
+		if i == c.SelectedChannel || c.isVisible(item) {
+			visible = append(visible, i)
+		}
+	}
+	return visible
+}
+
+// position returns the position of the channel index among the visible
+// channels
+func position(visible []int, index int) int {
+	for pos, i := range visible {
+		if i == index {
+			return pos
+		}
+	}
+	return -1
+}
+
+// scrollToSelected will set the Offset so the selected channel is in view,
+// and place the cursor on it
+func (c *Channels) scrollToSelected(visible []int) {
+	pos := position(visible, c.SelectedChannel)
+	height := c.List.InnerHeight()
+
+	if pos < c.Offset {
+		c.Offset = pos
+	} else if pos > c.Offset+height-1 {
+		c.Offset = pos - height + 1
+	}
+	if c.Offset > len(visible)-1 {
+		c.Offset = len(visible) - 1
+	}
+	if c.Offset < 0 {
+		c.Offset = 0
+	}
+
+	c.CursorPosition = c.List.InnerBounds().Min.Y + pos - c.Offset
+}
+
 // Search will search through the channels to find a channel,
 // when a match has been found the selected channel will then
-// be the channel that has been found
+// be the channel that has been found. Channel names are fuzzy
+// matched, after those follow the channels of which the topic,
+// purpose or real name of the user contains the term.
 func (c *Channels) Search(term string) {
 	c.SearchMatches = make([]int, 0)
 
+	// Only the channels that pass the filter are searched
 	targets := make([]string, 0)
-	for _, c := range c.ChannelItems {
-		targets = append(targets, c.Name)
+	for _, item := range c.ChannelItems {
+		if c.isVisible(item) {
+			targets = append(targets, item.Name)
+		}
 	}
 
 	matches := fuzzy.Find(term, targets)
 
+	matched := make(map[int]bool)
 	for _, m := range matches {
 		for i, item := range c.ChannelItems {
-			if m == item.Name {
+			if m == item.Name && c.isVisible(item) {
 				c.SearchMatches = append(c.SearchMatches, i)
+				matched[i] = true
 				break
 			}
 		}
 	}
 
+	for i, item := range c.ChannelItems {
+		if !matched[i] && c.isVisible(item) && item.matchesDescription(term) {
+			c.SearchMatches = append(c.SearchMatches, i)
+		}
+	}
+
 	if len(c.SearchMatches) > 0 {
 		c.GotoPositionSearch(0)
 		c.SearchPosition = 0
@@ -333,30 +825,11 @@ func (c *Channels) Search(term string) {
 // GotoPosition is used by to automatically scroll to a specific
 // location in the channels component
 func (c *Channels) GotoPosition(newPos int) {
+	c.SetSelectedChannel(newPos)
 
-	// Is the new position in range of the current view?
-	minRange := c.Offset
-	maxRange := c.Offset + (c.List.InnerBounds().Max.Y - 2)
-
-	if newPos < minRange {
-		// newPos is above, we need to scroll up.
-		c.SetSelectedChannel(newPos)
-
-		// How much do we need to scroll to get it into range?
-		c.Offset = c.Offset - (minRange - newPos)
-	} else if newPos > maxRange {
-		// newPos is below, we need to scroll down
-		c.SetSelectedChannel(newPos)
-
-		// How much do we need to scroll to get it into range?
-		c.Offset = c.Offset + (newPos - maxRange)
-	} else {
-		// newPos is inside range
-		c.SetSelectedChannel(newPos)
-	}
-
-	// Set cursor to correct position
-	c.CursorPosition = (newPos - c.Offset) + 1
+	// Scroll so the channel is in range of the current view, and set
+	// the cursor to the correct position
+	c.scrollToSelected(c.visibleChannels())
 }
 
 // GotoPosition is used by the search functionality to automatically
@@ -384,10 +857,98 @@ func (c *Channels) SearchPrev() {
 	}
 }
 
-// Jump to the first channel with a notification
+// GotoChannel will move the cursor to the channel with channelID, it
+// returns false when the channel isn't present in the channels component
+func (c *Channels) GotoChannel(channelID string) bool {
+	for i, channel := range c.ChannelItems {
+		if channel.ID == channelID {
+			c.GotoPosition(i)
+			return true
+		}
+	}
+	return false
+}
+
+// MoveChannelUp will swap the selected channel with the channel that is
+// shown above it when it is of the same type and group. It returns the ids
+// of the channels of that type in their new order, or nil when the channel
+// wasn't moved.
+func (c *Channels) MoveChannelUp() []string {
+	return c.moveChannel(-1)
+}
+
+// MoveChannelDown will swap the selected channel with the channel that is
+// shown below it when it is of the same type and group, see MoveChannelUp.
+func (c *Channels) MoveChannelDown() []string {
+	return c.moveChannel(1)
+}
+
+// moveChannel will swap the selected channel with the nearest channel in
+// direction that is shown, the channels that are hidden in between keep
+// their place
+func (c *Channels) moveChannel(direction int) []string {
+	if len(c.ChannelItems) == 0 {
+		return nil
+	}
+
+	index := c.SelectedChannel + direction
+	for index >= 0 && index < len(c.ChannelItems) && !c.isVisible(c.ChannelItems[index]) {
+		index += direction
+	}
+
+	if index < 0 || index >= len(c.ChannelItems) ||
+		c.ChannelItems[index].Type != c.GetSelectedChannel().Type ||
+		c.ChannelItems[index].Starred != c.GetSelectedChannel().Starred {
+		return nil
+	}
+
+	c.ChannelItems[index], c.ChannelItems[c.SelectedChannel] =
+		c.ChannelItems[c.SelectedChannel], c.ChannelItems[index]
+	c.GotoPosition(index)
+
+	// All the channels of the type get a position, so the order is kept
+	// when channels are added
+	var channelIDs []string
+	for i, channel := range c.ChannelItems {
+		if channel.Type == c.ChannelItems[index].Type {
+			channelIDs = append(channelIDs, channel.ID)
+			c.ChannelItems[i].Position = len(channelIDs)
+		}
+	}
+
+	return channelIDs
+}
+
+// JumpNext will move the cursor to the next channel with a notification
+// that is shown, when there is none after the selected channel it wraps
+// around
+func (c *Channels) JumpNext() {
+	for i := 1; i < len(c.ChannelItems); i++ {
+		index := (c.SelectedChannel + i) % len(c.ChannelItems)
+		if c.ChannelItems[index].HasUnread() && c.isVisible(c.ChannelItems[index]) {
+			c.GotoPosition(index)
+			return
+		}
+	}
+}
+
+// JumpPrevious will move the cursor to the previous channel with a
+// notification that is shown, when there is none before the selected
+// channel it wraps around
+func (c *Channels) JumpPrevious() {
+	for i := 1; i < len(c.ChannelItems); i++ {
+		index := (c.SelectedChannel - i + len(c.ChannelItems)) % len(c.ChannelItems)
+		if c.ChannelItems[index].HasUnread() && c.isVisible(c.ChannelItems[index]) {
+			c.GotoPosition(index)
+			return
+		}
+	}
+}
+
+// Jump to the first channel with a notification that is shown
 func (c *Channels) Jump() {
 	for i, channel := range c.ChannelItems {
-		if channel.Notification {
+		if channel.HasUnread() && c.isVisible(channel) {
 			c.GotoPosition(i)
 			break
 		}
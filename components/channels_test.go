@@ -0,0 +1,134 @@
+package components
+
+import (
+	"testing"
+)
+
+// newTestChannels creates the Channels component with the channels a to e
+func newTestChannels() *Channels {
+	channels := CreateChannelsComponent(20)
+	channels.SetChannels([]ChannelItem{
+		{ID: "C1", Name: "a", Type: ChannelTypeChannel},
+		{ID: "C2", Name: "b", Type: ChannelTypeChannel},
+		{ID: "C3", Name: "c", Type: ChannelTypeChannel},
+		{ID: "C4", Name: "d", Type: ChannelTypeChannel},
+		{ID: "C5", Name: "e", Type: ChannelTypeChannel},
+	})
+	return channels
+}
+
+func TestRemoveChannel(t *testing.T) {
+	channels := newTestChannels()
+	channels.SetSelectedChannel(2)
+
+	channels.RemoveChannel("C1")
+	if selected := channels.GetSelectedChannel().ID; selected != "C3" {
+		t.Errorf("%s is selected after a channel above it is removed, expected C3", selected)
+	}
+
+	channels.RemoveChannel("C5")
+	if selected := channels.GetSelectedChannel().ID; selected != "C3" {
+		t.Errorf("%s is selected after a channel below it is removed, expected C3", selected)
+	}
+}
+
+func TestUpdateChannels(t *testing.T) {
+	channels := newTestChannels()
+	channels.SetSelectedChannel(2)
+
+	update := []ChannelItem{
+		{ID: "C2", Name: "b", Type: ChannelTypeChannel},
+		{ID: "C3", Name: "c", Type: ChannelTypeChannel},
+		{ID: "C6", Name: "f", Type: ChannelTypeChannel},
+	}
+	if !channels.UpdateChannels(update) {
+		t.Error("UpdateChannels returned that the selected channel has been removed")
+	}
+	if selected := channels.GetSelectedChannel().ID; selected != "C3" {
+		t.Errorf("%s is selected after the update, expected C3", selected)
+	}
+
+	update = update[:1]
+	if channels.UpdateChannels(update) {
+		t.Error("UpdateChannels returned that the removed selected channel is present")
+	}
+	if selected := channels.GetSelectedChannel().ID; selected != "C2" {
+		t.Errorf("%s is selected after the selected channel is removed, expected C2", selected)
+	}
+}
+
+func TestFindChannel(t *testing.T) {
+	channels := newTestChannels()
+
+	if index := channels.FindChannel("C3"); index != 2 {
+		t.Errorf("FindChannel(C3) = %d, expected 2", index)
+	}
+	if index := channels.FindChannel("C9"); index != -1 {
+		t.Errorf("FindChannel(C9) = %d, expected -1", index)
+	}
+
+	// A channel that isn't present doesn't mark another channel
+	channels.MarkAsUnread("C9")
+	channels.MarkAsMentioned("C9")
+	channels.SetUnreadCount("C9", 3)
+	for _, channel := range channels.ChannelItems {
+		if channel.Notification || channel.Mention || channel.UnreadCount != 0 {
+			t.Errorf("%s is marked, expected only C9 to be marked", channel.ID)
+		}
+	}
+}
+
+func TestJumpSkipsHiddenChannels(t *testing.T) {
+	channels := CreateChannelsComponent(20)
+	channels.SetChannels([]ChannelItem{
+		{ID: "C1", Name: "a", Type: ChannelTypeChannel, Notification: true},
+		{ID: "C2", Name: "b", Type: ChannelTypeChannel},
+		{ID: "D1", Name: "c", Type: ChannelTypeIM, Notification: true},
+		{ID: "C3", Name: "d", Type: ChannelTypeChannel, Notification: true},
+	})
+	if err := channels.SetTypeHidden(ChannelTypeIM, true); err != nil {
+		t.Fatal(err)
+	}
+	channels.GotoChannel("C2")
+
+	channels.JumpNext()
+	if selected := channels.GetSelectedChannel().ID; selected != "C3" {
+		t.Errorf("JumpNext selected %s, expected C3", selected)
+	}
+
+	channels.JumpPrevious()
+	if selected := channels.GetSelectedChannel().ID; selected != "C1" {
+		t.Errorf("JumpPrevious selected %s, expected C1", selected)
+	}
+}
+
+func TestMoveChannelSkipsHiddenChannels(t *testing.T) {
+	channels := CreateChannelsComponent(20)
+	channels.SetChannels([]ChannelItem{
+		{ID: "C1", Name: "a", Type: ChannelTypeChannel, Notification: true},
+		{ID: "C2", Name: "b", Type: ChannelTypeChannel},
+		{ID: "C3", Name: "c", Type: ChannelTypeChannel, Notification: true},
+	})
+	if err := channels.SetFilter(FilterUnread); err != nil {
+		t.Fatal(err)
+	}
+	channels.GotoChannel("C3")
+
+	order := channels.MoveChannelUp()
+	expected := []string{"C3", "C2", "C1"}
+	if len(order) != len(expected) {
+		t.Fatalf("MoveChannelUp order %v, expected %v", order, expected)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("MoveChannelUp order %v, expected %v", order, expected)
+		}
+	}
+	if selected := channels.GetSelectedChannel().ID; selected != "C3" {
+		t.Errorf("%s is selected after the move, expected C3", selected)
+	}
+
+	if order := channels.MoveChannelUp(); order != nil {
+		t.Errorf("MoveChannelUp of the first channel that is shown moved it to %v", order)
+	}
+}
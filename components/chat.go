@@ -2,9 +2,11 @@ package components
 
 import (
 	"fmt"
+	"html"
 	"sort"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/erroneousboat/termui"
 	runewidth "github.com/mattn/go-runewidth"
@@ -17,6 +19,53 @@ type Chat struct {
 	List     *termui.List
 	Messages map[string]Message
 	Offset   int
+
+	// DateFormat is the layout of the separators between the messages of
+	// different days, they're not shown when it's empty
+	DateFormat string
+
+	// Header is shown above the messages, with the icon, name and topic
+	// of the channel. It's not shown when it's empty. The style of the
+	// channel overrides StyleHeader.
+	Header      string
+	StyleHeader string
+	styleHeader string
+
+	// Consecutive messages of the same user that are at most GroupMinutes
+	// apart are grouped, only the first one shows the name of the user.
+	// Grouping is disabled when it's 0. ShowSeconds adds the seconds to
+	// the time of the messages.
+	GroupMinutes int
+	ShowSeconds  bool
+
+	// NewMessages is the number of messages that have been added while
+	// the Chat pane was scrolled up
+	NewMessages int
+
+	// The attachments of messages are collapsed into a summary, unless
+	// ExpandAttachments is set. expanded are the ids of the messages of
+	// which this has been toggled.
+	ExpandAttachments bool
+	expanded          map[string]bool
+
+	// Preview is the message that is being composed, as it will be shown
+	// once it's sent. It's shown below the messages when it's set.
+	Preview *Message
+
+	// Selected is the id of the message that is selected in select mode,
+	// it's highlighted
+	Selected string
+
+	// Gap is the id of the message after which messages are missing,
+	// e.g. after being offline for a while. A marker is shown below it
+	// until the missed messages have been fetched.
+	Gap string
+
+	// Find is the term that is searched for in the messages that have
+	// been loaded, its matches are highlighted. found is the id of the
+	// message that was jumped to last.
+	Find  string
+	found string
 }
 
 // CreateChatComponent is the constructor for the Chat struct
@@ -25,6 +74,7 @@ func CreateChatComponent(inputHeight int) *Chat {
 		List:     termui.NewList(),
 		Messages: make(map[string]Message),
 		Offset:   0,
+		expanded: make(map[string]bool),
 	}
 
 	chat.List.Height = termui.TermHeight() - inputHeight
@@ -33,19 +83,26 @@ func CreateChatComponent(inputHeight int) *Chat {
 	return chat
 }
 
-// Buffer implements interface termui.Bufferer
-func (c *Chat) Buffer() termui.Buffer {
+// Line is a single line of cells, within the bounds of the Chat pane
+type Line struct {
+	cells []termui.Cell
+}
+
+// Lines will convert the Messages into the lines that are rendered in the
+// Chat pane
+func (c *Chat) Lines() []Line {
 	// Convert Messages into termui.Cell
 	cells := c.MessagesToCells(c.Messages)
 
+	if c.Preview != nil {
+		cells = append(cells, termui.Cell{Ch: '\n'})
+		cells = append(cells, c.PreviewToCells(*c.Preview)...)
+	}
+
 	// We will create an array of Line structs, this allows us
 	// to more easily render the items in a list. We will range
 	// over the cells we've created and create a Line within
 	// the bounds of the Chat pane
-	type Line struct {
-		cells []termui.Cell
-	}
-
 	lines := []Line{}
 	line := Line{}
 
@@ -80,6 +137,13 @@ func (c *Chat) Buffer() termui.Buffer {
 	// newlines or were at the bounds of the chat view
 	lines = append(lines, line)
 
+	return lines
+}
+
+// Buffer implements interface termui.Bufferer
+func (c *Chat) Buffer() termui.Buffer {
+	lines := c.Lines()
+
 	// We will print lines bottom up, it will loop over the lines
 	// backwards and for every line it'll set the cell in that line.
 	// Offset is the number which allows us to begin printing the
@@ -89,6 +153,12 @@ func (c *Chat) Buffer() termui.Buffer {
 	paneMinY := c.List.InnerBounds().Min.Y
 	paneMaxY := c.List.InnerBounds().Max.Y
 
+	// The header takes the first line of the pane
+	if c.Header != "" {
+		c.headerToBuffer(buf, paneMinY)
+		paneMinY++
+	}
+
 	currentY := paneMaxY - 1
 	for i := (linesHeight - 1) - c.Offset; i >= 0; i-- {
 
@@ -137,9 +207,82 @@ func (c *Chat) Buffer() termui.Buffer {
 		currentY--
 	}
 
+	// Show that there are new messages below, when scrolled up
+	if c.NewMessages > 0 && c.Offset > 0 {
+		c.newMessagesToBuffer(buf, paneMaxY-1)
+	}
+
 	return buf
 }
 
+// newMessagesToBuffer will set the indicator of the new messages on the
+// right of line y, e.g. "↓ 3 new messages"
+func (c *Chat) newMessagesToBuffer(buf termui.Buffer, y int) {
+	text := fmt.Sprintf(" ↓ %d new messages ", c.NewMessages)
+	if c.NewMessages == 1 {
+		text = " ↓ 1 new message "
+	}
+
+	x := c.List.InnerBounds().Max.X - runewidth.StringWidth(text)
+	if x < c.List.InnerBounds().Min.X {
+		x = c.List.InnerBounds().Min.X
+	}
+
+	for _, r := range text {
+		if x+runewidth.RuneWidth(r) > c.List.InnerBounds().Max.X {
+			break
+		}
+
+		buf.Set(
+			x, y,
+			termui.Cell{
+				Ch: r,
+				Fg: c.List.ItemFgColor,
+				Bg: c.List.ItemBgColor | termui.AttrReverse,
+			},
+		)
+		x += runewidth.RuneWidth(r)
+	}
+}
+
+// headerToBuffer will set the cells of the Header on line y, it's
+// truncated when it doesn't fit
+func (c *Chat) headerToBuffer(buf termui.Buffer, y int) {
+	styleHeader := c.StyleHeader
+	if c.styleHeader != "" {
+		styleHeader = c.styleHeader
+	}
+
+	fg, bg := c.List.ItemFgColor, c.List.ItemBgColor
+	if styleHeader != "" {
+		style := termui.DefaultTxBuilder.Build(
+			fmt.Sprintf("[.](%s)", styleHeader), fg, bg,
+		)[0]
+		fg, bg = style.Fg, style.Bg
+	}
+
+	minX := c.List.InnerBounds().Min.X
+	maxX := c.List.InnerBounds().Max.X
+
+	x := minX
+	for _, r := range runewidth.Truncate(c.Header, maxX-minX, "…") {
+		buf.Set(x, y, termui.Cell{Ch: r, Fg: fg, Bg: bg})
+		x += runewidth.RuneWidth(r)
+	}
+
+	for x < maxX {
+		buf.Set(
+			x, y,
+			termui.Cell{
+				Ch: ' ',
+				Fg: c.List.ItemFgColor,
+				Bg: c.List.ItemBgColor,
+			},
+		)
+		x += runewidth.RuneWidth(' ')
+	}
+}
+
 // GetHeight implements interface termui.GridBufferer
 func (c *Chat) GetHeight() int {
 	return c.List.Block.GetHeight()
@@ -163,7 +306,11 @@ func (c *Chat) SetY(y int) {
 // GetMaxItems return the maximal amount of items can fit in the Chat
 // component
 func (c *Chat) GetMaxItems() int {
-	return c.List.InnerBounds().Max.Y - c.List.InnerBounds().Min.Y
+	maxItems := c.List.InnerBounds().Max.Y - c.List.InnerBounds().Min.Y
+	if c.Header != "" {
+		maxItems--
+	}
+	return maxItems
 }
 
 // SetMessages will put the provided messages into the Messages field of the
@@ -172,14 +319,22 @@ func (c *Chat) SetMessages(messages []Message) {
 	// Reset offset first, when scrolling in view and changing channels we
 	// want the offset to be 0 when loading new messages
 	c.Offset = 0
+	c.NewMessages = 0
 	for _, msg := range messages {
 		c.Messages[msg.ID] = msg
 	}
 }
 
-// AddMessage adds a single message to Messages
+// AddMessage adds a single message to Messages, or replaces it when it is
+// already present
 func (c *Chat) AddMessage(message Message) {
-	c.Messages[message.ID] = message
+	if _, ok := c.Messages[message.ID]; !ok && c.Offset > 0 {
+		c.NewMessages++
+	}
+
+	c.keepScrollPosition(func() {
+		c.Messages[message.ID] = message
+	})
 }
 
 // AddReply adds a single reply to a parent thread, it also sets
@@ -187,28 +342,228 @@ func (c *Chat) AddMessage(message Message) {
 func (c *Chat) AddReply(parentID string, message Message) {
 	// It is possible that a message is received but the parent is not
 	// present in the chat view
-	if _, ok := c.Messages[parentID]; ok {
-		message.Thread = "  "
-		c.Messages[parentID].Messages[message.ID] = message
-	} else {
+	parent, ok := c.Messages[parentID]
+	if !ok {
 		c.AddMessage(message)
+		return
 	}
-}
 
-// IsNewThread check whether a message that is going to be added as
-// a child to a parent message, is the first one or not
-func (c *Chat) IsNewThread(parentID string) bool {
-	if parent, ok := c.Messages[parentID]; ok {
-		if len(parent.Messages) > 0 {
-			return true
+	c.keepScrollPosition(func() {
+		if _, ok := parent.Messages[message.ID]; !ok {
+			parent.ReplyCount++
 		}
+
+		message.Thread = "  "
+		parent.Messages[message.ID] = message
+		c.Messages[parentID] = parent
+	})
+}
+
+// keepScrollPosition will keep the lines that are visible in place when
+// update adds lines, and the Chat pane is scrolled up. When scrolled down
+// new lines will be shown at the bottom.
+func (c *Chat) keepScrollPosition(update func()) {
+	if c.Offset == 0 {
+		update()
+		return
 	}
-	return false
+
+	before := len(c.Lines())
+	update()
+	c.Offset += len(c.Lines()) - before
+}
+
+// SetPreview will show the preview of the message that is being composed,
+// or hides it when msg is nil
+func (c *Chat) SetPreview(msg *Message) {
+	c.keepScrollPosition(func() {
+		c.Preview = msg
+	})
 }
 
 // ClearMessages clear the c.Messages
 func (c *Chat) ClearMessages() {
 	c.Messages = make(map[string]Message)
+	c.NewMessages = 0
+	c.Selected = ""
+	c.Gap = ""
+	c.found = ""
+}
+
+// FillGap will add the missed messages of the gap, the newest of them when
+// not all of them have been fetched. The marker is removed when complete is
+// set.
+func (c *Chat) FillGap(messages []Message, complete bool) {
+	c.keepScrollPosition(func() {
+		for _, msg := range messages {
+			if _, ok := c.Messages[msg.ID]; !ok {
+				c.Messages[msg.ID] = msg
+			}
+		}
+
+		if complete {
+			c.Gap = ""
+		}
+	})
+}
+
+// GetGapEnd returns the id of the first message after the gap, it's empty
+// when there is no gap
+func (c *Chat) GetGapEnd() string {
+	if c.Gap == "" {
+		return ""
+	}
+
+	for _, msg := range SortMessages(c.Messages) {
+		if msg.ID > c.Gap {
+			return msg.ID
+		}
+	}
+	return ""
+}
+
+// RemoveMessage will remove a message, or a reply from its thread. It
+// returns false when the message isn't present.
+func (c *Chat) RemoveMessage(messageID string) bool {
+	if _, ok := c.Messages[messageID]; ok {
+		c.keepScrollPosition(func() {
+			delete(c.Messages, messageID)
+		})
+		if c.Selected == messageID {
+			c.Selected = ""
+		}
+		return true
+	}
+
+	for id, parent := range c.Messages {
+		if _, ok := parent.Messages[messageID]; !ok {
+			continue
+		}
+
+		c.keepScrollPosition(func() {
+			delete(parent.Messages, messageID)
+			if parent.ReplyCount > 0 {
+				parent.ReplyCount--
+			}
+			c.Messages[id] = parent
+		})
+		return true
+	}
+
+	return false
+}
+
+// AddReaction will add a reaction to a message or a reply, or remove it
+// when count is negative. It returns the id of the message, or of the
+// parent of the reply, and false when the message isn't present.
+func (c *Chat) AddReaction(messageID string, name string, count int, own bool) (string, bool) {
+	if msg, ok := c.Messages[messageID]; ok {
+		c.keepScrollPosition(func() {
+			msg.addReaction(name, count, own)
+			c.Messages[messageID] = msg
+		})
+		return messageID, true
+	}
+
+	for id, parent := range c.Messages {
+		reply, ok := parent.Messages[messageID]
+		if !ok {
+			continue
+		}
+
+		c.keepScrollPosition(func() {
+			reply.addReaction(name, count, own)
+			parent.Messages[messageID] = reply
+		})
+		return id, true
+	}
+
+	return "", false
+}
+
+// SelectMessage will select a message, and scroll to it when it isn't
+// shown. When messageID is empty the message at the bottom of the pane is
+// selected. It returns false when there is no such message.
+func (c *Chat) SelectMessage(messageID string) bool {
+	if messageID == "" {
+		messageID = c.GetBottomMessage()
+	}
+	if messageID == "" {
+		messages := SortMessages(c.Messages)
+		if len(messages) == 0 {
+			return false
+		}
+		messageID = messages[len(messages)-1].ID
+	}
+
+	if _, ok := c.Messages[messageID]; !ok {
+		return false
+	}
+
+	c.Selected = messageID
+	if !c.isShown(messageID) {
+		c.ScrollToMessage(messageID)
+	}
+
+	return true
+}
+
+// MoveSelection will select the message that is count messages below the
+// selected one, or above it when count is negative. It stops at the first
+// and the last message.
+func (c *Chat) MoveSelection(count int) {
+	messages := SortMessages(c.Messages)
+	for i, msg := range messages {
+		if msg.ID != c.Selected {
+			continue
+		}
+
+		i += count
+		if i < 0 {
+			i = 0
+		} else if i > len(messages)-1 {
+			i = len(messages) - 1
+		}
+
+		c.SelectMessage(messages[i].ID)
+		return
+	}
+}
+
+// GetSelectedMessage returns the message that is selected
+func (c *Chat) GetSelectedMessage() (Message, bool) {
+	msg, ok := c.Messages[c.Selected]
+	return msg, ok && c.Selected != ""
+}
+
+// isShown returns whether the first line of a message is shown in the pane
+func (c *Chat) isShown(messageID string) bool {
+	target := c.Messages[messageID]
+
+	before := make(map[string]Message)
+	for id, msg := range c.Messages {
+		if msg.Time.Before(target.Time) {
+			before[id] = msg
+		}
+	}
+
+	head := &Chat{
+		List:              c.List,
+		Messages:          before,
+		DateFormat:        c.DateFormat,
+		ExpandAttachments: c.ExpandAttachments,
+		expanded:          c.expanded,
+	}
+
+	// The first line of the message follows the lines of the messages
+	// before it
+	line := len(head.Lines())
+	if len(before) == 0 {
+		line = 0
+	}
+
+	bottom := len(c.Lines()) - c.Offset
+	return line < bottom && line >= bottom-c.GetMaxItems()
 }
 
 // ScrollUp will render the chat messages based on the Offset of the Chat
@@ -238,9 +593,211 @@ func (c *Chat) ScrollDown() {
 	c.Offset = c.Offset - 10
 
 	// Protect overscrolling
-	if c.Offset < 0 {
-		c.Offset = 0
+	if c.Offset <= 0 {
+		c.ScrollToBottom()
+	}
+}
+
+// ScrollToBottom will scroll down to the latest message, and clear the
+// number of new messages
+func (c *Chat) ScrollToBottom() {
+	c.Offset = 0
+	c.NewMessages = 0
+}
+
+// ScrollToMessage will scroll the Chat pane so that the message with
+// messageID is at the bottom of the pane. It returns false when the message
+// isn't present.
+func (c *Chat) ScrollToMessage(messageID string) bool {
+	target, ok := c.Messages[messageID]
+	if !ok {
+		return false
+	}
+
+	// The lines of the messages up until the target are counted, the
+	// remaining lines are below the target
+	before := make(map[string]Message)
+	for id, msg := range c.Messages {
+		if !msg.Time.After(target.Time) {
+			before[id] = msg
+		}
+	}
+
+	head := &Chat{List: c.List, Messages: before, DateFormat: c.DateFormat}
+	c.Offset = len(c.Lines()) - len(head.Lines())
+
+	return true
+}
+
+// SetFind will search for term in the messages that have been loaded, and
+// scroll to the newest message that matches it. It returns false when none
+// of the messages match.
+func (c *Chat) SetFind(term string) bool {
+	c.Find = term
+	c.found = ""
+
+	matches := c.findMatches()
+	if len(matches) == 0 {
+		return false
+	}
+
+	c.found = matches[len(matches)-1]
+	c.ScrollToMessage(c.found)
+
+	return true
+}
+
+// ClearFind will remove the term that is searched for, and its highlights
+func (c *Chat) ClearFind() {
+	c.Find = ""
+	c.found = ""
+}
+
+// HasFind returns whether a term is searched for in the messages
+func (c *Chat) HasFind() bool {
+	return c.Find != ""
+}
+
+// FindNext will scroll to the message with the match that is count matches
+// older than the one that was jumped to last, or newer when count is
+// negative. It wraps around at the oldest and the newest match, and
+// returns false when none of the messages match.
+func (c *Chat) FindNext(count int) bool {
+	matches := c.findMatches()
+	if len(matches) == 0 {
+		return false
+	}
+
+	// When the match that was jumped to last is gone, e.g. because the
+	// history has been reloaded, we start from the newest match
+	current := len(matches)
+	for i, id := range matches {
+		if id == c.found {
+			current = i
+			break
+		}
+	}
+
+	i := ((current-count)%len(matches) + len(matches)) % len(matches)
+	c.found = matches[i]
+	c.ScrollToMessage(c.found)
+
+	return true
+}
+
+// FindPosition returns the position of the match that was jumped to last,
+// counted from the newest match, and the number of matches
+func (c *Chat) FindPosition() (int, int) {
+	matches := c.findMatches()
+	for i, id := range matches {
+		if id == c.found {
+			return len(matches) - i, len(matches)
+		}
+	}
+	return 0, len(matches)
+}
+
+// findMatches returns the ids of the messages that match Find, the oldest
+// first. A message matches when its replies or attachments do.
+func (c *Chat) findMatches() []string {
+	if c.Find == "" {
+		return nil
+	}
+
+	var matches []string
+	for _, msg := range SortMessages(c.Messages) {
+		if c.isFound(msg) {
+			matches = append(matches, msg.ID)
+		}
+	}
+	return matches
+}
+
+// isFound returns whether the content of msg, or of one of the messages
+// that belong to it, matches Find
+func (c *Chat) isFound(msg Message) bool {
+	for _, found := range c.findRunes([]rune(msg.Content)) {
+		if found {
+			return true
+		}
+	}
+
+	for _, sub := range msg.Messages {
+		if c.isFound(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// findRunes returns for every rune of content whether it's part of a match
+// of Find, which is matched regardless of case
+func (c *Chat) findRunes(content []rune) []bool {
+	found := make([]bool, len(content))
+
+	term := []rune(strings.ToLower(c.Find))
+	if len(term) == 0 {
+		return found
+	}
+
+	for i := 0; i+len(term) <= len(content); i++ {
+		match := true
+		for j, r := range term {
+			if unicode.ToLower(content[i+j]) != r {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			for j := range term {
+				found[i+j] = true
+			}
+		}
+	}
+
+	return found
+}
+
+// SetHeader will set the Header to the icon, name and topic of the channel
+func (c *Chat) SetHeader(channel ChannelItem) {
+	c.Header = fmt.Sprintf("%s %s", channel.GetIcon(), channel.GetName())
+	c.styleHeader = channel.StyleHeader
+
+	if channel.Topic != "" {
+		topic := strings.Join(strings.Fields(html.UnescapeString(channel.Topic)), " ")
+		c.Header = fmt.Sprintf("%s — %s", c.Header, topic)
+	}
+}
+
+// GetBottomMessage returns the id of the message that is shown at the bottom
+// of the Chat pane, it's empty when the pane is scrolled to the bottom
+func (c *Chat) GetBottomMessage() string {
+	if c.Offset == 0 {
+		return ""
+	}
+
+	// The message is the last one of which the lines fit above the
+	// Offset
+	shown := len(c.Lines()) - c.Offset
+	head := &Chat{
+		List:              c.List,
+		Messages:          make(map[string]Message),
+		DateFormat:        c.DateFormat,
+		ExpandAttachments: c.ExpandAttachments,
+		expanded:          c.expanded,
+	}
+
+	var messageID string
+	for _, msg := range SortMessages(c.Messages) {
+		head.Messages[msg.ID] = msg
+		if len(head.Lines()) > shown {
+			break
+		}
+		messageID = msg.ID
 	}
+
+	return messageID
 }
 
 // SetBorderLabel will set Label of the Chat pane to the specified string
@@ -251,15 +808,65 @@ func (c *Chat) SetBorderLabel(channelName string) {
 // MessagesToCells is a wrapper around MessageToCells to use for a slice of
 // of type Message
 func (c *Chat) MessagesToCells(msgs map[string]Message) []termui.Cell {
+	return c.messagesToCells(msgs, c.DateFormat != "")
+}
+
+// messagesToCells will convert the messages to termui.Cell, the messages of
+// different days are separated when dates is set
+func (c *Chat) messagesToCells(msgs map[string]Message, dates bool) []termui.Cell {
 	cells := make([]termui.Cell, 0)
 	sortedMessages := SortMessages(msgs)
 
+	var day time.Time
+	var prev Message
 	for i, msg := range sortedMessages {
-		cells = append(cells, c.MessageToCells(msg)...)
+		// Separate the messages of different days, attachments don't
+		// have a time
+		if dates && (msg.Time != time.Time{}) {
+			y, m, d := msg.Time.Date()
+			if msgDay := time.Date(y, m, d, 0, 0, 0, 0, msg.Time.Location()); !msgDay.Equal(day) {
+				day = msgDay
+				cells = append(cells, c.DateToCells(day)...)
+				cells = append(cells, termui.Cell{Ch: '\n'})
+			}
+		}
 
-		if len(msg.Messages) > 0 {
+		msgCells := c.messageToCells(msg, c.isGrouped(prev, msg))
+		if c.Selected != "" && msg.ID == c.Selected {
+			for i := range msgCells {
+				msgCells[i].Bg |= termui.AttrReverse
+			}
+		}
+		cells = append(cells, msgCells...)
+		prev = msg
+
+		if len(msg.Reactions) > 0 {
 			cells = append(cells, termui.Cell{Ch: '\n'})
-			cells = append(cells, c.MessagesToCells(msg.Messages)...)
+			cells = append(cells, c.ReactionsToCells(msg)...)
+		}
+
+		// Attachments are shown as a summary when they're collapsed,
+		// followed by the replies
+		subMessages := msg.Messages
+		if msg.Attachments > 0 && !c.isExpanded(msg) {
+			cells = append(cells, termui.Cell{Ch: '\n'})
+			cells = append(cells, c.AttachmentsToCells(msg)...)
+			subMessages = withoutAttachments(msg.Messages)
+		}
+
+		if len(subMessages) > 0 {
+			cells = append(cells, termui.Cell{Ch: '\n'})
+			cells = append(cells, c.messagesToCells(subMessages, false)...)
+		}
+
+		if msg.ReplyCount > 0 && !msg.RepliesLoaded {
+			cells = append(cells, termui.Cell{Ch: '\n'})
+			cells = append(cells, c.RepliesToCells(msg)...)
+		}
+
+		if msg.ID == c.Gap && i < len(sortedMessages)-1 {
+			cells = append(cells, termui.Cell{Ch: '\n'})
+			cells = append(cells, c.GapToCells()...)
 		}
 
 		// Add a newline after every message
@@ -276,8 +883,32 @@ func (c *Chat) MessagesToCells(msgs map[string]Message) []termui.Cell {
 // We're building parts of the message individually, or else DefaultTxBuilder
 // will interpret potential markdown usage in a message as well.
 func (c *Chat) MessageToCells(msg Message) []termui.Cell {
+	return c.messageToCells(msg, false)
+}
+
+// isGrouped returns whether msg is grouped with the message before it
+func (c *Chat) isGrouped(prev Message, msg Message) bool {
+	if c.GroupMinutes == 0 || prev.Name == "" || prev.Name != msg.Name {
+		return false
+	}
+
+	// Attachments don't have a time, and are never grouped
+	if (prev.Time == time.Time{} || msg.Time == time.Time{}) {
+		return false
+	}
+
+	return msg.Time.Sub(prev.Time) <= time.Duration(c.GroupMinutes)*time.Minute
+}
+
+// messageToCells will convert the message to termui.Cell, the name of the
+// user is left blank when the message is grouped with the one before it
+func (c *Chat) messageToCells(msg Message, grouped bool) []termui.Cell {
 	cells := make([]termui.Cell, 0)
 
+	if c.ShowSeconds {
+		msg.FormatTime = withSeconds(msg.FormatTime)
+	}
+
 	// When msg.Time and msg.Name are empty (in the case of attachments)
 	// don't add the time and name parts.
 	if (msg.Time != time.Time{} && msg.Name != "") {
@@ -293,11 +924,18 @@ func (c *Chat) MessageToCells(msg Message) []termui.Cell {
 			termui.ColorDefault, termui.ColorDefault)...,
 		)
 
-		// Name
-		cells = append(cells, termui.DefaultTxBuilder.Build(
+		// Name, grouped messages keep the space it takes so their
+		// content is aligned
+		nameCells := termui.DefaultTxBuilder.Build(
 			msg.GetName(),
-			termui.ColorDefault, termui.ColorDefault)...,
+			termui.ColorDefault, termui.ColorDefault,
 		)
+		if grouped {
+			for i := range nameCells {
+				nameCells[i] = termui.Cell{Ch: ' '}
+			}
+		}
+		cells = append(cells, nameCells...)
 	}
 
 	// Hack, in order to get the correct fg and bg attributes. This is
@@ -307,21 +945,201 @@ func (c *Chat) MessageToCells(msg Message) []termui.Cell {
 		termui.ColorDefault, termui.ColorDefault,
 	)
 
-	// Text
-	for _, r := range msg.Content {
+	mentionCells := txCells
+	if msg.StyleMention != "" {
+		mentionCells = termui.DefaultTxBuilder.Build(
+			fmt.Sprintf("[.](%s)", msg.StyleMention),
+			termui.ColorDefault, termui.ColorDefault,
+		)
+	}
+
+	// Text, where mentions start with an @ at the start of a word, and
+	// the matches of Find are highlighted
+	content := []rune(msg.Content)
+	found := c.findRunes(content)
+
+	var mention bool
+	var prev rune = ' '
+	for i, r := range content {
+		if r == '@' && unicode.IsSpace(prev) {
+			mention = true
+		} else if mention && !isMentionRune(r) {
+			mention = false
+		}
+		prev = r
+
+		style := txCells[0]
+		if mention {
+			style = mentionCells[0]
+		}
+		if found[i] {
+			style = termui.Cell{Fg: termui.ColorBlack, Bg: termui.ColorYellow}
+		}
+
 		cells = append(
 			cells,
 			termui.Cell{
 				Ch: r,
-				Fg: txCells[0].Fg,
-				Bg: txCells[0].Bg,
+				Fg: style.Fg,
+				Bg: style.Bg,
 			},
 		)
 	}
 
+	if msg.Edited {
+		for _, r := range " (edited)" {
+			cells = append(cells, termui.Cell{Ch: r, Fg: txCells[0].Fg, Bg: txCells[0].Bg})
+		}
+	}
+
+	return cells
+}
+
+// isMentionRune returns whether r can be part of the name of a user or the
+// handle of a user group
+func isMentionRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_.", r)
+}
+
+// DateToCells will convert the separator of the messages of a day to
+// termui.Cell
+func (c *Chat) DateToCells(day time.Time) []termui.Cell {
+	separator := fmt.Sprintf("─── %s ───", config.FormatDate(day, c.DateFormat))
+
+	cells := make([]termui.Cell, 0)
+	for _, r := range separator {
+		cells = append(cells, termui.Cell{
+			Ch: r,
+			Fg: termui.ColorDefault,
+			Bg: termui.ColorDefault,
+		})
+	}
+	return cells
+}
+
+// GapToCells will convert the marker of missed messages to termui.Cell
+func (c *Chat) GapToCells() []termui.Cell {
+	return termui.DefaultTxBuilder.Build(
+		"[— missed messages, press f to fetch —](fg-bold)",
+		termui.ColorDefault, termui.ColorDefault,
+	)
+}
+
+// AttachmentsToCells will convert the summary of the attachments of a
+// message to termui.Cell, it's shown when they're collapsed
+func (c *Chat) AttachmentsToCells(msg Message) []termui.Cell {
+	return termui.DefaultTxBuilder.Build(
+		fmt.Sprintf("    [▸ %s](%s)", msg.GetAttachmentsSummary(), msg.StyleThread),
+		termui.ColorDefault, termui.ColorDefault,
+	)
+}
+
+// isExpanded returns whether the attachments of the message are shown
+func (c *Chat) isExpanded(msg Message) bool {
+	return c.ExpandAttachments != c.expanded[msg.ID]
+}
+
+// ToggleAttachments will expand or collapse the attachments of the message
+// that is shown at the bottom of the Chat pane, or the first message above
+// it that has attachments. It returns false when there is no such message.
+func (c *Chat) ToggleAttachments() bool {
+	bottom := c.GetBottomMessage()
+
+	messages := SortMessages(c.Messages)
+	for i := len(messages) - 1; i >= 0; i-- {
+		msg := messages[i]
+		if bottom != "" && msg.ID > bottom {
+			continue
+		}
+		if msg.Attachments == 0 {
+			continue
+		}
+
+		c.expanded[msg.ID] = !c.expanded[msg.ID]
+		return true
+	}
+
+	return false
+}
+
+// GetLastMessage returns the most recent message of a user, replies and
+// attachments aren't part of the messages
+func (c *Chat) GetLastMessage(userID string) (Message, bool) {
+	messages := SortMessages(c.Messages)
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].UserID == userID {
+			return messages[i], true
+		}
+	}
+	return Message{}, false
+}
+
+// withoutAttachments returns the messages that aren't attachments, which
+// are the ones without a time
+func withoutAttachments(msgs map[string]Message) map[string]Message {
+	replies := make(map[string]Message)
+	for id, msg := range msgs {
+		if (msg.Time != time.Time{}) {
+			replies[id] = msg
+		}
+	}
+	return replies
+}
+
+// RepliesToCells will convert the summary of the replies of a thread parent
+// to termui.Cell, it's shown when the replies haven't been loaded
+func (c *Chat) RepliesToCells(msg Message) []termui.Cell {
+	return termui.DefaultTxBuilder.Build(
+		fmt.Sprintf("    [↳ %s](%s)", msg.GetRepliesSummary(), msg.StyleThread),
+		termui.ColorDefault, termui.ColorDefault,
+	)
+}
+
+// PreviewToCells will convert the preview of the message that is being
+// composed to termui.Cell, below a separator
+func (c *Chat) PreviewToCells(msg Message) []termui.Cell {
+	cells := make([]termui.Cell, 0)
+	for _, r := range "─── preview ───" {
+		cells = append(cells, termui.Cell{
+			Ch: r,
+			Fg: termui.ColorDefault,
+			Bg: termui.ColorDefault,
+		})
+	}
+	cells = append(cells, termui.Cell{Ch: '\n'})
+
+	return append(cells, c.MessageToCells(msg)...)
+}
+
+// ReactionsToCells will convert the reactions of a Message to termui.Cell,
+// they're indented to set them apart from the message
+func (c *Chat) ReactionsToCells(msg Message) []termui.Cell {
+	cells := make([]termui.Cell, 0)
+	for _, r := range "    " + msg.GetReactions() {
+		cells = append(cells, termui.Cell{
+			Ch: r,
+			Fg: termui.ColorDefault,
+			Bg: termui.ColorDefault,
+		})
+	}
 	return cells
 }
 
+// SetText will replace the messages in the chat pane by lines of text, e.g.
+// a summary. They're shown until a channel is loaded again.
+func (c *Chat) SetText(lines []string) {
+	c.ClearMessages()
+	c.Offset = 0
+
+	for i, line := range lines {
+		msg := Message{
+			ID:      fmt.Sprintf("%06d", i),
+			Content: line,
+		}
+		c.Messages[msg.ID] = msg
+	}
+}
+
 // Help shows the usage and key bindings in the chat pane
 func (c *Chat) Help(usage string, cfg *config.Config) {
 	msgUsage := Message{
@@ -362,3 +1180,12 @@ func (c *Chat) Help(usage string, cfg *config.Config) {
 		c.Messages[msgNewline.ID] = msgNewline
 	}
 }
+
+// withSeconds adds the seconds to a time layout that only has the minutes,
+// e.g. "15:04" becomes "15:04:05"
+func withSeconds(layout string) string {
+	if strings.Contains(layout, "05") {
+		return layout
+	}
+	return strings.Replace(layout, "04", "04:05", 1)
+}
@@ -0,0 +1,97 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/erroneousboat/termui"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// CompletionItem is a candidate of a completion, Text replaces the word
+// before the cursor, e.g. "@name" or ":smile:". Description is shown next
+// to it, e.g. the real name of a user or the emoji of a shortcode.
+type CompletionItem struct {
+	Text        string
+	Description string
+}
+
+// ToString will set the label of the candidate, how it will be displayed
+// in the popup
+func (c CompletionItem) ToString() string {
+	if c.Description == "" {
+		return c.Text
+	}
+	return fmt.Sprintf("%s  %s", c.Text, c.Description)
+}
+
+// Completion shows the candidates of completing the word before the cursor
+// of the Input, in a popup above it. The selected candidate is highlighted.
+type Completion struct {
+	List       *termui.List
+	Candidates []CompletionItem
+	Selected   int
+}
+
+// CreateCompletionComponent is the constructor for the Completion component
+func CreateCompletionComponent() *Completion {
+	return &Completion{
+		List: termui.NewList(),
+	}
+}
+
+// Buffer implements interface termui.Bufferer
+func (c *Completion) Buffer() termui.Buffer {
+	buf := c.List.Buffer()
+
+	var items []string
+	for _, candidate := range c.Candidates {
+		items = append(items, candidate.ToString())
+	}
+
+	bufferLines(c.List, buf, items, c.Selected)
+
+	return buf
+}
+
+// Show will show the candidates in a popup above input, at the position of
+// its cursor. The first candidate is selected.
+func (c *Completion) Show(input *Input, candidates []CompletionItem) {
+	c.Candidates = candidates
+	c.Selected = 0
+
+	var width int
+	for _, candidate := range candidates {
+		if w := runewidth.StringWidth(candidate.ToString()); w > width {
+			width = w
+		}
+	}
+
+	c.List.Width = width + 2
+	c.List.Height = len(candidates) + 2
+
+	// Keep the popup within the bounds of the Input
+	c.List.X = input.Par.InnerX() + input.CursorPositionScreen
+	if max := input.Par.X + input.Par.Width - c.List.Width; c.List.X > max {
+		c.List.X = max
+	}
+	if c.List.X < input.Par.X {
+		c.List.X = input.Par.X
+	}
+	c.List.Y = input.Par.Y - c.List.Height
+}
+
+// Hide will hide the popup
+func (c *Completion) Hide() {
+	c.Candidates = nil
+	c.Selected = 0
+}
+
+// IsShown returns whether the popup is shown
+func (c *Completion) IsShown() bool {
+	return len(c.Candidates) > 0
+}
+
+// GetSelectedCandidate returns the CompletionItem that is selected
+func (c *Completion) GetSelectedCandidate() CompletionItem {
+	return c.Candidates[c.Selected]
+}
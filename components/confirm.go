@@ -0,0 +1,65 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/erroneousboat/termui"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// confirmChoices are the keys that answer the Confirm popup
+const confirmChoices = "[y](fg-green,fg-bold)es  [n](fg-red,fg-bold)o"
+
+// Confirm is a popup that asks to confirm an action that can't be undone,
+// e.g. deleting a message. It's answered with y or n, see
+// context.AppContext.PendingAction.
+type Confirm struct {
+	Par      *termui.Par
+	Question string
+}
+
+// CreateConfirmComponent is the constructor for the Confirm component
+func CreateConfirmComponent() *Confirm {
+	confirm := &Confirm{
+		Par: termui.NewPar(""),
+	}
+
+	confirm.Par.BorderLabel = "Confirm"
+	confirm.Par.BorderFg = termui.ColorYellow
+	confirm.Par.Height = 5
+
+	return confirm
+}
+
+// Buffer implements interface termui.Bufferer
+func (c *Confirm) Buffer() termui.Buffer {
+	c.Par.Text = fmt.Sprintf("[%s](fg-bold)\n\n%s", c.Question, confirmChoices)
+	return c.Par.Buffer()
+}
+
+// Show will show the question in the popup, centered on the pane at x, y
+// with the width and height
+func (c *Confirm) Show(question string, x, y, width, height int) {
+	c.Question = question
+
+	c.Par.Width = runewidth.StringWidth(question) + 4
+	if c.Par.Width < 20 {
+		c.Par.Width = 20
+	}
+	if c.Par.Width > width-4 {
+		c.Par.Width = width - 4
+	}
+
+	c.Par.X = x + (width-c.Par.Width)/2
+	c.Par.Y = y + (height-c.Par.Height)/3
+}
+
+// Hide will hide the popup
+func (c *Confirm) Hide() {
+	c.Question = ""
+}
+
+// IsShown returns whether the popup is shown
+func (c *Confirm) IsShown() bool {
+	return c.Question != ""
+}
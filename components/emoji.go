@@ -0,0 +1,201 @@
+package components
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/erroneousboat/termui"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+
+	"github.com/erroneousboat/slack-term/config"
+)
+
+// EmojiItem is an emoji that can be picked as a reaction, Reacted is set
+// when the user has already reacted with it
+type EmojiItem struct {
+	Name    string
+	Emoji   string
+	Reacted bool
+}
+
+// ToString will set the label of the emoji, how it will be displayed in
+// the list of emoji
+func (e EmojiItem) ToString() string {
+	check := " "
+	if e.Reacted {
+		check = "✓"
+	}
+	return fmt.Sprintf("%s %s  :%s:", check, e.Emoji, e.Name)
+}
+
+// EmojiPicker lists the emoji that can be added as a reaction to a
+// message, filtered by a fuzzy search term. It replaces the Chat component
+// when it's opened.
+type EmojiPicker struct {
+	EmojiItems    []EmojiItem // the emoji that match the term
+	Loaded        []EmojiItem // all the emoji
+	Term          string      // the term the emoji are filtered by
+	List          *termui.List
+	SelectedEmoji int // index of which emoji is selected from the List
+	Offset        int // from what offset are emoji rendered
+}
+
+// CreateEmojiPickerComponent is the constructor for the EmojiPicker
+// component
+func CreateEmojiPickerComponent(inputHeight int) *EmojiPicker {
+	picker := &EmojiPicker{
+		List: termui.NewList(),
+	}
+
+	picker.List.BorderLabel = "Reactions"
+	picker.List.Height = termui.TermHeight() - inputHeight
+
+	return picker
+}
+
+// Buffer implements interface termui.Bufferer
+func (e *EmojiPicker) Buffer() termui.Buffer {
+	buf := e.List.Buffer()
+
+	var items []string
+	for _, emoji := range e.EmojiItems[e.Offset:] {
+		items = append(items, emoji.ToString())
+	}
+
+	bufferLines(e.List, buf, items, e.SelectedEmoji-e.Offset)
+
+	return buf
+}
+
+// GetHeight implements interface termui.GridBufferer
+func (e *EmojiPicker) GetHeight() int {
+	return e.List.Block.GetHeight()
+}
+
+// SetWidth implements interface termui.GridBufferer
+func (e *EmojiPicker) SetWidth(w int) {
+	e.List.SetWidth(w)
+}
+
+// SetX implements interface termui.GridBufferer
+func (e *EmojiPicker) SetX(x int) {
+	e.List.SetX(x)
+}
+
+// SetY implements interface termui.GridBufferer
+func (e *EmojiPicker) SetY(y int) {
+	e.List.SetY(y)
+}
+
+// SetMessage will list the emoji for the reactions on msg, the reactions
+// that are already on the message are listed first, followed by the
+// frequent emoji in the order they're given
+func (e *EmojiPicker) SetMessage(msg Message, frequent []string) {
+	present := make(map[string]Reaction)
+	for _, r := range msg.Reactions {
+		present[r.Name] = r
+	}
+
+	rank := make(map[string]int)
+	for i, name := range frequent {
+		rank[name] = i + 1
+	}
+
+	var first, often, rest []EmojiItem
+	for name, emoji := range config.EmojiCodemap {
+		item := EmojiItem{Name: strings.Trim(name, ":"), Emoji: emoji}
+
+		// The names of reactions are the emoji, or the placeholders
+		// when emoji are disabled
+		r, ok := present[emoji]
+		if !ok {
+			r, ok = present[name]
+		}
+
+		if ok {
+			item.Reacted = r.Reacted
+			first = append(first, item)
+		} else if rank[item.Name] > 0 {
+			often = append(often, item)
+		} else {
+			rest = append(rest, item)
+		}
+	}
+
+	sortEmojiItems(first)
+	sortEmojiItems(rest)
+	sort.Slice(often, func(i, j int) bool {
+		return rank[often[i].Name] < rank[often[j].Name]
+	})
+
+	e.Loaded = append(append(first, often...), rest...)
+	e.Filter("")
+}
+
+func sortEmojiItems(items []EmojiItem) {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+}
+
+// Filter will only show the emoji of which the name matches the term, the
+// closest matches first. An empty term will show all the emoji.
+func (e *EmojiPicker) Filter(term string) {
+	e.Term = term
+
+	if term == "" {
+		e.EmojiItems = e.Loaded
+	} else {
+		names := make([]string, len(e.Loaded))
+		for i, item := range e.Loaded {
+			names[i] = item.Name
+		}
+
+		ranks := fuzzy.RankFindFold(term, names)
+		sort.Stable(ranks)
+
+		e.EmojiItems = make([]EmojiItem, 0, len(ranks))
+		for _, rank := range ranks {
+			e.EmojiItems = append(e.EmojiItems, e.Loaded[rank.OriginalIndex])
+		}
+	}
+
+	e.MoveCursorTop()
+}
+
+// HasEmoji returns whether any emoji match the term
+func (e *EmojiPicker) HasEmoji() bool {
+	return len(e.EmojiItems) > 0
+}
+
+// GetSelectedEmoji returns the EmojiItem that is currently selected
+func (e *EmojiPicker) GetSelectedEmoji() EmojiItem {
+	return e.EmojiItems[e.SelectedEmoji]
+}
+
+// MoveCursorUp will decrease the SelectedEmoji by 1
+func (e *EmojiPicker) MoveCursorUp() {
+	if e.SelectedEmoji > 0 {
+		e.SelectedEmoji--
+		if e.SelectedEmoji < e.Offset {
+			e.Offset = e.SelectedEmoji
+		}
+	}
+}
+
+// MoveCursorDown will increase the SelectedEmoji by 1
+func (e *EmojiPicker) MoveCursorDown() {
+	if e.SelectedEmoji < len(e.EmojiItems)-1 {
+		e.SelectedEmoji++
+		if e.SelectedEmoji > e.Offset+e.List.InnerHeight()-1 {
+			e.Offset = e.SelectedEmoji - e.List.InnerHeight() + 1
+		}
+	}
+}
+
+// MoveCursorTop will move the cursor to the top of the emoji
+func (e *EmojiPicker) MoveCursorTop() {
+	e.SelectedEmoji = 0
+	e.Offset = 0
+}
@@ -0,0 +1,198 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erroneousboat/termui"
+)
+
+const (
+	IconAudio = "♪"
+	IconVideo = "▶"
+)
+
+// FileItem is a file that has been shared in a channel
+type FileItem struct {
+	ID        string
+	Name      string
+	Title     string
+	User      string
+	Filetype  string
+	Mimetype  string
+	Size      int
+	Width     int           // width of images in pixels
+	Height    int           // height of images in pixels
+	Duration  time.Duration // duration of audio and video files
+	Created   time.Time
+	URL       string // url to download the file
+	Permalink string
+	Preview   string // the first lines of text files
+}
+
+// ToString will set the label of the file, how it will be displayed in
+// the list of files
+func (f FileItem) ToString() string {
+	name := f.Name
+	if icon := MediaIcon(f.Mimetype); icon != "" {
+		name = fmt.Sprintf("%s %s", icon, name)
+		if f.Duration > 0 {
+			name = fmt.Sprintf("%s  %s", name, FormatDuration(f.Duration))
+		}
+	}
+
+	return fmt.Sprintf(
+		"%s  %-10s  %8s  %s",
+		f.Created.Format("2006-01-02 15:04"), f.User, FormatSize(f.Size), name,
+	)
+}
+
+// MediaIcon returns the icon of audio and video files by their mimetype, it
+// returns an empty string for other files
+func MediaIcon(mimetype string) string {
+	switch {
+	case strings.HasPrefix(mimetype, "audio/"):
+		return IconAudio
+	case strings.HasPrefix(mimetype, "video/"):
+		return IconVideo
+	}
+	return ""
+}
+
+// PreviewLines returns the details of the file, followed by the preview
+// of its content when it's available
+func (f FileItem) PreviewLines() []string {
+	lines := []string{
+		fmt.Sprintf("Name:    %s", f.Name),
+		fmt.Sprintf("Title:   %s", f.Title),
+		fmt.Sprintf("Type:    %s", f.Filetype),
+		fmt.Sprintf("Size:    %s", FormatSize(f.Size)),
+	}
+
+	if f.Width > 0 && f.Height > 0 {
+		lines = append(lines, fmt.Sprintf("Image:   %d×%d", f.Width, f.Height))
+	}
+
+	if f.Duration > 0 {
+		lines = append(lines, fmt.Sprintf("Length:  %s", FormatDuration(f.Duration)))
+	}
+
+	lines = append(lines,
+		fmt.Sprintf("Shared:  %s by %s", f.Created.Format("2006-01-02 15:04"), f.User),
+		fmt.Sprintf("Link:    %s", f.Permalink),
+	)
+
+	if f.Preview != "" {
+		lines = append(lines, "")
+		lines = append(lines, strings.Split(f.Preview, "\n")...)
+	}
+
+	return lines
+}
+
+// FormatSize returns the size in bytes as a human readable string
+func FormatSize(size int) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+
+	div, exp := unit, 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGT"[exp])
+}
+
+// FormatDuration returns the duration of audio and video files as minutes
+// and seconds, e.g. "3:07", or with hours when it's longer, e.g. "1:02:03"
+func FormatDuration(d time.Duration) string {
+	seconds := int(d.Round(time.Second) / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// Files lists the files that have been shared in the selected channel, it
+// replaces the Chat component when browsing files. The selected file can
+// be previewed, which shows its details instead of the list.
+type Files struct {
+	SelectableList
+	FileItems []FileItem
+
+	// Preview holds the lines that are shown instead of the list, when a
+	// file is being previewed
+	Preview []string
+}
+
+// CreateFilesComponent is the constructor for the Files component
+func CreateFilesComponent(inputHeight int) *Files {
+	files := &Files{}
+	files.SelectableList = newSelectableList(
+		"Files",
+		func() int { return len(files.FileItems) },
+		func(i int) string { return files.FileItems[i].ToString() },
+	)
+
+	files.List.Height = termui.TermHeight() - inputHeight
+
+	return files
+}
+
+// Buffer implements interface termui.Bufferer, the preview is shown
+// instead of the files when a file is being previewed
+func (f *Files) Buffer() termui.Buffer {
+	if f.Preview == nil {
+		return f.SelectableList.Buffer()
+	}
+
+	buf := f.List.Buffer()
+	bufferLines(f.List, buf, f.Preview, -1)
+
+	return buf
+}
+
+// SetFiles will replace the files, and select the first one
+func (f *Files) SetFiles(files []FileItem) {
+	f.FileItems = files
+	f.Preview = nil
+	f.MoveCursorTop()
+}
+
+// RemoveFile will remove the file with fileID
+func (f *Files) RemoveFile(fileID string) {
+	for i, file := range f.FileItems {
+		if file.ID == fileID {
+			f.FileItems = append(f.FileItems[:i], f.FileItems[i+1:]...)
+			break
+		}
+	}
+
+	if f.Selected > len(f.FileItems)-1 && f.Selected > 0 {
+		f.MoveCursorBottom()
+	}
+}
+
+// HasFiles returns whether there are any files
+func (f *Files) HasFiles() bool {
+	return len(f.FileItems) > 0
+}
+
+// GetSelectedFile returns the FileItem that is currently selected
+func (f *Files) GetSelectedFile() FileItem {
+	return f.FileItems[f.Selected]
+}
+
+// ShowPreview will show the details of the selected file
+func (f *Files) ShowPreview() {
+	f.Preview = f.GetSelectedFile().PreviewLines()
+}
+
+// ClosePreview will show the list of files again
+func (f *Files) ClosePreview() {
+	f.Preview = nil
+}
@@ -0,0 +1,93 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erroneousboat/termui"
+)
+
+// FollowUpItem is a message that has been flagged for follow-up, Due is the
+// time of the reminder and it's zero without one
+type FollowUpItem struct {
+	ChannelID   string
+	ChannelName string
+	MessageID   string
+	Name        string
+	Content     string
+	Due         time.Time
+	Reminded    bool
+}
+
+// IsDue returns whether the reminder of the follow-up is due at now
+func (f FollowUpItem) IsDue(now time.Time) bool {
+	return !f.Due.IsZero() && !f.Due.After(now)
+}
+
+// ToString will set the label of the follow-up, how it will be displayed
+// in the list of follow-ups
+func (f FollowUpItem) ToString() string {
+	due := "           "
+	if !f.Due.IsZero() {
+		due = f.Due.Format("01-02 15:04")
+	}
+
+	return fmt.Sprintf(
+		"%s  %-15s  <%s> %s",
+		due, f.ChannelName, f.Name,
+		strings.Replace(f.Content, "\n", " ", -1),
+	)
+}
+
+// FollowUps lists the messages that have been flagged for follow-up, the
+// ones that are due first. It replaces the Chat component when it's opened.
+type FollowUps struct {
+	SelectableList
+	FollowUpItems []FollowUpItem
+}
+
+// CreateFollowUpsComponent is the constructor for the FollowUps component
+func CreateFollowUpsComponent(inputHeight int) *FollowUps {
+	followUps := &FollowUps{}
+	followUps.SelectableList = newSelectableList(
+		"Follow-ups",
+		func() int { return len(followUps.FollowUpItems) },
+		func(i int) string { return followUps.FollowUpItems[i].ToString() },
+	)
+
+	followUps.List.Height = termui.TermHeight() - inputHeight
+
+	return followUps
+}
+
+// SetFollowUps will replace the follow-ups, and select the first one
+func (f *FollowUps) SetFollowUps(followUps []FollowUpItem) {
+	f.FollowUpItems = followUps
+	f.MoveCursorTop()
+}
+
+// RemoveFollowUp will remove the follow-up of a message from the list, and
+// keep the selection in range
+func (f *FollowUps) RemoveFollowUp(channelID, messageID string) {
+	for i, followUp := range f.FollowUpItems {
+		if followUp.ChannelID == channelID && followUp.MessageID == messageID {
+			f.FollowUpItems = append(f.FollowUpItems[:i], f.FollowUpItems[i+1:]...)
+			break
+		}
+	}
+
+	if f.Selected > len(f.FollowUpItems)-1 {
+		f.MoveCursorBottom()
+	}
+}
+
+// HasFollowUps returns whether there are any follow-ups
+func (f *FollowUps) HasFollowUps() bool {
+	return len(f.FollowUpItems) > 0
+}
+
+// GetSelectedFollowUp returns the FollowUpItem that is currently selected
+func (f *FollowUps) GetSelectedFollowUp() FollowUpItem {
+	return f.FollowUpItems[f.Selected]
+}
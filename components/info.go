@@ -0,0 +1,202 @@
+package components
+
+import (
+	"fmt"
+	"html"
+	"time"
+
+	"github.com/erroneousboat/termui"
+)
+
+const (
+	// infoWidth and infoHeight are the maximum size of the ChannelInfo
+	// popup
+	infoWidth  = 80
+	infoHeight = 24
+)
+
+// BookmarkItem is a link that is bookmarked in a channel
+type BookmarkItem struct {
+	ID    string
+	Title string
+	Link  string
+	Emoji string
+}
+
+// ToString will set the label of the bookmark, how it will be displayed in
+// the ChannelInfo popup
+func (b BookmarkItem) ToString() string {
+	title := b.Title
+	if title == "" {
+		title = b.Link
+	}
+	if b.Emoji != "" {
+		title = fmt.Sprintf("%s %s", b.Emoji, title)
+	}
+
+	return fmt.Sprintf("%s  %s", title, b.Link)
+}
+
+// ChannelDetails are the details of a channel that aren't part of the
+// ChannelItem
+type ChannelDetails struct {
+	Topic       string
+	Purpose     string
+	Created     time.Time
+	MemberCount int
+	IsShared    bool // shared with other workspaces of the organization
+	IsExtShared bool // shared with external organizations
+}
+
+// ChannelInfo is a popup with the details of a channel and its bookmarks,
+// the selected bookmark and the canvas can be opened. It's shown on top of
+// the Chat component.
+type ChannelInfo struct {
+	List      *termui.List
+	Channel   ChannelItem
+	Details   ChannelDetails
+	Canvas    string // link to the canvas of the channel
+	Bookmarks []BookmarkItem
+	Selected  int // index of which bookmark is selected
+	Offset    int // from what offset are bookmarks rendered
+}
+
+// CreateChannelInfoComponent is the constructor for the ChannelInfo
+// component
+func CreateChannelInfoComponent() *ChannelInfo {
+	info := &ChannelInfo{
+		List: termui.NewList(),
+	}
+
+	info.List.BorderLabel = "Info"
+
+	return info
+}
+
+// Buffer implements interface termui.Bufferer
+func (i *ChannelInfo) Buffer() termui.Buffer {
+	buf := i.List.Buffer()
+
+	lines := i.detailLines()
+	cursor := -1
+	if len(i.Bookmarks) == 0 {
+		lines = append(lines, "  No bookmarks")
+	} else {
+		cursor = len(lines) + i.Selected - i.Offset
+		for _, bookmark := range i.Bookmarks[i.Offset:] {
+			lines = append(lines, "  "+bookmark.ToString())
+		}
+	}
+
+	bufferLines(i.List, buf, lines, cursor)
+
+	return buf
+}
+
+// detailLines returns the details of the channel, that are shown above the
+// bookmarks
+func (i *ChannelInfo) detailLines() []string {
+	created := "unknown"
+	if !i.Details.Created.IsZero() {
+		created = i.Details.Created.Format("2006-01-02")
+	}
+
+	members := "unknown"
+	if i.Details.MemberCount > 0 {
+		members = fmt.Sprintf("%d", i.Details.MemberCount)
+	}
+
+	shared := "no"
+	if i.Details.IsExtShared {
+		shared = fmt.Sprintf("%s with external organizations", IconExtShared)
+	} else if i.Details.IsShared {
+		shared = "with other workspaces"
+	}
+
+	canvas := i.Canvas
+	if canvas == "" {
+		canvas = "none"
+	}
+
+	return []string{
+		fmt.Sprintf("Topic:    %s", html.UnescapeString(i.Details.Topic)),
+		fmt.Sprintf("Purpose:  %s", html.UnescapeString(i.Details.Purpose)),
+		fmt.Sprintf("Created:  %s", created),
+		fmt.Sprintf("Members:  %s", members),
+		fmt.Sprintf("Shared:   %s", shared),
+		fmt.Sprintf("Canvas:   %s", canvas),
+		"",
+		"Bookmarks:",
+	}
+}
+
+// Show will open the popup on top of the pane at x, y with the width and
+// height, with the details of channel. The details that aren't part of
+// the ChannelItem and the bookmarks are set with SetDetails and
+// SetBookmarks, once they're fetched.
+func (i *ChannelInfo) Show(channel ChannelItem, x, y, width, height int) {
+	i.Channel = channel
+	i.List.BorderLabel = channel.GetName()
+
+	i.List.Width = width - 4
+	if i.List.Width > infoWidth {
+		i.List.Width = infoWidth
+	}
+	i.List.Height = height - 4
+	if i.List.Height > infoHeight {
+		i.List.Height = infoHeight
+	}
+
+	i.List.X = x + (width-i.List.Width)/2
+	i.List.Y = y + (height-i.List.Height)/3
+}
+
+// SetDetails will replace the details of the channel
+func (i *ChannelInfo) SetDetails(details ChannelDetails) {
+	i.Details = details
+}
+
+// HasCanvas returns whether the channel has a canvas
+func (i *ChannelInfo) HasCanvas() bool {
+	return i.Canvas != ""
+}
+
+// SetBookmarks will replace the bookmarks, and select the first one
+func (i *ChannelInfo) SetBookmarks(bookmarks []BookmarkItem) {
+	i.Bookmarks = bookmarks
+	i.Selected = 0
+	i.Offset = 0
+}
+
+// HasBookmarks returns whether the channel has any bookmarks
+func (i *ChannelInfo) HasBookmarks() bool {
+	return len(i.Bookmarks) > 0
+}
+
+// GetSelectedBookmark returns the bookmark that is selected
+func (i *ChannelInfo) GetSelectedBookmark() BookmarkItem {
+	return i.Bookmarks[i.Selected]
+}
+
+// MoveCursorUp will decrease Selected by 1
+func (i *ChannelInfo) MoveCursorUp() {
+	if i.Selected > 0 {
+		i.Selected--
+		if i.Selected < i.Offset {
+			i.Offset = i.Selected
+		}
+	}
+}
+
+// MoveCursorDown will increase Selected by 1, the first lines of the List
+// are taken by the details of the channel
+func (i *ChannelInfo) MoveCursorDown() {
+	if i.Selected < len(i.Bookmarks)-1 {
+		i.Selected++
+
+		height := i.List.InnerHeight() - len(i.detailLines())
+		if i.Selected > i.Offset+height-1 {
+			i.Offset = i.Selected - height + 1
+		}
+	}
+}
@@ -1,6 +1,8 @@
 package components
 
 import (
+	"unicode"
+
 	"github.com/erroneousboat/termui"
 	runewidth "github.com/mattn/go-runewidth"
 )
@@ -12,6 +14,10 @@ type Input struct {
 	CursorPositionScreen int
 	CursorPositionText   int
 	Offset               int
+
+	// Segments are shown at the right of the border, e.g. the current
+	// user and a clock. They're hidden when they would overlap the status.
+	Segments string
 }
 
 // CreateInput is the constructor of the Input struct
@@ -46,9 +52,38 @@ func (i *Input) Buffer() termui.Buffer {
 		},
 	)
 
+	i.segmentsToBuffer(buf)
+
 	return buf
 }
 
+// segmentsToBuffer will set the cells of the Segments at the right of the
+// top border
+func (i *Input) segmentsToBuffer(buf termui.Buffer) {
+	if i.Segments == "" {
+		return
+	}
+
+	cells := termui.DefaultTxBuilder.Build(
+		i.Segments, i.Par.BorderLabelFg, i.Par.BorderLabelBg,
+	)
+
+	width := 0
+	for _, cell := range cells {
+		width += cell.Width()
+	}
+
+	x := i.Par.X + i.Par.Width - 2 - width
+	if x <= i.Par.X+2+runewidth.StringWidth(i.Par.BorderLabel) {
+		return
+	}
+
+	for _, cell := range cells {
+		buf.Set(x, i.Par.Y, cell)
+		x += cell.Width()
+	}
+}
+
 // GetHeight implements interface termui.GridBufferer
 func (i *Input) GetHeight() int {
 	return i.Par.Block.GetHeight()
@@ -213,8 +248,40 @@ func (i *Input) GetText() string {
 	return string(i.Text)
 }
 
+// GetWordBeforeCursor returns the word that ends at the CursorPositionText,
+// together with the position in Text at which it starts
+func (i *Input) GetWordBeforeCursor() (string, int) {
+	start := i.CursorPositionText
+	for start > 0 && !unicode.IsSpace(i.Text[start-1]) {
+		start--
+	}
+	return string(i.Text[start:i.CursorPositionText]), start
+}
+
+// ReplaceBeforeCursor will replace the text between start and the
+// CursorPositionText with text
+func (i *Input) ReplaceBeforeCursor(start int, text string) {
+	for i.CursorPositionText > start {
+		i.Backspace()
+	}
+	for _, r := range text {
+		i.Insert(r)
+	}
+}
+
 // GetMaxWidth returns the maximum number of positions
 // the Input component can display
 func (i *Input) GetMaxWidth() int {
 	return i.Par.InnerBounds().Dx() - 1
 }
+
+// SetSegments will show segments at the right of the border of the Input
+// component
+func (i *Input) SetSegments(segments string) {
+	i.Segments = segments
+}
+
+// SetStatus will show status in the border of the Input component
+func (i *Input) SetStatus(status string) {
+	i.Par.BorderLabel = status
+}
@@ -0,0 +1,146 @@
+package components
+
+import (
+	"github.com/erroneousboat/termui"
+)
+
+// bufferLines will set the lines of text in the buffer of the list, the
+// line at index cursor is highlighted, use -1 for no cursor. The lines
+// aren't parsed for the markup of termui.
+func bufferLines(list *termui.List, buf termui.Buffer, lines []string, cursor int) {
+	for i, line := range lines {
+		y := list.InnerBounds().Min.Y + i
+		if y > list.InnerBounds().Max.Y-1 {
+			break
+		}
+
+		fg, bg := list.ItemFgColor, list.ItemBgColor
+		if i == cursor {
+			fg, bg = bg, fg
+		}
+
+		cells := make([]termui.Cell, 0)
+		for _, r := range line {
+			cells = append(cells, termui.Cell{Ch: r, Fg: fg, Bg: bg})
+		}
+		cells = termui.DTrimTxCls(cells, list.InnerWidth())
+
+		x := list.InnerBounds().Min.X
+		for _, cell := range cells {
+			buf.Set(x, y, cell)
+			x += cell.Width()
+		}
+
+		// When not at the end of the pane fill it up empty characters
+		for x < list.InnerBounds().Max.X {
+			buf.Set(x, y, termui.Cell{Ch: ' ', Fg: fg, Bg: bg})
+			x++
+		}
+	}
+}
+
+// SelectableList is a list of items of which one is selected, it's embedded
+// by the components that list e.g. files or mentions. They provide the
+// number of items, and the line of every item.
+type SelectableList struct {
+	List     *termui.List
+	Selected int // index of which item is selected from the List
+	Offset   int // from what offset are items rendered
+
+	count func() int
+	line  func(i int) string
+}
+
+// newSelectableList is the constructor for the SelectableList, label is
+// shown in its border. count returns the number of items, and line the line
+// of the item at index i.
+func newSelectableList(label string, count func() int, line func(i int) string) SelectableList {
+	list := SelectableList{
+		List:  termui.NewList(),
+		count: count,
+		line:  line,
+	}
+
+	list.List.BorderLabel = label
+
+	return list
+}
+
+// Buffer implements interface termui.Bufferer
+func (l *SelectableList) Buffer() termui.Buffer {
+	buf := l.List.Buffer()
+
+	var lines []string
+	for i := l.Offset; i < l.count() && len(lines) < l.List.InnerHeight(); i++ {
+		lines = append(lines, l.line(i))
+	}
+
+	bufferLines(l.List, buf, lines, l.Selected-l.Offset)
+
+	return buf
+}
+
+// GetHeight implements interface termui.GridBufferer
+func (l *SelectableList) GetHeight() int {
+	return l.List.Block.GetHeight()
+}
+
+// SetWidth implements interface termui.GridBufferer
+func (l *SelectableList) SetWidth(w int) {
+	l.List.SetWidth(w)
+}
+
+// SetX implements interface termui.GridBufferer
+func (l *SelectableList) SetX(x int) {
+	l.List.SetX(x)
+}
+
+// SetY implements interface termui.GridBufferer
+func (l *SelectableList) SetY(y int) {
+	l.List.SetY(y)
+}
+
+// MoveCursorUp will decrease Selected by 1
+func (l *SelectableList) MoveCursorUp() {
+	if l.Selected > 0 {
+		l.Selected--
+		l.scrollToSelected()
+	}
+}
+
+// MoveCursorDown will increase Selected by 1
+func (l *SelectableList) MoveCursorDown() {
+	if l.Selected < l.count()-1 {
+		l.Selected++
+		l.scrollToSelected()
+	}
+}
+
+// MoveCursorTop will move the cursor to the top of the items
+func (l *SelectableList) MoveCursorTop() {
+	l.Selected = 0
+	l.Offset = 0
+}
+
+// MoveCursorBottom will move the cursor to the bottom of the items
+func (l *SelectableList) MoveCursorBottom() {
+	l.Selected = l.count() - 1
+	if l.Selected < 0 {
+		l.Selected = 0
+	}
+
+	l.Offset = l.Selected - l.List.InnerHeight() + 1
+	if l.Offset < 0 {
+		l.Offset = 0
+	}
+}
+
+// scrollToSelected will change the Offset so that the selected item is
+// shown
+func (l *SelectableList) scrollToSelected() {
+	if l.Selected < l.Offset {
+		l.Offset = l.Selected
+	} else if l.Selected > l.Offset+l.List.InnerHeight()-1 {
+		l.Offset = l.Selected - l.List.InnerHeight() + 1
+	}
+}
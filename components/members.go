@@ -0,0 +1,90 @@
+package components
+
+import (
+	"fmt"
+
+	"github.com/erroneousboat/termui"
+)
+
+// MemberItem is a member of a channel
+type MemberItem struct {
+	UserID   string
+	Name     string
+	RealName string
+	Presence string
+}
+
+// ToString will set the label of the member, how it will be displayed in
+// the list of members
+func (m MemberItem) ToString() string {
+	icon := IconOffline
+	if m.Presence == PresenceActive {
+		icon = IconOnline
+	}
+
+	return fmt.Sprintf("%s %-20s  %s", icon, m.Name, m.RealName)
+}
+
+// Members lists the members of the selected channel, it replaces the Chat
+// component when it's opened. Members are loaded a page at a time.
+type Members struct {
+	SelectableList
+	ChannelID   string // id of the channel of which the members are listed
+	MemberItems []MemberItem
+	NextCursor  string // cursor of the next page to load
+	Complete    bool   // whether all the pages have been loaded
+}
+
+// CreateMembersComponent is the constructor for the Members component
+func CreateMembersComponent(inputHeight int) *Members {
+	members := &Members{}
+	members.SelectableList = newSelectableList(
+		"Members",
+		func() int { return len(members.MemberItems) },
+		func(i int) string { return members.MemberItems[i].ToString() },
+	)
+
+	members.List.Height = termui.TermHeight() - inputHeight
+
+	return members
+}
+
+// Reset will remove the members, so that the pages of the channel with
+// channelID can be loaded
+func (m *Members) Reset(channelID string) {
+	m.ChannelID = channelID
+	m.MemberItems = nil
+	m.NextCursor = ""
+	m.Complete = false
+	m.MoveCursorTop()
+}
+
+// AddPage will add a page of loaded members, nextCursor is the cursor of
+// the page that follows, which is empty for the last page
+func (m *Members) AddPage(members []MemberItem, nextCursor string) {
+	m.MemberItems = append(m.MemberItems, members...)
+	m.NextCursor = nextCursor
+	m.Complete = nextCursor == ""
+}
+
+// SetPresence will set the presence of the member with userID, it returns
+// whether the user is one of the members
+func (m *Members) SetPresence(userID string, presence string) bool {
+	for i, member := range m.MemberItems {
+		if member.UserID == userID {
+			m.MemberItems[i].Presence = presence
+			return true
+		}
+	}
+	return false
+}
+
+// HasMembers returns whether any members have been loaded
+func (m *Members) HasMembers() bool {
+	return len(m.MemberItems) > 0
+}
+
+// GetSelectedMember returns the MemberItem that is currently selected
+func (m *Members) GetSelectedMember() MemberItem {
+	return m.MemberItems[m.Selected]
+}
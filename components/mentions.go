@@ -0,0 +1,66 @@
+package components
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/erroneousboat/termui"
+)
+
+// MentionItem is a message that mentions the user
+type MentionItem struct {
+	ChannelID   string
+	ChannelName string
+	MessageID   string
+	Name        string
+	Content     string
+	Time        time.Time
+}
+
+// ToString will set the label of the mention, how it will be displayed in
+// the list of mentions
+func (m MentionItem) ToString() string {
+	return fmt.Sprintf(
+		"%s  %-15s  <%s> %s",
+		m.Time.Format("01-02 15:04"), m.ChannelName, m.Name,
+		strings.Replace(m.Content, "\n", " ", -1),
+	)
+}
+
+// Mentions lists the most recent messages that mention the user across the
+// workspace, it replaces the Chat component when it's opened
+type Mentions struct {
+	SelectableList
+	MentionItems []MentionItem
+}
+
+// CreateMentionsComponent is the constructor for the Mentions component
+func CreateMentionsComponent(inputHeight int) *Mentions {
+	mentions := &Mentions{}
+	mentions.SelectableList = newSelectableList(
+		"Mentions",
+		func() int { return len(mentions.MentionItems) },
+		func(i int) string { return mentions.MentionItems[i].ToString() },
+	)
+
+	mentions.List.Height = termui.TermHeight() - inputHeight
+
+	return mentions
+}
+
+// SetMentions will replace the mentions, and select the first one
+func (m *Mentions) SetMentions(mentions []MentionItem) {
+	m.MentionItems = mentions
+	m.MoveCursorTop()
+}
+
+// HasMentions returns whether there are any mentions
+func (m *Mentions) HasMentions() bool {
+	return len(m.MentionItems) > 0
+}
+
+// GetSelectedMention returns the MentionItem that is currently selected
+func (m *Mentions) GetSelectedMention() MentionItem {
+	return m.MentionItems[m.Selected]
+}
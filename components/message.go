@@ -24,16 +24,41 @@ type Message struct {
 	ID       string
 	Messages map[string]Message
 
-	Time    time.Time
-	Thread  string
-	Name    string
-	Content string
+	Time      time.Time
+	Thread    string
+	Name      string
+	UserID    string
+	Content   string
+	Reactions []Reaction
+
+	// Edited is set when the message has been edited, it's marked after
+	// the content
+	Edited bool
+
+	// ReplyCount is the number of replies of a thread parent, and
+	// RepliesCursor is the cursor of the older replies that haven't been
+	// loaded, it's empty when all are loaded.
+	ReplyCount    int
+	RepliesCursor string
+	RepliesLoaded bool
+
+	// LatestReply is the time of the latest reply of a thread parent,
+	// it's known before the replies are loaded
+	LatestReply time.Time
+
+	// Attachments is the number of attachments and files of the message,
+	// they're part of Messages without a time
+	Attachments int
 
 	StyleTime   string
 	StyleThread string
 	StyleName   string
 	StyleText   string
 
+	// StyleMention is the style of the mentions of users and user groups
+	// in the content, e.g. "@backend-team"
+	StyleMention string
+
 	FormatTime string
 }
 
@@ -63,6 +88,65 @@ func (m Message) GetContent() string {
 	return fmt.Sprintf("[.](%s)", m.StyleText)
 }
 
+// GetRepliesSummary returns the number of replies of a thread parent, and
+// when the latest reply was posted, e.g. "5 replies, last at 14:32"
+func (m Message) GetRepliesSummary() string {
+	summary := fmt.Sprintf("%d replies", m.ReplyCount)
+	if m.ReplyCount == 1 {
+		summary = "1 reply"
+	}
+
+	if (m.LatestReply != time.Time{}) {
+		summary = fmt.Sprintf("%s, last at %s", summary, m.LatestReply.Format(m.FormatTime))
+	}
+
+	return summary
+}
+
+// GetAttachmentsSummary returns the number of attachments of the message,
+// e.g. "3 attachments"
+func (m Message) GetAttachmentsSummary() string {
+	if m.Attachments == 1 {
+		return "1 attachment"
+	}
+	return fmt.Sprintf("%d attachments", m.Attachments)
+}
+
+// GetReactions returns the reactions on a message, e.g. ":+1: 2 :tada: 1"
+func (m Message) GetReactions() string {
+	reactions := make([]string, 0)
+	for _, r := range m.Reactions {
+		reactions = append(reactions, fmt.Sprintf("%s %d", r.Name, r.Count))
+	}
+	return strings.Join(reactions, "  ")
+}
+
+// addReaction will add count reactions with name to the message, or remove
+// them when count is negative. Reactions without users are removed, own is
+// set when the reaction is of the current user.
+func (m *Message) addReaction(name string, count int, own bool) {
+	reactions := make([]Reaction, 0, len(m.Reactions)+1)
+	found := false
+	for _, r := range m.Reactions {
+		if r.Name == name {
+			r.Count += count
+			if own {
+				r.Reacted = count > 0
+			}
+			found = true
+		}
+		if r.Count > 0 {
+			reactions = append(reactions, r)
+		}
+	}
+
+	if !found && count > 0 {
+		reactions = append(reactions, Reaction{Name: name, Count: count, Reacted: own})
+	}
+
+	m.Reactions = reactions
+}
+
 func (m Message) colorizeName(styleName string) string {
 	if strings.Contains(styleName, "colorize") {
 		var sum int
@@ -78,6 +162,15 @@ func (m Message) colorizeName(styleName string) string {
 	return styleName
 }
 
+// Reaction is an emoji reaction on a message, with the number of users
+// that have reacted with it. Reacted is set when the current user is one
+// of them.
+type Reaction struct {
+	Name    string
+	Count   int
+	Reacted bool
+}
+
 func SortMessages(msgs map[string]Message) []Message {
 	keys := make([]string, 0)
 	for k := range msgs {
@@ -5,9 +5,22 @@ import (
 )
 
 const (
-	CommandMode = "NORMAL"
-	InsertMode  = "INSERT"
-	SearchMode  = "SEARCH"
+	CommandMode   = "NORMAL"
+	InsertMode    = "INSERT"
+	SearchMode    = "SEARCH"
+	BrowseMode    = "BROWSE"
+	FilesMode     = "FILES"
+	MentionsMode  = "MENTIONS"
+	FollowUpsMode = "FOLLOW-UPS"
+	MembersMode   = "MEMBERS"
+	SelectMode    = "SELECT"
+	ReactionMode  = "REACTION"
+	SwitcherMode  = "JUMP"
+	InfoMode      = "INFO"
+	TasksMode     = "TASKS"
+	FindMode      = "FIND"
+
+	CommandLineMode = "COMMAND"
 )
 
 // Mode is the definition of Mode component
@@ -104,3 +117,63 @@ func (m *Mode) SetSearchMode() {
 	m.Par.Text = SearchMode
 	termui.Render(m)
 }
+
+func (m *Mode) SetBrowseMode() {
+	m.Par.Text = BrowseMode
+	termui.Render(m)
+}
+
+func (m *Mode) SetFilesMode() {
+	m.Par.Text = FilesMode
+	termui.Render(m)
+}
+
+func (m *Mode) SetMentionsMode() {
+	m.Par.Text = MentionsMode
+	termui.Render(m)
+}
+
+func (m *Mode) SetFollowUpsMode() {
+	m.Par.Text = FollowUpsMode
+	termui.Render(m)
+}
+
+func (m *Mode) SetMembersMode() {
+	m.Par.Text = MembersMode
+	termui.Render(m)
+}
+
+func (m *Mode) SetSelectMode() {
+	m.Par.Text = SelectMode
+	termui.Render(m)
+}
+
+func (m *Mode) SetReactionMode() {
+	m.Par.Text = ReactionMode
+	termui.Render(m)
+}
+
+func (m *Mode) SetSwitcherMode() {
+	m.Par.Text = SwitcherMode
+	termui.Render(m)
+}
+
+func (m *Mode) SetInfoMode() {
+	m.Par.Text = InfoMode
+	termui.Render(m)
+}
+
+func (m *Mode) SetTasksMode() {
+	m.Par.Text = TasksMode
+	termui.Render(m)
+}
+
+func (m *Mode) SetFindMode() {
+	m.Par.Text = FindMode
+	termui.Render(m)
+}
+
+func (m *Mode) SetCommandLineMode() {
+	m.Par.Text = CommandLineMode
+	termui.Render(m)
+}
@@ -0,0 +1,143 @@
+package components
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/erroneousboat/termui"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// spinnerFrames are the frames of the Spinner, the next one is shown on
+// every tick
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// spinnerTask is an operation that is in progress
+type spinnerTask struct {
+	id      int
+	message string
+}
+
+// Spinner shows that operations are in progress in a pane, e.g. that the
+// history of a channel is being loaded, with a message about their
+// progress. It's drawn on the right of the top border of the pane, and only
+// while there are operations in progress.
+type Spinner struct {
+	Par   *termui.Par
+	Block *termui.Block // block of the pane the Spinner is drawn on
+
+	tasks   []spinnerTask
+	nextID  int
+	frame   int
+	running bool
+	mu      sync.Mutex
+}
+
+// CreateSpinnerComponent is the constructor for the Spinner component, it's
+// drawn on the block of a pane
+func CreateSpinnerComponent(block *termui.Block) *Spinner {
+	spinner := &Spinner{
+		Par:   termui.NewPar(""),
+		Block: block,
+	}
+
+	spinner.Par.Border = false
+	spinner.Par.Height = 1
+	spinner.Par.TextFgColor = termui.ColorYellow
+
+	return spinner
+}
+
+// Buffer implements interface termui.Bufferer
+func (s *Spinner) Buffer() termui.Buffer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.tasks) == 0 {
+		return termui.NewBuffer()
+	}
+
+	// The message of the operation that was started first is shown, with
+	// the number of other operations
+	message := s.tasks[0].message
+	if len(s.tasks) > 1 {
+		message = fmt.Sprintf("%s (+%d)", message, len(s.tasks)-1)
+	}
+	s.Par.Text = fmt.Sprintf(" %s %s ", spinnerFrames[s.frame], message)
+
+	s.Par.Width = runewidth.StringWidth(s.Par.Text)
+	if s.Par.Width > s.Block.Width-4 {
+		s.Par.Width = s.Block.Width - 4
+	}
+
+	s.Par.X = s.Block.X + s.Block.Width - s.Par.Width - 2
+	s.Par.Y = s.Block.Y
+
+	return s.Par.Buffer()
+}
+
+// Start will add an operation with the message, it returns the id of the
+// operation, and whether the Spinner has to be started because it wasn't
+// running
+func (s *Spinner) Start(message string) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.nextID++
+	s.tasks = append(s.tasks, spinnerTask{id: s.nextID, message: message})
+
+	start := !s.running
+	s.running = true
+
+	return s.nextID, start
+}
+
+// SetMessage will replace the message of the operation with id, e.g. to
+// show its progress
+func (s *Spinner) SetMessage(id int, message string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, task := range s.tasks {
+		if task.id == id {
+			s.tasks[i].message = message
+			return
+		}
+	}
+}
+
+// Stop will remove the operation with id, once it's done
+func (s *Spinner) Stop(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, task := range s.tasks {
+		if task.id == id {
+			s.tasks = append(s.tasks[:i], s.tasks[i+1:]...)
+			return
+		}
+	}
+}
+
+// Tick will show the next frame, it returns false when there are no
+// operations in progress anymore, and the Spinner stops running
+func (s *Spinner) Tick() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.tasks) == 0 {
+		s.running = false
+		return false
+	}
+
+	s.frame = (s.frame + 1) % len(spinnerFrames)
+	return true
+}
+
+// IsShown returns whether there are operations in progress
+func (s *Spinner) IsShown() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.tasks) > 0
+}
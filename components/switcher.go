@@ -0,0 +1,189 @@
+package components
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/erroneousboat/termui"
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+const (
+	// switcherWidth and switcherHeight are the maximum size of the
+	// Switcher popup
+	switcherWidth  = 60
+	switcherHeight = 16
+)
+
+// Switcher is a popup that jumps to a conversation, the conversations are
+// filtered by what is typed in the popup. It's shown on top of the Chat
+// component.
+type Switcher struct {
+	List     *termui.List
+	Term     string        // the term the conversations are filtered by
+	Items    []ChannelItem // all the conversations
+	Matches  []ChannelItem // the conversations that match the Term
+	Selected int           // index of which match is selected
+	Offset   int           // from what offset are matches rendered
+}
+
+// CreateSwitcherComponent is the constructor for the Switcher component
+func CreateSwitcherComponent() *Switcher {
+	switcher := &Switcher{
+		List: termui.NewList(),
+	}
+
+	switcher.List.BorderLabel = "Jump to"
+
+	return switcher
+}
+
+// Buffer implements interface termui.Bufferer
+func (s *Switcher) Buffer() termui.Buffer {
+	buf := s.List.Buffer()
+
+	// The first line is the term, followed by the matches
+	lines := []string{"> " + s.Term + "_"}
+	for _, item := range s.Matches[s.Offset:] {
+		lines = append(lines, switcherLabel(item))
+	}
+
+	bufferLines(s.List, buf, lines, s.Selected-s.Offset+1)
+
+	return buf
+}
+
+// switcherLabel returns how a conversation is displayed in the Switcher
+func switcherLabel(item ChannelItem) string {
+	label := item.GetName()
+	switch item.Type {
+	case ChannelTypeChannel, ChannelTypeGroup:
+		label = "#" + label
+	case ChannelTypeIM:
+		label = "@" + label
+	}
+
+	if item.Notification {
+		label += " " + IconNotification
+	}
+
+	return label
+}
+
+// Show will open the popup on top of the pane at x, y with the width and
+// height, with all of the conversations in items
+func (s *Switcher) Show(items []ChannelItem, x, y, width, height int) {
+	s.Items = items
+
+	s.List.Width = width - 4
+	if s.List.Width > switcherWidth {
+		s.List.Width = switcherWidth
+	}
+	s.List.Height = height - 4
+	if s.List.Height > switcherHeight {
+		s.List.Height = switcherHeight
+	}
+
+	s.List.X = x + (width-s.List.Width)/2
+	s.List.Y = y + (height-s.List.Height)/3
+
+	s.Filter("")
+}
+
+// Filter will only show the conversations of which the name matches term,
+// the best matches first
+func (s *Switcher) Filter(term string) {
+	s.Term = term
+	s.Selected = 0
+	s.Offset = 0
+
+	type match struct {
+		item  ChannelItem
+		score int
+	}
+
+	var matches []match
+	for _, item := range s.Items {
+		if score, ok := scoreSwitcherName(term, item.Name); ok {
+			matches = append(matches, match{item, score})
+		}
+	}
+
+	// Matches that score the same keep the order of the sidebar
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+
+	s.Matches = make([]ChannelItem, 0, len(matches))
+	for _, m := range matches {
+		s.Matches = append(s.Matches, m.item)
+	}
+}
+
+// scoreSwitcherName returns how well name matches term, case insensitive. An
+// exact match scores highest, followed by names that start with the term,
+// names in which a word starts with it, names that contain it, and names
+// that contain its characters in order.
+func scoreSwitcherName(term, name string) (int, bool) {
+	term, name = strings.ToLower(term), strings.ToLower(name)
+
+	switch index := strings.Index(name, term); {
+	case term == "":
+		return 0, true
+	case name == term:
+		return 4000, true
+	case index == 0:
+		return 3000 - len(name), true
+	case index > 0 && strings.ContainsRune("-_. ", rune(name[index-1])):
+		return 2000 - index, true
+	case index > 0:
+		return 1000 - index, true
+	}
+
+	if fuzzy.Match(term, name) {
+		return -len(name), true
+	}
+
+	return 0, false
+}
+
+// Backspace will remove the last character of the term
+func (s *Switcher) Backspace() {
+	if s.Term == "" {
+		return
+	}
+
+	term := []rune(s.Term)
+	s.Filter(string(term[:len(term)-1]))
+}
+
+// HasMatches returns whether any conversations match the term
+func (s *Switcher) HasMatches() bool {
+	return len(s.Matches) > 0
+}
+
+// GetSelectedMatch returns the conversation that is selected
+func (s *Switcher) GetSelectedMatch() ChannelItem {
+	return s.Matches[s.Selected]
+}
+
+// MoveCursorUp will decrease Selected by 1
+func (s *Switcher) MoveCursorUp() {
+	if s.Selected > 0 {
+		s.Selected--
+		if s.Selected < s.Offset {
+			s.Offset = s.Selected
+		}
+	}
+}
+
+// MoveCursorDown will increase Selected by 1, the first line of the List is
+// taken by the term
+func (s *Switcher) MoveCursorDown() {
+	if s.Selected < len(s.Matches)-1 {
+		s.Selected++
+		if s.Selected > s.Offset+s.List.InnerHeight()-2 {
+			s.Offset = s.Selected - s.List.InnerHeight() + 2
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package components
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/erroneousboat/termui"
+)
+
+const (
+	// tasksWidth and tasksHeight are the maximum size of the Tasks popup
+	tasksWidth  = 80
+	tasksHeight = 16
+)
+
+// TaskItem is an operation that is in progress
+type TaskItem struct {
+	ID          int
+	Description string
+	Progress    string
+	Started     time.Time
+}
+
+// ToString will set the label of the task, how it will be displayed in the
+// Tasks popup
+func (t TaskItem) ToString() string {
+	elapsed := time.Since(t.Started).Round(time.Second)
+
+	label := fmt.Sprintf("%-6s %s", elapsed, t.Description)
+	if t.Progress != "" {
+		label = fmt.Sprintf("%s  %s", label, t.Progress)
+	}
+	return label
+}
+
+// Tasks is a popup with the operations that are in progress, the selected
+// one can be cancelled. It's shown on top of the Chat component.
+type Tasks struct {
+	SelectableList
+	TaskItems []TaskItem
+}
+
+// CreateTasksComponent is the constructor for the Tasks component
+func CreateTasksComponent() *Tasks {
+	tasks := &Tasks{}
+	tasks.SelectableList = newSelectableList(
+		"Tasks",
+		func() int { return len(tasks.TaskItems) },
+		func(i int) string { return tasks.TaskItems[i].ToString() },
+	)
+
+	return tasks
+}
+
+// Buffer implements interface termui.Bufferer
+func (t *Tasks) Buffer() termui.Buffer {
+	if len(t.TaskItems) == 0 {
+		buf := t.List.Buffer()
+		bufferLines(t.List, buf, []string{"No tasks in progress"}, -1)
+		return buf
+	}
+
+	return t.SelectableList.Buffer()
+}
+
+// Show will open the popup on top of the pane at x, y with the width and
+// height
+func (t *Tasks) Show(x, y, width, height int) {
+	t.List.Width = width - 4
+	if t.List.Width > tasksWidth {
+		t.List.Width = tasksWidth
+	}
+	t.List.Height = height - 4
+	if t.List.Height > tasksHeight {
+		t.List.Height = tasksHeight
+	}
+
+	t.List.X = x + (width-t.List.Width)/2
+	t.List.Y = y + (height-t.List.Height)/3
+}
+
+// SetTasks will replace the tasks, the selected task stays selected while
+// it's in progress
+func (t *Tasks) SetTasks(tasks []TaskItem) {
+	selected := -1
+	if t.HasTasks() {
+		selected = t.GetSelectedTask().ID
+	}
+
+	t.TaskItems = tasks
+	t.Selected = 0
+	for i, task := range tasks {
+		if task.ID == selected {
+			t.Selected = i
+			break
+		}
+	}
+
+	t.scrollToSelected()
+}
+
+// HasTasks returns whether any tasks are in progress
+func (t *Tasks) HasTasks() bool {
+	return len(t.TaskItems) > 0
+}
+
+// GetSelectedTask returns the task that is selected
+func (t *Tasks) GetSelectedTask() TaskItem {
+	return t.TaskItems[t.Selected]
+}
@@ -1,26 +1,202 @@
 package components
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/erroneousboat/termui"
 )
 
+// moreRepliesID is the key of the placeholder message which indicates that
+// not all replies of a thread have been loaded. It sorts before timestamps,
+// so it's shown above the thread, where the older replies are loaded.
+const moreRepliesID = "!more"
+
+// Threads shows a single thread of the selected channel: the parent
+// message followed by all of its replies. It can be scrolled independently
+// of the Chat component.
 type Threads struct {
-	*Channels
+	*Chat
+
+	ThreadItems    []ChannelItem // the threads of the selected channel
+	SelectedThread int           // index of the thread that is shown
 }
 
-func CreateThreadsComponent(height int) *Threads {
+// CreateThreadsComponent is the constructor for the Threads component
+func CreateThreadsComponent(inputHeight int) *Threads {
 	threads := &Threads{
-		Channels: &Channels{
-			List: termui.NewList(),
+		Chat: &Chat{
+			List:     termui.NewList(),
+			Messages: make(map[string]Message),
+			Offset:   0,
+
+			// The attachments of the thread can't be toggled in the
+			// Threads pane, they're always shown
+			ExpandAttachments: true,
 		},
+		ThreadItems:    make([]ChannelItem, 0),
+		SelectedThread: 0,
 	}
 
 	threads.List.BorderLabel = "Threads"
-	threads.List.Height = height
-
-	threads.SelectedChannel = 0
-	threads.Offset = 0
-	threads.CursorPosition = threads.List.InnerBounds().Min.Y
+	threads.List.Height = termui.TermHeight() - inputHeight
+	threads.List.Overflow = "wrap"
 
 	return threads
 }
+
+// SetThreads will set the threads of the selected channel, ordered from
+// newest to oldest, and selects the newest one
+func (t *Threads) SetThreads(threads []ChannelItem) {
+	t.ThreadItems = threads
+	t.SelectedThread = 0
+	t.ClearMessages()
+}
+
+// AddThread will add a thread, keeping the threads ordered from newest to
+// oldest. The thread that is shown remains selected.
+func (t *Threads) AddThread(thread ChannelItem) {
+	index := len(t.ThreadItems)
+	for i, item := range t.ThreadItems {
+		if item.ID < thread.ID {
+			index = i
+			break
+		}
+	}
+
+	t.ThreadItems = append(t.ThreadItems, ChannelItem{})
+	copy(t.ThreadItems[index+1:], t.ThreadItems[index:])
+	t.ThreadItems[index] = thread
+
+	if index <= t.SelectedThread && len(t.ThreadItems) > 1 {
+		t.SelectedThread++
+	}
+}
+
+// HasThreads returns whether the selected channel has threads
+func (t *Threads) HasThreads() bool {
+	return len(t.ThreadItems) > 0
+}
+
+// GetSelectedThread returns the ChannelItem of the thread that is shown
+func (t *Threads) GetSelectedThread() ChannelItem {
+	return t.ThreadItems[t.SelectedThread]
+}
+
+// MoveCursorUp will select the previous thread
+func (t *Threads) MoveCursorUp() {
+	if t.SelectedThread > 0 {
+		t.SelectedThread--
+	}
+}
+
+// MoveCursorDown will select the next thread
+func (t *Threads) MoveCursorDown() {
+	if t.SelectedThread < len(t.ThreadItems)-1 {
+		t.SelectedThread++
+	}
+}
+
+// SetThread will show the parent message of a thread, the replies are
+// expected to be present in the Messages field of the parent
+func (t *Threads) SetThread(parent Message) {
+	t.ClearMessages()
+	t.SetMessages([]Message{parent})
+
+	if hasMoreReplies(parent) {
+		t.AddMessage(Message{
+			ID: moreRepliesID,
+			Content: fmt.Sprintf(
+				"    ... %d older replies, scroll up to load them",
+				parent.ReplyCount-countReplies(parent),
+			),
+		})
+	}
+
+	t.SetBorderLabel(
+		fmt.Sprintf(
+			"Thread %s (%d/%d)",
+			strings.TrimSpace(t.GetSelectedThread().Name),
+			t.SelectedThread+1,
+			len(t.ThreadItems),
+		),
+	)
+}
+
+// GetThread returns the parent message of the thread that is shown
+func (t *Threads) GetThread() (Message, bool) {
+	if !t.HasThreads() {
+		return Message{}, false
+	}
+
+	parent, ok := t.Messages[t.GetSelectedThread().ID]
+	return parent, ok
+}
+
+// ScrollUp will scroll up the Threads pane, up to the top of the thread
+func (t *Threads) ScrollUp() {
+	t.Offset = t.Offset + 10
+
+	// Protect overscrolling
+	if max := t.maxOffset(); t.Offset > max {
+		t.Offset = max
+	}
+}
+
+// AtTop returns whether the Threads pane is scrolled up to the top of the
+// thread
+func (t *Threads) AtTop() bool {
+	return t.Offset >= t.maxOffset()
+}
+
+// maxOffset returns the Offset at which the top of the thread is shown
+func (t *Threads) maxOffset() int {
+	max := len(t.Lines()) - t.List.InnerHeight()
+	if max < 0 {
+		return 0
+	}
+	return max
+}
+
+// HasMoreReplies returns whether not all replies of the thread that is
+// shown have been loaded
+func (t *Threads) HasMoreReplies() bool {
+	parent, ok := t.GetThread()
+	return ok && hasMoreReplies(parent)
+}
+
+// AddReplies will add a page of replies to the thread that is shown, cursor
+// is the cursor of the older replies. It returns the updated parent.
+func (t *Threads) AddReplies(replies []Message, cursor string) Message {
+	parent, _ := t.GetThread()
+	for _, reply := range replies {
+		parent.Messages[reply.ID] = reply
+	}
+	parent.RepliesCursor = cursor
+	parent.RepliesLoaded = true
+
+	t.SetThread(parent)
+
+	return parent
+}
+
+// hasMoreReplies returns whether a parent message has replies that haven't
+// been loaded, either because loading them failed or there are more pages
+func hasMoreReplies(parent Message) bool {
+	if parent.ReplyCount == 0 {
+		return false
+	}
+	return !parent.RepliesLoaded || parent.RepliesCursor != ""
+}
+
+// countReplies will count the replies of a parent message, these are the
+// messages with a name, unlike attachments and files
+func countReplies(parent Message) int {
+	var count int
+	for _, msg := range parent.Messages {
+		if msg.Name != "" {
+			count++
+		}
+	}
+	return count
+}
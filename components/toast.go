@@ -0,0 +1,99 @@
+package components
+
+import (
+	"sync"
+
+	"github.com/erroneousboat/termui"
+	runewidth "github.com/mattn/go-runewidth"
+)
+
+// toastMaxWidth is the maximum width of the Toast
+const toastMaxWidth = 60
+
+// Toast shows short messages that confirm an action, e.g. that a file has
+// been downloaded, in the bottom right corner of a pane. The messages are
+// queued, and shown one after another until they're dismissed.
+type Toast struct {
+	Par *termui.Par
+
+	// Messages are the queued messages, the first one is shown
+	Messages []string
+
+	mu sync.Mutex
+}
+
+// CreateToastComponent is the constructor for the Toast component
+func CreateToastComponent() *Toast {
+	toast := &Toast{
+		Par: termui.NewPar(""),
+	}
+
+	toast.Par.Height = 3
+	toast.Par.BorderFg = termui.ColorGreen
+
+	return toast
+}
+
+// Buffer implements interface termui.Bufferer
+func (t *Toast) Buffer() termui.Buffer {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.Messages) > 0 {
+		t.Par.Text = t.Messages[0]
+	}
+
+	return t.Par.Buffer()
+}
+
+// Place will position the Toast in the bottom right corner of the pane at
+// x, y with the width and height, sized to the message that is shown
+func (t *Toast) Place(x, y, width, height int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.Messages) == 0 {
+		return
+	}
+
+	t.Par.Width = runewidth.StringWidth(t.Messages[0]) + 2
+	if t.Par.Width > toastMaxWidth {
+		t.Par.Width = toastMaxWidth
+	}
+	if t.Par.Width > width-2 {
+		t.Par.Width = width - 2
+	}
+
+	t.Par.X = x + width - t.Par.Width - 1
+	t.Par.Y = y + height - t.Par.Height - 1
+}
+
+// Push will add a message to the queue, it returns whether it's shown
+// right away because the queue was empty
+func (t *Toast) Push(message string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Messages = append(t.Messages, message)
+	return len(t.Messages) == 1
+}
+
+// Pop will dismiss the message that is shown, it returns whether there is
+// a next message to show
+func (t *Toast) Pop() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.Messages) > 0 {
+		t.Messages = t.Messages[1:]
+	}
+	return len(t.Messages) > 0
+}
+
+// IsShown returns whether a message is shown
+func (t *Toast) IsShown() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return len(t.Messages) > 0
+}
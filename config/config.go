@@ -7,6 +7,9 @@ import (
 	"io/ioutil"
 	"os"
 	fp "path/filepath"
+	"runtime"
+	"sort"
+	"strings"
 
 	"github.com/OpenPeeDeeP/xdg"
 	"github.com/erroneousboat/termui"
@@ -17,19 +20,74 @@ const (
 	NotifyMention = "mention"
 )
 
+// The conversation that is selected on startup is the first one in the
+// sidebar, or with StartupUnread the one with the oldest unread mention or
+// else the most recent direct message
+const (
+	StartupFirst  = "first"
+	StartupUnread = "unread"
+)
+
+// HistoryMaxCount is the maximum number of messages that is fetched of the
+// history of a channel
+const HistoryMaxCount = 1000
+
 // Config is the definition of a Config struct
 type Config struct {
-	SlackToken   string                `json:"slack_token"`
-	SlackCookie  string                `json:"slack_cookie"`
-	SlackApiUrl  string                `json:"slack_api_url"`
-	Notify       string                `json:"notify"`
-	Emoji        bool                  `json:"emoji"`
-	SidebarWidth int                   `json:"sidebar_width"`
-	MainWidth    int                   `json:"-"`
-	ThreadsWidth int                   `json:"threads_width"`
-	KeyMap       map[string]keyMapping `json:"key_map"`
-	Theme        Theme                 `json:"theme"`
-	IsEnterprise bool                  `json:"is_enterprise"`
+	Version           int                   `json:"version"`
+	SlackToken        string                `json:"slack_token"`
+	SlackCookie       string                `json:"slack_cookie"`
+	SlackApiUrl       string                `json:"slack_api_url"`
+	SlackRefreshToken string                `json:"slack_refresh_token"`
+	SlackClientID     string                `json:"slack_client_id"`
+	SlackClientSecret string                `json:"slack_client_secret"`
+	EncryptedSecrets  string                `json:"encrypted_secrets"`
+	SecretsCommand    string                `json:"secrets_command"`
+	Askpass           string                `json:"askpass"`
+	Notify            string                `json:"notify"`
+	StartupChannel    string                `json:"startup_channel"`
+	NotifyPreview     bool                  `json:"notify_preview"`
+	Emoji             bool                  `json:"emoji"`
+	EmojiFile         string                `json:"emoji_file"`
+	EmojiChannels     map[string]bool       `json:"emoji_channels"`
+	DownloadDir       string                `json:"download_dir"`
+	ClipboardCommand  string                `json:"clipboard_command"`
+	OpenCommand       string                `json:"open_command"`
+	SidebarWidth      int                   `json:"sidebar_width"`
+	MainWidth         int                   `json:"-"`
+	ThreadsWidth      int                   `json:"threads_width"`
+	ChannelRefresh    int                   `json:"channel_refresh"`
+	AwayAfter         int                   `json:"away_after"`
+	AutoReply         string                `json:"auto_reply"`
+	AutoReplyCooldown int                   `json:"auto_reply_cooldown"`
+	GroupMinutes      int                   `json:"group_minutes"`
+	ShowSeconds       bool                  `json:"show_seconds"`
+	ExpandAttachments bool                  `json:"expand_attachments"`
+	ShowDeactivated   bool                  `json:"show_deactivated"`
+	HiddenTypes       []string              `json:"hidden_channel_types"`
+	BroadcastWarn     int                   `json:"broadcast_warn"`
+	HistoryDays       int                   `json:"history_days"`
+	HistoryCount      int                   `json:"history_count"`
+	StatusClock       string                `json:"status_clock"`
+	StatusUser        bool                  `json:"status_user"`
+	KeyMap            map[string]keyMapping `json:"key_map"`
+	Slots             map[string]string     `json:"slots"`
+	ChannelOrder      []string              `json:"channel_order"`
+	MutedChannels     []string              `json:"muted_channels"`
+	Theme             Theme                 `json:"theme"`
+	IsEnterprise      bool                  `json:"is_enterprise"`
+
+	// Warnings about options that were dropped when the config file was
+	// migrated to the current version
+	Warnings []string `json:"-"`
+
+	// TokenEntered is set when the slack token has been entered in the
+	// prompt, the access token of a previous session isn't used then
+	TokenEntered bool `json:"-"`
+
+	// secretsKey is the key of the encrypted_secrets, the tokens that are
+	// persisted are encrypted with it, see SealSecret
+	secretsKey []byte
 }
 
 type keyMapping map[string]string
@@ -48,16 +106,107 @@ func NewConfig(filepath string) (*Config, error) {
 		}
 	}
 
-	if err := json.NewDecoder(file).Decode(&cfg); err != nil {
+	data, err := ioutil.ReadAll(file)
+	file.Close()
+	if err != nil {
+		return &cfg, fmt.Errorf("couldn't read the slack-term config file: (%v)", err)
+	}
+
+	// Decode the config file and migrate it to the current version, before
+	// it is merged with the default config
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
 		return &cfg, fmt.Errorf("the slack-term config file isn't valid json: (%v)", err)
 	}
 
+	if raw == nil {
+		raw = make(map[string]interface{})
+	}
+
+	version := configVersion(raw)
+	cfg.Warnings, err = migrateConfig(raw)
+	if err != nil {
+		return &cfg, err
+	}
+
+	// The migrated config file is written, so the warnings are only shown
+	// once
+	if version < ConfigVersion {
+		if err := writeMigratedConfig(file.Name(), data, raw); err != nil {
+			cfg.Warnings = append(
+				cfg.Warnings,
+				fmt.Sprintf("couldn't write the migrated config file: %v", err),
+			)
+		} else {
+			cfg.Warnings = append(
+				cfg.Warnings,
+				fmt.Sprintf("the config file has been migrated to version %d, the original is kept at %s.bak", ConfigVersion, file.Name()),
+			)
+		}
+	}
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return &cfg, err
+	}
+
+	if err := json.Unmarshal(migrated, &cfg); err != nil {
+		return &cfg, fmt.Errorf("the slack-term config file isn't valid: (%v)", err)
+	}
+
 	if cfg.SidebarWidth < 1 || cfg.SidebarWidth > 11 {
 		return &cfg, errors.New("please specify the 'sidebar_width' between 1 and 11")
 	}
 
 	cfg.MainWidth = 12 - cfg.SidebarWidth
 
+	if cfg.ChannelRefresh < 0 {
+		return &cfg, errors.New("please specify the 'channel_refresh' in minutes, or 0 to disable it")
+	}
+
+	if cfg.AwayAfter < 0 {
+		return &cfg, errors.New("please specify the 'away_after' in minutes, or 0 to disable it")
+	}
+
+	if cfg.AutoReplyCooldown < 1 {
+		return &cfg, errors.New("please specify the 'auto_reply_cooldown' in minutes of at least 1")
+	}
+
+	if cfg.GroupMinutes < 0 {
+		return &cfg, errors.New("please specify the 'group_minutes' in minutes, or 0 to disable grouping")
+	}
+
+	if cfg.HistoryDays < 1 {
+		return &cfg, errors.New("please specify the 'history_days' as a number of days of at least 1")
+	}
+
+	if cfg.HistoryCount < 0 || cfg.HistoryCount > HistoryMaxCount {
+		return &cfg, fmt.Errorf("please specify the 'history_count' up to %d messages, or 0 to fill the chat pane", HistoryMaxCount)
+	}
+
+	if cfg.BroadcastWarn < 0 {
+		return &cfg, errors.New("please specify the 'broadcast_warn' in members, or 0 to disable the warning")
+	}
+
+	if cfg.EmojiFile != "" {
+		emojiFile := cfg.EmojiFile
+		if !fp.IsAbs(emojiFile) {
+			emojiFile = fp.Join(fp.Dir(filepath), emojiFile)
+		}
+
+		if err := LoadEmojiFile(emojiFile); err != nil {
+			return &cfg, fmt.Errorf("couldn't load the emoji file: (%v)", err)
+		}
+	}
+
+	if layout, ok := TimeFormats[cfg.Theme.Message.TimeFormat]; ok {
+		cfg.Theme.Message.TimeFormat = layout
+	}
+
+	if layout, ok := TimeFormats[cfg.StatusClock]; ok {
+		cfg.StatusClock = layout
+	}
+
 	switch cfg.Notify {
 	case NotifyAll, NotifyMention, "":
 		break
@@ -65,6 +214,17 @@ func NewConfig(filepath string) (*Config, error) {
 		return &cfg, fmt.Errorf("unsupported setting for notify: %s", cfg.Notify)
 	}
 
+	switch cfg.StartupChannel {
+	case StartupFirst, StartupUnread:
+		break
+	default:
+		return &cfg, fmt.Errorf("unsupported setting for startup_channel: %s", cfg.StartupChannel)
+	}
+
+	if cfg.EncryptedSecrets != "" && cfg.SecretsCommand != "" {
+		return &cfg, errors.New("please specify either the 'encrypted_secrets' or the 'secrets_command'")
+	}
+
 	termui.ColorMap = map[string]termui.Attribute{
 		"fg":        termui.StringToAttribute(cfg.Theme.View.Fg),
 		"bg":        termui.StringToAttribute(cfg.Theme.View.Bg),
@@ -72,11 +232,74 @@ func NewConfig(filepath string) (*Config, error) {
 		"border.bg": termui.StringToAttribute(cfg.Theme.View.BorderBg),
 		"label.fg":  termui.StringToAttribute(cfg.Theme.View.LabelFg),
 		"label.bg":  termui.StringToAttribute(cfg.Theme.View.LabelBg),
+		"focus.fg":  termui.StringToAttribute(cfg.Theme.View.FocusFg),
 	}
 
 	return &cfg, nil
 }
 
+// EmojiEnabled returns whether emoji are rendered in a channel, this can be
+// overridden per channel by name or id in EmojiChannels
+func (c *Config) EmojiEnabled(channelID string, channelName string) bool {
+	if enabled, ok := c.EmojiChannels[channelID]; ok {
+		return enabled
+	}
+	if enabled, ok := c.EmojiChannels[channelName]; ok {
+		return enabled
+	}
+	return c.Emoji
+}
+
+// SetEmojiChannel will override whether emoji are rendered in a channel
+func (c *Config) SetEmojiChannel(channelID string, enabled bool) {
+	if c.EmojiChannels == nil {
+		c.EmojiChannels = make(map[string]bool)
+	}
+	c.EmojiChannels[channelID] = enabled
+}
+
+// GetKey returns the key that is bound to an action in a mode, e.g. "F" for
+// mode-files in command mode. When several keys are bound to it the first
+// of them is returned.
+func (c *Config) GetKey(mode string, action string) (string, bool) {
+	var keys []string
+	for key, bound := range c.KeyMap[mode] {
+		if bound == action {
+			keys = append(keys, key)
+		}
+	}
+
+	if len(keys) == 0 {
+		return "", false
+	}
+
+	sort.Strings(keys)
+	return keys[0], true
+}
+
+// LoadEmojiFile will merge the emoji of a json file into the EmojiCodemap,
+// overriding the emoji that are already present. The file maps shortcodes,
+// with or without the surrounding colons, to the text they are rendered as,
+// e.g. {"partyparrot": "\U0001f99c"}.
+func LoadEmojiFile(filepath string) error {
+	data, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return err
+	}
+
+	var codemap map[string]string
+	if err := json.Unmarshal(data, &codemap); err != nil {
+		return err
+	}
+
+	for code, emoji := range codemap {
+		code = fmt.Sprintf(":%s:", strings.Trim(code, ":"))
+		EmojiCodemap[code] = emoji
+	}
+
+	return nil
+}
+
 func CreateConfigFile(filepath string) (*os.File, error) {
 	filepath = fp.Join(xdg.ConfigHome(), "slack-term", "config")
 
@@ -84,8 +307,8 @@ func CreateConfigFile(filepath string) (*os.File, error) {
 		os.MkdirAll(fp.Dir(filepath), os.ModePerm)
 	}
 
-	payload := "{\"slack_token\": \"\"}"
-	err := ioutil.WriteFile(filepath, []byte(payload), 0755)
+	payload := fmt.Sprintf("{\"version\": %d, \"slack_token\": \"\"}", ConfigVersion)
+	err := ioutil.WriteFile(filepath, []byte(payload), 0600)
 	if err != nil {
 		return nil, err
 	}
@@ -99,12 +322,24 @@ func CreateConfigFile(filepath string) (*os.File, error) {
 }
 
 func getDefaultConfig() Config {
+	openCommand := "xdg-open"
+	if runtime.GOOS == "darwin" {
+		openCommand = "open"
+	}
+
 	return Config{
-		SidebarWidth: 1,
-		MainWidth:    11,
-		ThreadsWidth: 1,
-		Notify:       "",
-		Emoji:        false,
+		Version:           ConfigVersion,
+		SidebarWidth:      1,
+		MainWidth:         11,
+		ThreadsWidth:      4,
+		ChannelRefresh:    5,
+		BroadcastWarn:     50,
+		AutoReplyCooldown: 60,
+		OpenCommand:       openCommand,
+		HistoryDays:       1,
+		Notify:            "",
+		StartupChannel:    StartupFirst,
+		Emoji:             false,
 		KeyMap: map[string]keyMapping{
 			"command": {
 				"i":          "mode-insert",
@@ -112,31 +347,215 @@ func getDefaultConfig() Config {
 				"k":          "channel-up",
 				"j":          "channel-down",
 				"g":          "channel-top",
-				"G":          "channel-bottom",
+				"G":          "bottom",
+				"<end>":      "chat-bottom",
 				"<enter>":    "channel-select",
 				"K":          "thread-up",
 				"J":          "thread-down",
-				"<previous>": "chat-up",
-				"C-b":        "chat-up",
-				"C-u":        "chat-up",
-				"<next>":     "chat-down",
-				"C-f":        "chat-down",
-				"C-d":        "chat-down",
-				"n":          "channel-search-next",
-				"N":          "channel-search-prev",
-				"'":          "channel-jump",
+				"C-y":        "thread-scroll-up",
+				"C-e":        "thread-scroll-down",
+				"<previous>": "scroll-up",
+				"C-b":        "scroll-up",
+				"C-u":        "scroll-up",
+				"<next>":     "scroll-down",
+				"C-f":        "scroll-down",
+				"C-d":        "scroll-down",
+				"<tab>":      "focus-next",
+				"M-<tab>":    "focus-prev",
+				"C-/":        "mode-find",
+				"n":          "search-next",
+				"N":          "search-prev",
+				"M-1":        "slot-1",
+				"M-2":        "slot-2",
+				"M-3":        "slot-3",
+				"M-4":        "slot-4",
+				"M-5":        "slot-5",
+				"M-6":        "slot-6",
+				"M-7":        "slot-7",
+				"M-8":        "slot-8",
+				"M-9":        "slot-9",
+				"s":          "slot-set",
+				"e":          "emoji-toggle",
+				"E":          "emoji-channel",
+				"<":          "channel-move-up",
+				">":          "channel-move-down",
+				"M-j":        "channel-unread-next",
+				"M-k":        "channel-unread-prev",
+				"'":          "mark-jump",
+				"m":          "mark-set",
 				"q":          "quit",
 				"<f1>":       "help",
+				"b":          "mode-browse",
+				"F":          "mode-files",
+				"M":          "mode-mentions",
+				"u":          "mode-followups",
+				"P":          "mode-members",
+				"C-k":        "mode-switcher",
+				"I":          "mode-info",
+				"T":          "mode-tasks",
+				":":          "mode-command-line",
+				"x":          "channel-mute",
+				"*":          "channel-star",
+				"o":          "attachments-toggle",
+				"c":          "message-edit",
+				"v":          "mode-select",
+				"f":          "gap-fetch",
+				"1":          "sidebar-channels",
+				"2":          "sidebar-groups",
+				"3":          "sidebar-mpims",
+				"4":          "sidebar-ims",
 			},
 			"insert": {
 				"<left>":      "cursor-left",
 				"<right>":     "cursor-right",
 				"<enter>":     "send",
+				"M-<enter>":   "send-later",
 				"<escape>":    "mode-command",
 				"<backspace>": "backspace",
 				"C-8":         "backspace",
 				"<delete>":    "delete",
 				"<space>":     "space",
+				"<tab>":       "complete",
+				"C-p":         "preview-toggle",
+				"C-k":         "mode-switcher",
+			},
+			"browse": {
+				"k":        "browse-up",
+				"j":        "browse-down",
+				"g":        "browse-top",
+				"G":        "browse-bottom",
+				"<enter>":  "browse-peek",
+				"a":        "browse-join",
+				"/":        "mode-browse-search",
+				"<escape>": "browse-close",
+				"q":        "browse-close",
+			},
+			"files": {
+				"k":        "files-up",
+				"j":        "files-down",
+				"g":        "files-top",
+				"G":        "files-bottom",
+				"<enter>":  "files-preview",
+				"d":        "files-download",
+				"o":        "files-open",
+				"x":        "files-delete",
+				"<escape>": "files-close",
+				"q":        "files-close",
+			},
+			"mentions": {
+				"k":        "mentions-up",
+				"j":        "mentions-down",
+				"g":        "mentions-top",
+				"G":        "mentions-bottom",
+				"<enter>":  "mentions-jump",
+				"<escape>": "mentions-close",
+				"q":        "mentions-close",
+			},
+			"followups": {
+				"k":        "followups-up",
+				"j":        "followups-down",
+				"g":        "followups-top",
+				"G":        "followups-bottom",
+				"<enter>":  "followups-jump",
+				"x":        "followups-done",
+				"<escape>": "followups-close",
+				"q":        "followups-close",
+			},
+			"members": {
+				"k":        "members-up",
+				"j":        "members-down",
+				"g":        "members-top",
+				"G":        "members-bottom",
+				"<enter>":  "members-dm",
+				"<escape>": "members-close",
+				"q":        "members-close",
+			},
+			"select": {
+				"k":        "select-up",
+				"j":        "select-down",
+				"g":        "select-top",
+				"G":        "select-bottom",
+				"x":        "select-delete",
+				"<enter>":  "select-copy",
+				"f":        "select-flag",
+				"r":        "mode-reaction",
+				"<escape>": "select-close",
+				"q":        "select-close",
+			},
+			"reaction": {
+				"<up>":        "reaction-up",
+				"<down>":      "reaction-down",
+				"C-p":         "reaction-up",
+				"C-n":         "reaction-down",
+				"<enter>":     "reaction-toggle",
+				"<escape>":    "reaction-close",
+				"<backspace>": "reaction-backspace",
+				"C-8":         "reaction-backspace",
+				"<left>":      "cursor-left",
+				"<right>":     "cursor-right",
+			},
+			"switcher": {
+				"<up>":        "switcher-up",
+				"<down>":      "switcher-down",
+				"C-p":         "switcher-up",
+				"C-n":         "switcher-down",
+				"<enter>":     "switcher-jump",
+				"<escape>":    "switcher-close",
+				"C-k":         "switcher-close",
+				"<backspace>": "switcher-backspace",
+				"C-8":         "switcher-backspace",
+			},
+			"info": {
+				"k":        "info-up",
+				"j":        "info-down",
+				"<up>":     "info-up",
+				"<down>":   "info-down",
+				"<enter>":  "info-open",
+				"o":        "info-open",
+				"c":        "info-canvas",
+				"<escape>": "info-close",
+				"q":        "info-close",
+				"I":        "info-close",
+			},
+			"tasks": {
+				"k":        "tasks-up",
+				"j":        "tasks-down",
+				"<up>":     "tasks-up",
+				"<down>":   "tasks-down",
+				"x":        "tasks-cancel",
+				"<escape>": "tasks-close",
+				"q":        "tasks-close",
+				"T":        "tasks-close",
+			},
+			"browse-search": {
+				"<left>":      "cursor-left",
+				"<right>":     "cursor-right",
+				"<escape>":    "browse-search-clear",
+				"<enter>":     "browse-search-done",
+				"<backspace>": "backspace",
+				"C-8":         "backspace",
+				"<delete>":    "delete",
+				"<space>":     "space",
+			},
+			"command-line": {
+				"<left>":      "cursor-left",
+				"<right>":     "cursor-right",
+				"<escape>":    "clear-input",
+				"<enter>":     "command-run",
+				"<backspace>": "backspace",
+				"C-8":         "backspace",
+				"<delete>":    "delete",
+				"<space>":     "space",
+			},
+			"find": {
+				"<left>":      "cursor-left",
+				"<right>":     "cursor-right",
+				"<escape>":    "find-clear",
+				"<enter>":     "find-done",
+				"<backspace>": "find-backspace",
+				"C-8":         "find-backspace",
+				"<delete>":    "find-delete",
+				"<space>":     "find-space",
 			},
 			"search": {
 				"<left>":      "cursor-left",
@@ -157,18 +576,27 @@ func getDefaultConfig() Config {
 				BorderBg: "",
 				LabelFg:  "green,bold",
 				LabelBg:  "",
+				FocusFg:  "yellow",
 			},
 			Channel: Channel{
-				Prefix: "",
-				Icon:   "",
-				Text:   "",
+				Prefix:      "",
+				Icon:        "",
+				Text:        "",
+				Unread:      "",
+				UnreadIcon:  "*",
+				Mention:     "fg-red,fg-bold",
+				MentionIcon: "@",
+				Header:      "fg-bold",
+				Muted:       "fg-black,fg-bold",
 			},
 			Message: Message{
 				Time:       "",
 				TimeFormat: "15:04",
+				DateFormat: "Monday, January 2",
 				Thread:     "fg-bold",
 				Name:       "",
 				Text:       "",
+				Mention:    "fg-bold",
 			},
 		},
 	}
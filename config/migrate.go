@@ -0,0 +1,149 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ConfigVersion is the version of the config file format, config files with
+// an older version are migrated when they're loaded
+const ConfigVersion = 1
+
+// migrations upgrade a config file from the version of their index to the
+// next version. They return warnings about the options that have been
+// dropped.
+var migrations = []func(map[string]interface{}) []string{
+	migrateUnversioned,
+}
+
+// migrateConfig will upgrade the decoded config file to the current
+// ConfigVersion
+func migrateConfig(raw map[string]interface{}) ([]string, error) {
+	version := configVersion(raw)
+	if version > ConfigVersion {
+		return nil, fmt.Errorf(
+			"the slack-term config file has version %d, this version of slack-term supports up to version %d",
+			version, ConfigVersion,
+		)
+	}
+
+	var warnings []string
+	for ; version < ConfigVersion; version++ {
+		warnings = append(warnings, migrations[version](raw)...)
+	}
+	raw["version"] = ConfigVersion
+
+	return warnings, nil
+}
+
+// configVersion returns the version of the decoded config file, it's 0 for
+// config files from before the config was versioned
+func configVersion(raw map[string]interface{}) int {
+	if v, ok := raw["version"].(float64); ok {
+		return int(v)
+	}
+	return 0
+}
+
+// writeMigratedConfig will replace the config file with the migrated
+// config, so it's only migrated once. The original config file is kept
+// next to it, with the .bak extension. It holds the tokens, so only the
+// user is able to read it.
+func writeMigratedConfig(filepath string, original []byte, raw map[string]interface{}) error {
+	info, err := os.Stat(filepath)
+	if err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath+".bak", original, 0600); err != nil {
+		return err
+	}
+
+	// The permissions of an existing file aren't changed by WriteFile
+	if err := os.Chmod(filepath+".bak", 0600); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(raw); err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath, buf.Bytes(), info.Mode().Perm())
+}
+
+// migrateUnversioned will drop the options of config files from before the
+// config was versioned that aren't supported anymore, including the key
+// mappings of modes that don't exist.
+func migrateUnversioned(raw map[string]interface{}) []string {
+	var warnings []string
+
+	options := jsonOptions(reflect.TypeOf(Config{}))
+	for _, option := range sortedKeys(raw) {
+		if !options[option] {
+			delete(raw, option)
+			warnings = append(
+				warnings,
+				fmt.Sprintf("the option '%s' isn't supported and has been ignored", option),
+			)
+		}
+	}
+
+	if keyMap, ok := raw["key_map"].(map[string]interface{}); ok {
+		modes := getDefaultConfig().KeyMap
+		for _, mode := range sortedKeys(keyMap) {
+			if _, ok := modes[mode]; !ok {
+				delete(keyMap, mode)
+				warnings = append(
+					warnings,
+					fmt.Sprintf("the key mappings of mode '%s' aren't supported and have been ignored", mode),
+				)
+			}
+		}
+	}
+
+	if theme, ok := raw["theme"].(map[string]interface{}); ok {
+		sections := jsonOptions(reflect.TypeOf(Theme{}))
+		for _, section := range sortedKeys(theme) {
+			if !sections[section] {
+				delete(theme, section)
+				warnings = append(
+					warnings,
+					fmt.Sprintf("the theme option '%s' isn't supported and has been ignored", section),
+				)
+			}
+		}
+	}
+
+	return warnings
+}
+
+// jsonOptions returns the json names of the fields of a struct
+func jsonOptions(t reflect.Type) map[string]bool {
+	options := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			options[name] = true
+		}
+	}
+	return options
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
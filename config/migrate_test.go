@@ -0,0 +1,144 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	fp "path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected string
+		warnings []string
+	}{
+		{
+			name:     "unversioned without unsupported options",
+			raw:      `{"slack_token": "xoxp-token", "emoji": true}`,
+			expected: `{"version": 1, "slack_token": "xoxp-token", "emoji": true}`,
+		},
+		{
+			name:     "unversioned with an unsupported option",
+			raw:      `{"slack_token": "xoxp-token", "show_avatars": true}`,
+			expected: `{"version": 1, "slack_token": "xoxp-token"}`,
+			warnings: []string{
+				"the option 'show_avatars' isn't supported and has been ignored",
+			},
+		},
+		{
+			name: "unversioned with the key mappings of an unsupported mode",
+			raw: `{"key_map": {
+				"command": {"q": "quit"},
+				"visual": {"v": "mode-command"}
+			}}`,
+			expected: `{"version": 1, "key_map": {"command": {"q": "quit"}}}`,
+			warnings: []string{
+				"the key mappings of mode 'visual' aren't supported and have been ignored",
+			},
+		},
+		{
+			name:     "unversioned with an unsupported theme option",
+			raw:      `{"theme": {"view": {"fg": "white"}, "sidebar": {"fg": "blue"}}}`,
+			expected: `{"version": 1, "theme": {"view": {"fg": "white"}}}`,
+			warnings: []string{
+				"the theme option 'sidebar' isn't supported and has been ignored",
+			},
+		},
+		{
+			name:     "current version",
+			raw:      `{"version": 1, "slack_token": "xoxp-token"}`,
+			expected: `{"version": 1, "slack_token": "xoxp-token"}`,
+		},
+	}
+
+	for _, test := range tests {
+		var raw, expected map[string]interface{}
+		if err := json.Unmarshal([]byte(test.raw), &raw); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if err := json.Unmarshal([]byte(test.expected), &expected); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		warnings, err := migrateConfig(raw)
+		if err != nil {
+			t.Errorf("%s: migrateConfig: %v", test.name, err)
+			continue
+		}
+
+		// The version is set as an int, the expected version is decoded
+		// as a float64
+		raw["version"] = float64(raw["version"].(int))
+		if !reflect.DeepEqual(raw, expected) {
+			t.Errorf("%s: migrated to %v, expected %v", test.name, raw, expected)
+		}
+		if !reflect.DeepEqual(warnings, test.warnings) {
+			t.Errorf("%s: warnings %q, expected %q", test.name, warnings, test.warnings)
+		}
+	}
+}
+
+func TestMigrateConfigNewerVersion(t *testing.T) {
+	raw := map[string]interface{}{"version": float64(ConfigVersion + 1)}
+	if _, err := migrateConfig(raw); err == nil {
+		t.Error("migrateConfig of a newer version succeeded, expected an error")
+	}
+}
+
+func TestNewConfigWritesMigratedConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slack-term")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	original := []byte(`{"slack_token": "xoxp-token", "show_avatars": true}`)
+	filepath := fp.Join(dir, "config")
+	if err := ioutil.WriteFile(filepath, original, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// A backup of an earlier migration is replaced
+	if err := ioutil.WriteFile(filepath+".bak", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := NewConfig(filepath)
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	if len(cfg.Warnings) != 2 {
+		t.Errorf("NewConfig warnings %q, expected the dropped option and the migration", cfg.Warnings)
+	}
+
+	backup, err := ioutil.ReadFile(filepath + ".bak")
+	if err != nil {
+		t.Fatalf("the original config file isn't kept: %v", err)
+	}
+	if string(backup) != string(original) {
+		t.Errorf("backup %s, expected %s", backup, original)
+	}
+	info, err := os.Stat(filepath + ".bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("backup has permissions %v, expected 0600", perm)
+	}
+
+	// The migrated config file is loaded without warnings
+	cfg, err = NewConfig(filepath)
+	if err != nil {
+		t.Fatalf("NewConfig of the migrated config file: %v", err)
+	}
+	if len(cfg.Warnings) != 0 {
+		t.Errorf("NewConfig of the migrated config file warnings %q, expected none", cfg.Warnings)
+	}
+	if cfg.Version != ConfigVersion || cfg.SlackToken != "xoxp-token" {
+		t.Errorf("migrated config has version %d and token %q", cfg.Version, cfg.SlackToken)
+	}
+}
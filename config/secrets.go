@@ -0,0 +1,392 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// ErrWrongPassphrase is returned when the encrypted secrets can't be
+// decrypted with the passphrase
+var ErrWrongPassphrase = errors.New("the passphrase of the encrypted secrets is wrong")
+
+// secretsPrefix is the prefix of encrypted_secrets, it's followed by the
+// base64 encoded salt, nonce and ciphertext
+const secretsPrefix = "slack-term:v1:"
+
+// sealedPrefix is the prefix of a secret that is sealed with the key of the
+// encrypted_secrets, it's followed by the base64 encoded nonce and
+// ciphertext
+const sealedPrefix = "slack-term:sealed:v1:"
+
+// The key of the encrypted secrets is derived from the passphrase with
+// PBKDF2-HMAC-SHA256
+const (
+	secretsSaltSize   = 16
+	secretsIterations = 600000
+)
+
+// Secrets are the options of the config file that can be stored encrypted,
+// with encrypted_secrets or secrets_command
+type Secrets struct {
+	SlackToken        string `json:"slack_token,omitempty"`
+	SlackCookie       string `json:"slack_cookie,omitempty"`
+	SlackRefreshToken string `json:"slack_refresh_token,omitempty"`
+	SlackClientSecret string `json:"slack_client_secret,omitempty"`
+}
+
+// LoadSecrets will set the slack token, cookie, refresh token and client
+// secret from secrets_command or encrypted_secrets, when they aren't set in
+// the config file itself. The passphrase of encrypted_secrets is the output
+// of the askpass command, or when that isn't set it's asked for with
+// passphrase.
+func (c *Config) LoadSecrets(passphrase func() (string, error)) error {
+	var secrets Secrets
+	switch {
+	case c.SecretsCommand != "":
+		output, err := runCommand(c.SecretsCommand)
+		if err != nil {
+			return fmt.Errorf("couldn't run the secrets_command: (%v)", err)
+		}
+
+		if err := json.Unmarshal(output, &secrets); err != nil {
+			return fmt.Errorf("the output of the secrets_command isn't valid: (%v)", err)
+		}
+	case c.EncryptedSecrets != "":
+		var pass string
+		var err error
+		if c.Askpass != "" {
+			var output []byte
+			output, err = runCommand(c.Askpass)
+			pass = strings.TrimRight(string(output), "\r\n")
+		} else {
+			pass, err = passphrase()
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't get the passphrase: (%v)", err)
+		}
+
+		secrets, c.secretsKey, err = decryptSecrets(c.EncryptedSecrets, pass)
+		if err != nil {
+			return err
+		}
+	default:
+		return nil
+	}
+
+	setSecret(&c.SlackToken, secrets.SlackToken)
+	setSecret(&c.SlackCookie, secrets.SlackCookie)
+	setSecret(&c.SlackRefreshToken, secrets.SlackRefreshToken)
+	setSecret(&c.SlackClientSecret, secrets.SlackClientSecret)
+
+	return nil
+}
+
+func setSecret(option *string, secret string) {
+	if *option == "" {
+		*option = secret
+	}
+}
+
+// runCommand will run a command with the shell, and return its output
+func runCommand(command string) ([]byte, error) {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+	return cmd.Output()
+}
+
+// EncryptSecrets will encrypt secrets with a passphrase, the result is the
+// value of encrypted_secrets
+func EncryptSecrets(secrets Secrets, passphrase string) (string, error) {
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return "", err
+	}
+
+	salt := make([]byte, secretsSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", err
+	}
+
+	key := pbkdf2SHA256([]byte(passphrase), salt, secretsIterations, 32)
+	gcm, err := newSecretsCipher(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	data := append(append(salt, nonce...), gcm.Seal(nil, nonce, plaintext, nil)...)
+	return secretsPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// DecryptSecrets will decrypt the value of encrypted_secrets with a
+// passphrase
+func DecryptSecrets(encrypted string, passphrase string) (Secrets, error) {
+	secrets, _, err := decryptSecrets(encrypted, passphrase)
+	return secrets, err
+}
+
+// decryptSecrets will decrypt the value of encrypted_secrets with a
+// passphrase, it returns the key that is derived from the passphrase as well
+func decryptSecrets(encrypted string, passphrase string) (Secrets, []byte, error) {
+	var secrets Secrets
+
+	if !strings.HasPrefix(encrypted, secretsPrefix) {
+		return secrets, nil, errors.New("the 'encrypted_secrets' aren't created by slack-term encrypt-config")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encrypted, secretsPrefix))
+	if err != nil || len(data) < secretsSaltSize {
+		return secrets, nil, errors.New("the 'encrypted_secrets' are corrupted")
+	}
+
+	salt, data := data[:secretsSaltSize], data[secretsSaltSize:]
+	key := pbkdf2SHA256([]byte(passphrase), salt, secretsIterations, 32)
+	gcm, err := newSecretsCipher(key)
+	if err != nil {
+		return secrets, nil, err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return secrets, nil, errors.New("the 'encrypted_secrets' are corrupted")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return secrets, nil, ErrWrongPassphrase
+	}
+
+	err = json.Unmarshal(plaintext, &secrets)
+	return secrets, key, err
+}
+
+func newSecretsCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// HasSecrets returns whether the secrets are kept out of the config file,
+// with encrypted_secrets or secrets_command. The tokens that are persisted
+// have to be sealed then, see SealSecret.
+func (c *Config) HasSecrets() bool {
+	return c.EncryptedSecrets != "" || c.SecretsCommand != ""
+}
+
+// CanSealSecrets returns whether secrets can be sealed, which is when they
+// have been decrypted from encrypted_secrets
+func (c *Config) CanSealSecrets() bool {
+	return c.secretsKey != nil
+}
+
+// SealSecret will encrypt a secret with the key of the encrypted_secrets,
+// e.g. a rotated token that is persisted
+func (c *Config) SealSecret(secret string) (string, error) {
+	if c.secretsKey == nil {
+		return "", errors.New("there are no 'encrypted_secrets' to seal the secret with")
+	}
+
+	gcm, err := newSecretsCipher(c.secretsKey)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	data := append(nonce, gcm.Seal(nil, nonce, []byte(secret), nil)...)
+	return sealedPrefix + base64.StdEncoding.EncodeToString(data), nil
+}
+
+// OpenSecret will decrypt a secret that has been sealed with SealSecret
+func (c *Config) OpenSecret(sealed string) (string, error) {
+	if c.secretsKey == nil {
+		return "", errors.New("there are no 'encrypted_secrets' to open the secret with")
+	}
+	if !strings.HasPrefix(sealed, sealedPrefix) {
+		return "", errors.New("the secret isn't sealed")
+	}
+
+	gcm, err := newSecretsCipher(c.secretsKey)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(sealed, sealedPrefix))
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", errors.New("the sealed secret is corrupted")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", errors.New("the secret is sealed with other 'encrypted_secrets'")
+	}
+
+	return string(plaintext), nil
+}
+
+// pbkdf2SHA256 will derive a key from a password as described in RFC 8018
+func pbkdf2SHA256(password []byte, salt []byte, iterations int, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+
+	var key []byte
+	for block := uint32(1); len(key) < keyLen; block++ {
+		var index [4]byte
+		binary.BigEndian.PutUint32(index[:], block)
+
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(index[:])
+		u := prf.Sum(nil)
+
+		t := make([]byte, len(u))
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		key = append(key, t...)
+	}
+
+	return key[:keyLen]
+}
+
+// EncryptConfigFile will encrypt the slack token, cookie, refresh token and
+// client secret of the config file with a passphrase. They're removed from
+// the config file, and stored encrypted in encrypted_secrets. It returns
+// the number of secrets that have been encrypted. The secrets are removed
+// from the backup of a migrated config file as well, see scrubConfigBackup.
+func EncryptConfigFile(filepath string, passphrase string) (int, error) {
+	data, err := ioutil.ReadFile(filepath)
+	if err != nil {
+		return 0, err
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return 0, fmt.Errorf("the slack-term config file isn't valid json: (%v)", err)
+	}
+
+	if _, ok := raw["encrypted_secrets"]; ok {
+		return 0, errors.New("the slack-term config file already has 'encrypted_secrets'")
+	}
+
+	// Only the secrets that are set in the config file are moved
+	var secrets Secrets
+	var count int
+	options := map[string]*string{
+		"slack_token":         &secrets.SlackToken,
+		"slack_cookie":        &secrets.SlackCookie,
+		"slack_refresh_token": &secrets.SlackRefreshToken,
+		"slack_client_secret": &secrets.SlackClientSecret,
+	}
+	for option, secret := range options {
+		value, ok := raw[option].(string)
+		if !ok || value == "" {
+			continue
+		}
+
+		*secret = value
+		delete(raw, option)
+		count++
+	}
+
+	if count == 0 {
+		return 0, errors.New("the slack-term config file has no secrets to encrypt")
+	}
+
+	raw["encrypted_secrets"], err = EncryptSecrets(secrets, passphrase)
+	if err != nil {
+		return 0, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(raw); err != nil {
+		return 0, err
+	}
+
+	if err := ioutil.WriteFile(filepath, buf.Bytes(), 0600); err != nil {
+		return 0, err
+	}
+
+	// The permissions of an existing file aren't changed by WriteFile
+	if err := os.Chmod(filepath, 0600); err != nil {
+		return 0, err
+	}
+
+	backup := filepath + ".bak"
+	if err := scrubConfigBackup(backup, options); err != nil {
+		return count, fmt.Errorf("the secrets are still readable in %s, remove it: (%v)", backup, err)
+	}
+
+	return count, nil
+}
+
+// scrubConfigBackup will remove the secret options from the backup that is
+// kept when the config file is migrated, otherwise the secrets would still
+// be readable in it. Nothing is done when there is no backup.
+func scrubConfigBackup(filepath string, options map[string]*string) error {
+	data, err := ioutil.ReadFile(filepath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("the backup of the config file isn't valid json: (%v)", err)
+	}
+
+	for option := range options {
+		delete(raw, option)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "    ")
+	if err := encoder.Encode(raw); err != nil {
+		return err
+	}
+
+	if err := ioutil.WriteFile(filepath, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	return os.Chmod(filepath, 0600)
+}
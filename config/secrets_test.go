@@ -0,0 +1,176 @@
+package config
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	fp "path/filepath"
+	"strings"
+	"testing"
+)
+
+// The test vectors of PBKDF2-HMAC-SHA256 are published in RFC 7914,
+// section 11
+func TestPBKDF2SHA256(t *testing.T) {
+	tests := []struct {
+		password   string
+		salt       string
+		iterations int
+		keyLen     int
+		expected   string
+	}{
+		{
+			password:   "passwd",
+			salt:       "salt",
+			iterations: 1,
+			keyLen:     64,
+			expected: "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+				"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783",
+		},
+		{
+			password:   "Password",
+			salt:       "NaCl",
+			iterations: 80000,
+			keyLen:     64,
+			expected: "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56" +
+				"a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d",
+		},
+	}
+
+	for _, test := range tests {
+		key := pbkdf2SHA256(
+			[]byte(test.password), []byte(test.salt), test.iterations, test.keyLen,
+		)
+
+		if actual := hex.EncodeToString(key); actual != test.expected {
+			t.Errorf(
+				"pbkdf2SHA256(%q, %q, %d) = %s, expected %s",
+				test.password, test.salt, test.iterations, actual, test.expected,
+			)
+		}
+	}
+}
+
+func TestEncryptSecrets(t *testing.T) {
+	secrets := Secrets{
+		SlackToken:        "xoxp-token",
+		SlackCookie:       "xoxd-cookie",
+		SlackRefreshToken: "xoxe-refresh",
+		SlackClientSecret: "secret",
+	}
+
+	encrypted, err := EncryptSecrets(secrets, "correct horse")
+	if err != nil {
+		t.Fatalf("EncryptSecrets: %v", err)
+	}
+
+	decrypted, err := DecryptSecrets(encrypted, "correct horse")
+	if err != nil {
+		t.Fatalf("DecryptSecrets: %v", err)
+	}
+	if decrypted != secrets {
+		t.Errorf("DecryptSecrets = %+v, expected %+v", decrypted, secrets)
+	}
+
+	if _, err := DecryptSecrets(encrypted, "battery staple"); err != ErrWrongPassphrase {
+		t.Errorf("DecryptSecrets with the wrong passphrase = %v, expected %v", err, ErrWrongPassphrase)
+	}
+}
+
+func TestDecryptSecretsCorrupted(t *testing.T) {
+	tests := []string{
+		"",
+		"slack-term:v1:",
+		"slack-term:v1:not base64",
+		"slack-term:v1:c2FsdA==",
+	}
+
+	for _, encrypted := range tests {
+		if _, err := DecryptSecrets(encrypted, "correct horse"); err == nil {
+			t.Errorf("DecryptSecrets(%q) succeeded, expected an error", encrypted)
+		}
+	}
+}
+
+func TestSealSecret(t *testing.T) {
+	encrypted, err := EncryptSecrets(Secrets{SlackToken: "xoxp-token"}, "correct horse")
+	if err != nil {
+		t.Fatalf("EncryptSecrets: %v", err)
+	}
+
+	cfg := &Config{EncryptedSecrets: encrypted}
+	if cfg.CanSealSecrets() {
+		t.Fatal("CanSealSecrets before the secrets are loaded, expected false")
+	}
+
+	err = cfg.LoadSecrets(func() (string, error) { return "correct horse", nil })
+	if err != nil {
+		t.Fatalf("LoadSecrets: %v", err)
+	}
+	if !cfg.CanSealSecrets() {
+		t.Fatal("CanSealSecrets after the secrets are loaded, expected true")
+	}
+
+	sealed, err := cfg.SealSecret("xoxe-refresh")
+	if err != nil {
+		t.Fatalf("SealSecret: %v", err)
+	}
+	if sealed == "xoxe-refresh" {
+		t.Fatal("SealSecret returned the secret itself")
+	}
+
+	opened, err := cfg.OpenSecret(sealed)
+	if err != nil {
+		t.Fatalf("OpenSecret: %v", err)
+	}
+	if opened != "xoxe-refresh" {
+		t.Errorf("OpenSecret = %q, expected %q", opened, "xoxe-refresh")
+	}
+
+	// A token that was persisted before the secrets were encrypted isn't
+	// used
+	if _, err := cfg.OpenSecret("xoxe-refresh"); err == nil {
+		t.Error("OpenSecret of a secret that isn't sealed succeeded, expected an error")
+	}
+}
+
+func TestEncryptConfigFileScrubsBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slack-term")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filepath := fp.Join(dir, "config")
+	config := []byte(`{"version": 1, "slack_token": "xoxp-token"}`)
+	if err := ioutil.WriteFile(filepath, config, 0600); err != nil {
+		t.Fatal(err)
+	}
+	backup := []byte(`{"slack_token": "xoxp-token", "slack_cookie": "xoxd-cookie", "emoji": true}`)
+	if err := ioutil.WriteFile(filepath+".bak", backup, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := EncryptConfigFile(filepath, "correct horse"); err != nil {
+		t.Fatalf("EncryptConfigFile: %v", err)
+	}
+
+	scrubbed, err := ioutil.ReadFile(filepath + ".bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(scrubbed), "xoxp-token") || strings.Contains(string(scrubbed), "xoxd-cookie") {
+		t.Errorf("backup %s still has the secrets", scrubbed)
+	}
+	if !strings.Contains(string(scrubbed), "emoji") {
+		t.Errorf("backup %s, expected the other options to be kept", scrubbed)
+	}
+
+	info, err := os.Stat(filepath + ".bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("backup has permissions %v, expected 0600", perm)
+	}
+}
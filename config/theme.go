@@ -4,6 +4,10 @@ type Theme struct {
 	View    View    `json:"view"`
 	Channel Channel `json:"channel"`
 	Message Message `json:"message"`
+
+	// Channels override the style of single channels, by their name or
+	// id, e.g. "#alerts"
+	Channels map[string]ChannelStyle `json:"channels"`
 }
 
 type View struct {
@@ -13,6 +17,7 @@ type View struct {
 	BorderBg string `json:"border_bg"` // Border background
 	LabelFg  string `json:"label_fg"`  // Label text foreground
 	LabelBg  string `json:"label_bg"`  // Label text background
+	FocusFg  string `json:"focus_fg"`  // Border foreground of the focused pane
 }
 
 type Message struct {
@@ -20,11 +25,25 @@ type Message struct {
 	Name       string `json:"name"`
 	Thread     string `json:"thread"`
 	Text       string `json:"text"`
-	TimeFormat string `json:"time_format"`
+	Mention    string `json:"mention"`
+	TimeFormat string `json:"time_format"` // Layout, "12h" or "24h"
+	DateFormat string `json:"date_format"` // Layout of the date separators
+}
+
+type ChannelStyle struct {
+	Text   string `json:"text"`   // Name of the channel in the sidebar
+	Header string `json:"header"` // Header of the chat pane
+	Border string `json:"border"` // Border foreground of the chat pane
 }
 
 type Channel struct {
-	Prefix string `json:"prefix"`
-	Icon   string `json:"icon"`
-	Text   string `json:"text"`
+	Prefix      string `json:"prefix"`
+	Icon        string `json:"icon"`
+	Text        string `json:"text"`
+	Unread      string `json:"unread"`       // Badge of channels with unread messages
+	UnreadIcon  string `json:"unread_icon"`  // Icon of the unread badge
+	Mention     string `json:"mention"`      // Badge of channels that mention the user
+	MentionIcon string `json:"mention_icon"` // Icon of the mention badge
+	Header      string `json:"header"`       // Header of the chat pane
+	Muted       string `json:"muted"`        // Channels that are muted
 }
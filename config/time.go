@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// TimeFormats are the names of the formats that can be used as the
+// time_format of messages, instead of a layout of the time package
+var TimeFormats = map[string]string{
+	"24h": "15:04",
+	"12h": "3:04 PM",
+}
+
+// localeNames are the names of the days, starting with sunday, and the
+// names of the months of the supported languages
+var localeNames = map[string]struct {
+	days   [7]string
+	months [12]string
+}{
+	"de": {
+		[7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		[12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+	},
+	"es": {
+		[7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		[12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+	},
+	"fr": {
+		[7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		[12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+	},
+	"it": {
+		[7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+		[12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+	},
+	"nl": {
+		[7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+		[12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+	},
+	"pt": {
+		[7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+		[12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+	},
+}
+
+// FormatDate will format t with layout, using the names of the days and
+// months of the language of the locale, which is set by LC_ALL, LC_TIME or
+// LANG, e.g. "nl_NL.UTF-8"
+func FormatDate(t time.Time, layout string) string {
+	date := t.Format(layout)
+
+	names, ok := localeNames[localeLanguage()]
+	if !ok {
+		return date
+	}
+
+	day := t.Weekday().String()
+	month := t.Month().String()
+	localDay := names.days[t.Weekday()]
+	localMonth := names.months[t.Month()-1]
+
+	// Full names are replaced first, so the abbreviations of the layout
+	// are only replaced when the full names aren't used
+	replacements := []string{day, localDay, month, localMonth}
+	if !strings.Contains(date, day) {
+		replacements = append(replacements, day[:3], abbreviate(localDay))
+	}
+	if !strings.Contains(date, month) {
+		replacements = append(replacements, month[:3], abbreviate(localMonth))
+	}
+
+	return strings.NewReplacer(replacements...).Replace(date)
+}
+
+// localeLanguage returns the language of the locale, e.g. "nl"
+func localeLanguage() string {
+	for _, env := range []string{"LC_ALL", "LC_TIME", "LANG"} {
+		if locale := os.Getenv(env); locale != "" {
+			return strings.ToLower(strings.SplitN(locale, "_", 2)[0])
+		}
+	}
+	return ""
+}
+
+func abbreviate(name string) string {
+	runes := []rune(name)
+	if len(runes) <= 3 {
+		return name
+	}
+	return string(runes[:3])
+}
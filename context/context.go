@@ -6,21 +6,42 @@ import (
 	_ "net/http/pprof"
 	"os"
 
-	"github.com/0xAX/notificator"
 	"github.com/erroneousboat/termui"
 	termbox "github.com/nsf/termbox-go"
 
 	"github.com/erroneousboat/slack-term/config"
+	"github.com/erroneousboat/slack-term/notify"
 	"github.com/erroneousboat/slack-term/service"
+	"github.com/erroneousboat/slack-term/tasks"
 	"github.com/erroneousboat/slack-term/views"
 )
 
 const (
-	CommandMode = "command"
-	InsertMode  = "insert"
-	SearchMode  = "search"
+	CommandMode   = "command"
+	InsertMode    = "insert"
+	SearchMode    = "search"
+	BrowseMode    = "browse"
+	FilesMode     = "files"
+	MentionsMode  = "mentions"
+	FollowUpsMode = "followups"
+	MembersMode   = "members"
+	SelectMode    = "select"
+	ReactionMode  = "reaction"
+	SwitcherMode  = "switcher"
+	InfoMode      = "info"
+	TasksMode     = "tasks"
+	FindMode      = "find"
 
-	ChatFocus = iota
+	BrowseSearchMode = "browse-search"
+	CommandLineMode  = "command-line"
+)
+
+// Focus is the pane that the scroll keys act on, messages are sent as
+// replies when the Threads pane is focused. The Input is focused when in
+// insert mode.
+const (
+	ChannelsFocus = iota
+	ChatFocus
 	ThreadFocus
 )
 
@@ -28,79 +49,161 @@ type AppContext struct {
 	Version    string
 	Usage      string
 	EventQueue chan termbox.Event
-	Service    *service.SlackService
+	Service    service.ChatService
 	Body       *termui.Grid
 	View       *views.View
 	Config     *config.Config
 	Debug      bool
 	Mode       string
 	Focus      int
-	Notify     *notificator.Notificator
+	Notify     notify.Notifier
+	Tasks      *tasks.Manager
+
+	// Conversations keeps the state of the conversations that aren't
+	// shown, e.g. where they were scrolled to
+	Conversations *Conversations
+
+	// ActionQueue holds the actions that have to run on the goroutine
+	// that handles the keys, e.g. showing the history of a channel once
+	// it has been fetched in the background
+	ActionQueue chan func()
+
+	// PendingAction is the name of an action that is waiting for the
+	// next key press as its argument, e.g. setting a mark
+	PendingAction string
 }
 
-// CreateAppContext creates an application context which can be passed
-// and referenced througout the application
-func CreateAppContext(flgConfig string, flgToken string, flgCookie string, flgApiUrl string, flgDebug bool, version string, usage string) (*AppContext, error) {
-	if flgDebug {
-		go func() {
-			http.ListenAndServe(":6060", nil)
-		}()
-	}
+// passphraseAttempts is the number of times the passphrase of the encrypted
+// secrets is asked for, when it's wrong
+const passphraseAttempts = 3
 
-	// Loading screen
-	views.Loading()
+// LoadConfig will load the config file, and decrypt its secrets with the
+// passphrase that is asked for with passphrase, retry is true when the
+// previous one was wrong. When the slack token, cookie and api url aren't
+// set in it they're taken from the command-line flags or the environment
+// variables.
+func LoadConfig(flgConfig string, flgToken string, flgCookie string, flgApiUrl string, passphrase func(retry bool) (string, error)) (*config.Config, error) {
+	cfg, err := config.NewConfig(flgConfig)
+	if err != nil {
+		return nil, err
+	}
 
-	// Load config
-	config, err := config.NewConfig(flgConfig)
+	for attempt := 0; ; attempt++ {
+		err = cfg.LoadSecrets(func() (string, error) {
+			return passphrase(attempt > 0)
+		})
+		if err != config.ErrWrongPassphrase || cfg.Askpass != "" || attempt == passphraseAttempts-1 {
+			break
+		}
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	// When slack token isn't set in the config file, we'll check
 	// the command-line flag or the environment variable
-	if config.SlackToken == "" {
+	if cfg.SlackToken == "" {
 		if flgToken != "" {
-			config.SlackToken = flgToken
+			cfg.SlackToken = flgToken
 		} else {
-			config.SlackToken = os.Getenv("SLACK_TOKEN")
+			cfg.SlackToken = os.Getenv("SLACK_TOKEN")
 		}
 	}
 
-	if config.SlackCookie == "" {
+	if cfg.SlackCookie == "" {
 		if flgCookie != "" {
-			config.SlackCookie = flgCookie
+			cfg.SlackCookie = flgCookie
 		} else {
-			config.SlackCookie = os.Getenv("SLACK_COOKIE")
+			cfg.SlackCookie = os.Getenv("SLACK_COOKIE")
 		}
 	}
 
-	if config.SlackApiUrl == "" {
+	if cfg.SlackApiUrl == "" {
 		if flgApiUrl != "" {
-			config.SlackApiUrl = flgApiUrl
+			cfg.SlackApiUrl = flgApiUrl
 		} else {
-			config.SlackApiUrl = os.Getenv("SLACK_API_URL")
+			cfg.SlackApiUrl = os.Getenv("SLACK_API_URL")
 		}
 	}
 
+	return cfg, nil
+}
+
+// CreateAppContext creates an application context which can be passed
+// and referenced througout the application
+func CreateAppContext(flgConfig string, flgToken string, flgCookie string, flgApiUrl string, flgDebug bool, version string, usage string) (*AppContext, error) {
+	if flgDebug {
+		go func() {
+			http.ListenAndServe(":6060", nil)
+		}()
+	}
+
+	// Loading screen
+	progress := views.Loading()
+
+	// Load config
+	progress.Start("Reading config")
+	config, err := LoadConfig(
+		flgConfig, flgToken, flgCookie, flgApiUrl,
+		func(retry bool) (string, error) {
+			var reason string
+			if retry {
+				reason = "The passphrase is wrong."
+			}
+
+			passphrase, ok := views.AskPassphrase(reason)
+			if !ok {
+				return "", errors.New("cancelled")
+			}
+
+			progress.Draw()
+			return passphrase, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create desktop notifier
-	var notify *notificator.Notificator
+	var notifier notify.Notifier
 	if config.Notify != "" {
-		notify = notificator.New(notificator.Options{AppName: "slack-term"})
-		if notify == nil {
-			return nil, errors.New(
-				"desktop notifications are not supported for your OS",
-			)
+		notifier, err = notify.New("slack-term")
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	// Create Service
-	svc, err := service.NewSlackService(config)
-	if err != nil {
-		return nil, err
+	// Create Service, when the client isn't able to authorize we'll ask
+	// for a new slack token and cookie
+	var svc *service.SlackService
+	progress.Start("Signing in")
+	for {
+		svc, err = service.NewSlackService(config)
+		if err == nil {
+			break
+		}
+
+		authErr, ok := err.(*service.AuthError)
+		if !ok {
+			return nil, err
+		}
+
+		token, cookie, ok := views.Reauthenticate(
+			authErr.Err.Error(), config.SlackToken, config.SlackCookie,
+		)
+		if !ok {
+			return nil, err
+		}
+		config.SlackToken = token
+		config.SlackCookie = cookie
+		config.TokenEntered = true
+
+		progress.Draw()
 	}
+	progress.Update(svc.CurrentUsername)
 
 	// Create the main view
-	view, err := views.CreateView(config, svc)
+	view, err := views.CreateView(config, svc, progress)
 	if err != nil {
 		return nil, err
 	}
@@ -109,10 +212,7 @@ func CreateAppContext(flgConfig string, flgToken string, flgCookie string, flgAp
 		termui.NewCol(config.SidebarWidth, 0, view.Channels),
 	}
 
-	threads := false
-	if len(view.Threads.ChannelItems) > 0 {
-		threads = true
-	}
+	threads := view.Threads.HasThreads()
 
 	// Setup the interface
 	if threads && flgDebug {
@@ -171,6 +271,10 @@ func CreateAppContext(flgConfig string, flgToken string, flgCookie string, flgAp
 		Debug:      flgDebug,
 		Mode:       CommandMode,
 		Focus:      ChatFocus,
-		Notify:     notify,
+		Notify:     notifier,
+		Tasks:      tasks.NewManager(),
+
+		Conversations: NewConversations(),
+		ActionQueue:   make(chan func(), 20),
 	}, nil
 }
@@ -0,0 +1,72 @@
+package context
+
+import (
+	"sync"
+
+	"github.com/erroneousboat/slack-term/components"
+)
+
+// Conversation is the state of a conversation that is kept while other
+// conversations are shown, so that it's shown as it was left when it's
+// selected again
+type Conversation struct {
+	// Messages are the messages that were shown in the Chat pane when the
+	// conversation was left, they're shown while the history is fetched
+	Messages []components.Message
+
+	// ScrollPosition is the id of the message that was shown at the
+	// bottom of the Chat pane when the conversation was left, it's empty
+	// when it was scrolled to the bottom
+	ScrollPosition string
+
+	// Draft is the message that was being composed in the conversation
+	Draft string
+
+	// HistoryWindows is the number of times the history that is fetched
+	// of the conversation has been extended with the more command
+	HistoryWindows int
+}
+
+// Conversations keeps the state of the conversations, keyed by channel id.
+// The unread state of the conversations is part of the ChannelItems, as
+// it's shown in the Channels component.
+type Conversations struct {
+	states map[string]Conversation
+	mu     sync.Mutex
+}
+
+// NewConversations is the constructor for the Conversations store
+func NewConversations() *Conversations {
+	return &Conversations{
+		states: make(map[string]Conversation),
+	}
+}
+
+// Get returns the state of the conversation with channelID, which is empty
+// when nothing has been kept of it yet
+func (c *Conversations) Get(channelID string) Conversation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.states[channelID]
+}
+
+// Update will change the state of the conversation with channelID with
+// update
+func (c *Conversations) Update(channelID string, update func(*Conversation)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	state := c.states[channelID]
+	update(&state)
+	c.states[channelID] = state
+}
+
+// Remove will forget the state of the conversation with channelID, e.g.
+// when the channel has been left
+func (c *Conversations) Remove(channelID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.states, channelID)
+}
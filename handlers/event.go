@@ -1,15 +1,24 @@
 package handlers
 
 import (
+	gocontext "context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode"
 
-	"github.com/0xAX/notificator"
 	"github.com/erroneousboat/termui"
 	termbox "github.com/nsf/termbox-go"
 	"github.com/slack-go/slack"
@@ -17,26 +26,149 @@ import (
 	"github.com/erroneousboat/slack-term/components"
 	"github.com/erroneousboat/slack-term/config"
 	"github.com/erroneousboat/slack-term/context"
+	"github.com/erroneousboat/slack-term/service"
 	"github.com/erroneousboat/slack-term/views"
 )
 
 var scrollTimer *time.Timer
 var notifyTimer *time.Timer
 
+// idleTimer sets the user as away when there hasn't been any input, and
+// away is whether that has happened
+var (
+	idleTimer *time.Timer
+	away      bool
+	awayMu    sync.Mutex
+)
+
+// completion is the state of completing the word before the cursor, a
+// repeated completion will cycle through the candidates of the Completion
+// popup
+var completion struct {
+	start int
+
+	// text is the text of the input after the last completion
+	text string
+}
+
+// autoReply is the state of the auto-responder, dnd is the do not disturb
+// status of the user and replied are the times the senders of direct
+// messages have been replied to, keyed by user id
+var autoReply struct {
+	dnd     slack.DNDStatus
+	replied map[string]time.Time
+	mu      sync.Mutex
+}
+
+// completionTimer delays updating the Completion popup until the typing is
+// paused
+var completionTimer *time.Timer
+
+// completionLimit is the maximum number of candidates in the Completion
+// popup
+const completionLimit = 10
+
+// members are the ids of the members of the selected channel, and presence
+// the last known presence of users
+var (
+	members struct {
+		channelID string
+		userIDs   []string
+	}
+	presence   = make(map[string]string)
+	presenceMu sync.Mutex
+)
+
+// posting is whether the current user is allowed to post in the selected
+// channel, insert mode isn't available when it's not
+var posting struct {
+	channelID string
+	policy    service.PostingPolicy
+	mu        sync.Mutex
+}
+
+// editing is the message that is being edited, the input is sent as its
+// new text instead of as a new message. It's cleared when insert mode is
+// left.
+var editing struct {
+	channelID string
+	messageID string
+}
+
+// showPreview is whether the preview of the message that is being composed
+// is shown in the Chat pane, see actionRenderPreview
+var showPreview bool
+
+// chatChannelID is the id of the channel that is shown in the Chat pane,
+// its state is kept in ctx.Conversations when it's left
+var chatChannelID string
+
+// commandMap binds the names of the commands that can be run from the
+// command line to their function counterparts, they receive the arguments
+// that follow the name
+var commandMap = map[string]func(*context.AppContext, []string) error{
+	"filter":        commandFilter,
+	"snooze":        commandSnooze,
+	"deactivated":   commandDeactivated,
+	"more":          commandMore,
+	"at":            commandAt,
+	"digest":        commandDigest,
+	"flag":          commandFlag,
+	"new-channel":   commandNewChannel,
+	"dm":            commandDM,
+	"leave":         commandLeave,
+	"archive":       commandArchive,
+	"mark-all-read": commandMarkAllAsRead,
+}
+
+// snoozeTimers end the snooze of channels, keyed by channel id
+var snoozeTimers = make(map[string]*time.Timer)
+
+// rtmConnected is whether the real time api has been connected to before
+var rtmConnected bool
+
+// channelCtx is the context of the requests that are made for the selected
+// channel, channelCancel cancels them, see newChannelContext
+var (
+	channelCtx    gocontext.Context = gocontext.Background()
+	channelCancel gocontext.CancelFunc
+)
+
+// browserCtx is the context of the requests that are made for the Browser,
+// browserCancel cancels them when the Browser is closed
+var (
+	browserCtx    gocontext.Context = gocontext.Background()
+	browserCancel gocontext.CancelFunc
+)
+
+// prefetchCount is the number of channels below the selected channel of
+// which the history is prefetched
+const prefetchCount = 3
+
+// unreadWorkers is the number of unread counts that are fetched concurrently
+const unreadWorkers = 4
+
 // actionMap binds specific action names to the function counterparts,
 // these action names can then be used to bind them to specific keys
 // in the Config.
 var actionMap = map[string]func(*context.AppContext){
 	"space":               actionSpace,
 	"backspace":           actionBackSpace,
+	"complete":            actionComplete,
+	"preview-toggle":      actionTogglePreview,
+	"emoji-toggle":        actionToggleEmoji,
+	"emoji-channel":       actionToggleEmojiChannel,
 	"delete":              actionDelete,
 	"cursor-right":        actionMoveCursorRight,
 	"cursor-left":         actionMoveCursorLeft,
 	"send":                actionSend,
+	"send-later":          actionSendLater,
 	"quit":                actionQuit,
 	"mode-insert":         actionInsertMode,
 	"mode-command":        actionCommandMode,
 	"mode-search":         actionSearchMode,
+	"mode-command-line":   actionCommandLineMode,
+	"command-run":         actionRunCommand,
 	"clear-input":         actionClearInput,
 	"channel-up":          actionMoveCursorUpChannels,
 	"channel-down":        actionMoveCursorDownChannels,
@@ -44,26 +176,207 @@ var actionMap = map[string]func(*context.AppContext){
 	"channel-bottom":      actionMoveCursorBottomChannels,
 	"channel-search-next": actionSearchNextChannels,
 	"channel-search-prev": actionSearchPrevChannels,
+	"search-next":         actionSearchNext,
+	"search-prev":         actionSearchPrev,
 	"channel-jump":        actionJumpChannels,
+	"channel-unread-next": actionJumpNextChannels,
+	"channel-unread-prev": actionJumpPreviousChannels,
 	"channel-select":      actionChangeChannel,
+	"channel-move-up":     actionMoveUpChannels,
+	"channel-move-down":   actionMoveDownChannels,
+	"channel-mute":        actionToggleMute,
+	"sidebar-channels":    actionToggleChannels,
+	"sidebar-groups":      actionToggleGroups,
+	"sidebar-mpims":       actionToggleMpIMs,
+	"sidebar-ims":         actionToggleIMs,
+	"channel-star":        actionToggleStar,
+	"attachments-toggle":  actionToggleAttachments,
+	"message-edit":        actionEditMessage,
 	"thread-up":           actionMoveCursorUpThreads,
 	"thread-down":         actionMoveCursorDownThreads,
+	"thread-scroll-up":    actionScrollUpThreads,
+	"thread-scroll-down":  actionScrollDownThreads,
 	"chat-up":             actionScrollUpChat,
 	"chat-down":           actionScrollDownChat,
+	"chat-bottom":         actionScrollBottomChat,
+	"bottom":              actionScrollBottom,
+	"scroll-up":           actionScrollUp,
+	"scroll-down":         actionScrollDown,
+	"focus-next":          actionFocusNext,
+	"focus-prev":          actionFocusPrev,
 	"help":                actionHelp,
+	"mark-set":            actionSetMark,
+	"mark-jump":           actionJumpMark,
+	"slot-set":            actionSetSlot,
+	"mode-browse":         actionBrowseMode,
+	"browse-up":           actionMoveCursorUpBrowser,
+	"browse-down":         actionMoveCursorDownBrowser,
+	"browse-top":          actionMoveCursorTopBrowser,
+	"browse-bottom":       actionMoveCursorBottomBrowser,
+	"browse-peek":         actionPeekBrowser,
+	"browse-join":         actionJoinBrowser,
+	"browse-close":        actionCloseBrowser,
+	"mode-browse-search":  actionBrowseSearchMode,
+	"browse-search-done":  actionBrowseSearchDone,
+	"browse-search-clear": actionBrowseSearchClear,
+	"mode-files":          actionFilesMode,
+	"files-up":            actionMoveCursorUpFiles,
+	"files-down":          actionMoveCursorDownFiles,
+	"files-top":           actionMoveCursorTopFiles,
+	"files-bottom":        actionMoveCursorBottomFiles,
+	"files-preview":       actionPreviewFile,
+	"files-download":      actionDownloadFile,
+	"files-open":          actionOpenFile,
+	"files-delete":        actionDeleteFile,
+	"files-close":         actionCloseFiles,
+	"mode-mentions":       actionMentionsMode,
+	"mentions-up":         actionMoveCursorUpMentions,
+	"mentions-down":       actionMoveCursorDownMentions,
+	"mentions-top":        actionMoveCursorTopMentions,
+	"mentions-bottom":     actionMoveCursorBottomMentions,
+	"mentions-jump":       actionJumpMention,
+	"mentions-close":      actionCloseMentions,
+	"mode-followups":      actionFollowUpsMode,
+	"followups-up":        actionMoveCursorUpFollowUps,
+	"followups-down":      actionMoveCursorDownFollowUps,
+	"followups-top":       actionMoveCursorTopFollowUps,
+	"followups-bottom":    actionMoveCursorBottomFollowUps,
+	"followups-jump":      actionJumpFollowUp,
+	"followups-done":      actionRemoveFollowUp,
+	"followups-close":     actionCloseMentions,
+	"mode-members":        actionMembersMode,
+	"members-up":          actionMoveCursorUpMembers,
+	"members-down":        actionMoveCursorDownMembers,
+	"members-top":         actionMoveCursorTopMembers,
+	"members-bottom":      actionMoveCursorBottomMembers,
+	"members-dm":          actionOpenMemberDM,
+	"members-close":       actionCloseMentions,
+	"mode-select":         actionSelectMode,
+	"select-up":           actionMoveSelectionUp,
+	"select-down":         actionMoveSelectionDown,
+	"select-top":          actionMoveSelectionTop,
+	"select-bottom":       actionMoveSelectionBottom,
+	"select-delete":       actionDeleteMessage,
+	"select-copy":         actionCopyTimestamp,
+	"select-flag":         actionFlagMessage,
+	"select-close":        actionCloseSelect,
+	"gap-fetch":           actionFetchGap,
+	"mode-reaction":       actionReactionMode,
+	"reaction-up":         actionMoveCursorUpReaction,
+	"reaction-down":       actionMoveCursorDownReaction,
+	"reaction-backspace":  actionBackSpaceReaction,
+	"reaction-toggle":     actionToggleReaction,
+	"reaction-close":      actionCloseReaction,
+	"mode-switcher":       actionSwitcherMode,
+	"switcher-up":         actionMoveCursorUpSwitcher,
+	"switcher-down":       actionMoveCursorDownSwitcher,
+	"switcher-backspace":  actionBackSpaceSwitcher,
+	"switcher-jump":       actionJumpSwitcher,
+	"switcher-close":      actionCloseSwitcher,
+	"mode-info":           actionInfoMode,
+	"info-up":             actionMoveCursorUpInfo,
+	"info-down":           actionMoveCursorDownInfo,
+	"info-open":           actionOpenBookmark,
+	"info-canvas":         actionOpenCanvas,
+	"info-close":          actionCloseInfo,
+	"mode-tasks":          actionTasksMode,
+	"tasks-up":            actionMoveCursorUpTasks,
+	"tasks-down":          actionMoveCursorDownTasks,
+	"tasks-cancel":        actionCancelTask,
+	"tasks-close":         actionCloseInfo,
+	"mode-find":           actionFindMode,
+	"find-backspace":      actionBackSpaceFind,
+	"find-delete":         actionDeleteFind,
+	"find-space":          actionSpaceFind,
+	"find-done":           actionFindDone,
+	"find-clear":          actionFindClear,
+}
+
+// pendingActionMap binds action names to functions that take the key
+// that is pressed after the action was triggered as their argument. See
+// context.AppContext.PendingAction.
+var pendingActionMap = map[string]func(*context.AppContext, rune){
+	"mark-set":  actionSetMarkKey,
+	"mark-jump": actionJumpMarkKey,
+	"slot-set":  actionSetSlotKey,
+
+	"files-delete":  actionDeleteFileKey,
+	"send-confirm":  actionSendConfirmKey,
+	"select-delete": actionDeleteMessageKey,
+
+	"channel-leave":   actionLeaveChannelKey,
+	"channel-archive": actionArchiveChannelKey,
+	"mark-all-read":   actionMarkAllAsReadKey,
+}
+
+// slotCount is the number of channel slots, they're jumped to with the
+// actions slot-1 up to slot-9
+const slotCount = 9
+
+func init() {
+	for i := 1; i <= slotCount; i++ {
+		slot := strconv.Itoa(i)
+		actionMap["slot-"+slot] = func(ctx *context.AppContext) {
+			actionJumpSlot(ctx, slot)
+		}
+	}
 }
 
 // Initialize will start a combination of event handlers and 'background tasks'
 func Initialize(ctx *context.AppContext) {
 
+	// Options that were dropped when migrating the config file
+	for _, warning := range ctx.Config.Warnings {
+		ctx.View.Debug.Println(warning)
+	}
+
+	// Update the Tasks popup when tasks start, stop or progress
+	ctx.Tasks.OnChange = func() {
+		actionRenderTasks(ctx)
+	}
+
 	// Keyboard events
 	eventHandler(ctx)
 
 	// RTM incoming events
 	messageHandler(ctx)
 
-	// User presence
-	go actionSetPresenceAll(ctx)
+	// Remaining conversations, once they're all loaded we can get the
+	// user presence and the unread counts of the conversations
+	go func() {
+		actionLoadChannels(ctx)
+		go actionSetPresenceAll(ctx)
+		go actionLoadUnreadCounts(ctx)
+	}()
+
+	// Keep the conversations in the sidebar up to date
+	go actionRefreshChannels(ctx)
+
+	// Show the current user and the clock in the status bar
+	actionRenderSegments(ctx)
+	go actionRunClock(ctx)
+
+	// Set the user as away after a period without input
+	actionActivity(ctx)
+
+	// Reply to direct messages while the user is away
+	go actionLoadDoNotDisturb(ctx)
+
+	// Show the workspace in the border of the sidebar
+	go actionLoadTeam(ctx)
+
+	// Remind the user of the follow-ups that are due
+	go actionRunFollowUpReminders(ctx)
+
+	// Replies of the thread that is shown in the initial channel
+	if ctx.View.Threads.HasThreads() {
+		actionLoadReplies(
+			ctx,
+			newChannelContext(),
+			ctx.View.Channels.GetSelectedChannel().ID,
+			ctx.View.Threads.GetSelectedThread().ID,
+		)
+	}
 }
 
 // eventHandler will handle events created by the user
@@ -76,7 +389,14 @@ func eventHandler(ctx *context.AppContext) {
 
 	go func() {
 		for {
-			ev := <-ctx.EventQueue
+			var ev termbox.Event
+			select {
+			case ev = <-ctx.EventQueue:
+			case action := <-ctx.ActionQueue:
+				action()
+				continue
+			}
+
 			handleTermboxEvents(ctx, ev)
 			handleMoreTermboxEvents(ctx, ev)
 
@@ -93,6 +413,7 @@ func eventHandler(ctx *context.AppContext) {
 func handleTermboxEvents(ctx *context.AppContext, ev termbox.Event) bool {
 	switch ev.Type {
 	case termbox.EventKey:
+		actionActivity(ctx)
 		actionKeyEvent(ctx, ev)
 	case termbox.EventResize:
 		actionResizeEvent(ctx, ev)
@@ -120,80 +441,160 @@ func messageHandler(ctx *context.AppContext) {
 	go func() {
 		for {
 			select {
-			case rtmEvent := <-ctx.Service.RTM.IncomingEvents:
-				switch ev := rtmEvent.Data.(type) {
-				case *slack.MessageEvent:
-
-					// Construct message
-					msg, err := ctx.Service.CreateMessageFromMessageEvent(ev, ev.Channel)
-					if err != nil {
-						continue
-					}
+			case rtmEvent := <-ctx.Service.IncomingEvents():
+				handleRTMEvent(ctx, rtmEvent)
+			}
+		}
+	}()
+}
 
-					// Add message to the selected channel
-					if ev.Channel == ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID {
-
-						// Get the thread timestamp of the event, we need to
-						// check the previous message as well, because edited
-						// message don't have the thread timestamp
-						var threadTimestamp string
-						if ev.ThreadTimestamp != "" {
-							threadTimestamp = ev.ThreadTimestamp
-						} else if ev.PreviousMessage != nil && ev.PreviousMessage.ThreadTimestamp != "" {
-							threadTimestamp = ev.PreviousMessage.ThreadTimestamp
-						} else {
-							threadTimestamp = ""
-						}
-
-						// When timestamp isn't set this is a thread reply,
-						// handle as such
-						if threadTimestamp != "" {
-							ctx.View.Chat.AddReply(threadTimestamp, msg)
-						} else if threadTimestamp == "" && ctx.Focus == context.ChatFocus {
-							ctx.View.Chat.AddMessage(msg)
-						}
-
-						// we (mis)use actionChangeChannel, to rerender, the
-						// view when a new thread has been started
-						if ctx.View.Chat.IsNewThread(threadTimestamp) {
-							actionChangeChannel(ctx)
-						} else {
-							termui.Render(ctx.View.Chat)
-						}
-
-						// TODO: set Chat.Offset to 0, to automatically scroll
-						// down?
-					}
+// handleRTMEvent will update the view with an event of the real time api
+func handleRTMEvent(ctx *context.AppContext, rtmEvent slack.RTMEvent) {
+	switch ev := rtmEvent.Data.(type) {
+	case *slack.MessageEvent:
 
-					// Set new message indicator for channel, I'm leaving
-					// this here because I also want to be notified when
-					// I'm currently in a channel but not in the terminal
-					// window (tmux). But only create a notification when
-					// it comes from someone else but the current user.
-					if ev.User != ctx.Service.CurrentUserID {
-						actionNewMessage(ctx, ev)
-					}
-				case *slack.PresenceChangeEvent:
-					actionSetPresence(ctx, ev.User, ev.Presence)
-				case *slack.RTMError:
-					ctx.View.Debug.Println(
-						ev.Error(),
-					)
-				}
+		// Remove deleted messages from the Chat pane
+		if ev.SubType == "message_deleted" {
+			actionRemoveMessage(ctx, ev.Channel, ev.DeletedTimestamp)
+			return
+		}
+
+		// Keep the topic in the header of the Chat
+		// pane up to date
+		if ev.SubType == "channel_topic" || ev.SubType == "group_topic" {
+			actionSetTopic(ctx, ev.Channel, ev.Topic)
+		}
+
+		// Construct message
+		msg, err := ctx.Service.CreateMessageFromMessageEvent(ev, ev.Channel)
+		if err != nil {
+			return
+		}
+
+		// Add message to the selected channel, unless we're
+		// previewing a channel from the browser
+		if ev.Channel == ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID &&
+			!isBrowsing(ctx) {
+
+			// Get the thread timestamp of the event, we need to
+			// check the previous message as well, because edited
+			// message don't have the thread timestamp
+			var threadTimestamp string
+			if ev.ThreadTimestamp != "" {
+				threadTimestamp = ev.ThreadTimestamp
+			} else if ev.PreviousMessage != nil && ev.PreviousMessage.ThreadTimestamp != "" {
+				threadTimestamp = ev.PreviousMessage.ThreadTimestamp
+			} else {
+				threadTimestamp = ""
+			}
+
+			// When timestamp is set this is a thread reply,
+			// handle as such
+			if threadTimestamp != "" {
+				actionAddReply(ctx, threadTimestamp, msg)
+			} else {
+				ctx.View.Chat.AddMessage(msg)
+				actionRenderChat(ctx)
 			}
 		}
-	}()
+
+		// Set new message indicator for channel, I'm leaving
+		// this here because I also want to be notified when
+		// I'm currently in a channel but not in the terminal
+		// window (tmux). But only create a notification when
+		// it comes from someone else but the current user.
+		if ev.User != ctx.Service.GetCurrentUserID() {
+			actionNewMessage(ctx, ev, msg)
+			actionAutoReply(ctx, ev)
+		}
+	case *slack.ReactionAddedEvent:
+		if ev.Item.Type == "message" {
+			actionAddReaction(ctx, ev.Item.Channel, ev.Item.Timestamp, ev.Reaction, ev.User, 1)
+		}
+	case *slack.ReactionRemovedEvent:
+		if ev.Item.Type == "message" {
+			actionAddReaction(ctx, ev.Item.Channel, ev.Item.Timestamp, ev.Reaction, ev.User, -1)
+		}
+	case *slack.ChannelMarkedEvent:
+		actionMarkedAsRead(ctx, ev.Channel)
+	case *slack.GroupMarkedEvent:
+		actionMarkedAsRead(ctx, ev.Channel)
+	case *slack.IMMarkedEvent:
+		actionMarkedAsRead(ctx, ev.Channel)
+	case *slack.PrefChangeEvent:
+		if ev.Name == "muted_channels" {
+			actionSetSlackMutes(ctx, ev.Value)
+		}
+	case *slack.DNDUpdatedEvent:
+		if ev.User == ctx.Service.GetCurrentUserID() {
+			autoReply.mu.Lock()
+			autoReply.dnd = ev.Status
+			autoReply.mu.Unlock()
+		}
+	case *slack.ConnectedEvent:
+		// Messages can have been missed while the
+		// connection was lost, or while it was made
+		// again with a refreshed access token
+		if rtmConnected {
+			actionReconnected(ctx)
+		}
+		rtmConnected = true
+	case *slack.PresenceChangeEvent:
+		// Presence changes of several users can be
+		// batched into one event
+		if ev.User != "" {
+			actionSetPresence(ctx, ev.User, ev.Presence)
+		}
+		for _, userID := range ev.Users {
+			actionSetPresence(ctx, userID, ev.Presence)
+		}
+	case *slack.RTMError:
+		ctx.View.Debug.Println(
+			ev.Error(),
+		)
+	}
 }
 
 func actionKeyEvent(ctx *context.AppContext, ev termbox.Event) {
+	// The preview follows the input
+	defer actionRenderPreview(ctx)
 
 	keyStr := getKeyString(ev)
 
+	// When an action is waiting for a key, we pass the key as an argument
+	// to that action instead of looking it up in the key map.
+	if ctx.PendingAction != "" {
+		pending := ctx.PendingAction
+		ctx.PendingAction = ""
+
+		// The Confirm popup is closed before the action is run
+		if ctx.View.Confirm.IsShown() {
+			actionCloseConfirm(ctx)
+		}
+
+		action, ok := pendingActionMap[pending]
+		if ok && ev.Ch != 0 {
+			action(ctx, ev.Ch)
+		}
+		return
+	}
+
 	// Get the action name (actionStr) from the key that
 	// has been pressed. If this is found try to uncover
 	// the associated function with this key and execute
 	// it.
 	actionStr, ok := ctx.Config.KeyMap[ctx.Mode][keyStr]
+
+	// An escape that is quickly followed by another key is received as
+	// alt+key, when that isn't mapped we handle both keys separately.
+	if !ok && ev.Mod == termbox.ModAlt {
+		actionKeyEvent(ctx, termbox.Event{Type: termbox.EventKey, Key: termbox.KeyEsc})
+
+		ev.Mod = 0
+		actionKeyEvent(ctx, ev)
+		return
+	}
+
 	if ok {
 		action, ok := actionMap[actionStr]
 		if ok {
@@ -202,8 +603,19 @@ func actionKeyEvent(ctx *context.AppContext, ev termbox.Event) {
 	} else {
 		if ctx.Mode == context.InsertMode && ev.Ch != 0 {
 			actionInput(ctx.View, ev.Ch)
+			actionUpdateCompletion(ctx)
 		} else if ctx.Mode == context.SearchMode && ev.Ch != 0 {
 			actionSearch(ctx, ev.Ch)
+		} else if ctx.Mode == context.BrowseSearchMode && ev.Ch != 0 {
+			actionSearchBrowser(ctx, ev.Ch)
+		} else if ctx.Mode == context.CommandLineMode && ev.Ch != 0 {
+			actionInput(ctx.View, ev.Ch)
+		} else if ctx.Mode == context.ReactionMode && ev.Ch != 0 {
+			actionSearchReaction(ctx, ev.Ch)
+		} else if ctx.Mode == context.SwitcherMode && ev.Ch != 0 {
+			actionSearchSwitcher(ctx, ev.Ch)
+		} else if ctx.Mode == context.FindMode && ev.Ch != 0 {
+			actionFind(ctx, ev.Ch)
 		}
 	}
 }
@@ -216,14 +628,34 @@ func actionResizeEvent(ctx *context.AppContext, ev termbox.Event) {
 	}
 
 	termui.Body.Width = termui.TermWidth()
+	ctx.View.Completion.Hide()
 
 	// Vertical resize components
 	ctx.View.Channels.List.Height = termui.TermHeight() - ctx.View.Input.Par.Height
 	ctx.View.Chat.List.Height = termui.TermHeight() - ctx.View.Input.Par.Height
+	ctx.View.Threads.List.Height = termui.TermHeight() - ctx.View.Input.Par.Height
 	ctx.View.Debug.List.Height = termui.TermHeight() - ctx.View.Input.Par.Height
+	ctx.View.Browser.List.Height = termui.TermHeight() - ctx.View.Input.Par.Height
+	ctx.View.Files.List.Height = termui.TermHeight() - ctx.View.Input.Par.Height
+	ctx.View.Mentions.List.Height = termui.TermHeight() - ctx.View.Input.Par.Height
+	ctx.View.FollowUps.List.Height = termui.TermHeight() - ctx.View.Input.Par.Height
+	ctx.View.Members.List.Height = termui.TermHeight() - ctx.View.Input.Par.Height
+	ctx.View.Emoji.List.Height = termui.TermHeight() - ctx.View.Input.Par.Height
 
 	termui.Body.Align()
 	termui.Render(termui.Body)
+
+	if ctx.Mode == context.SwitcherMode {
+		actionShowSwitcher(ctx, ctx.View.Switcher.Term)
+	} else if ctx.Mode == context.InfoMode {
+		actionShowInfo(ctx)
+	} else if ctx.Mode == context.TasksMode {
+		actionShowTasks(ctx)
+	}
+	if ctx.View.Confirm.IsShown() {
+		actionConfirm(ctx, ctx.PendingAction, ctx.View.Confirm.Question)
+	}
+	actionRenderToast(ctx)
 }
 
 func actionRedrawGrid(ctx *context.AppContext, threads bool, debug bool) {
@@ -234,15 +666,37 @@ func actionRedrawGrid(ctx *context.AppContext, threads bool, debug bool) {
 	termui.Body.BgColor = termui.ThemeAttr("bg")
 	termui.Body.Width = termui.TermWidth()
 
+	// When browsing, the Browser takes the place of the Channels
+	var sidebar termui.GridBufferer = ctx.View.Channels
+	if isBrowsing(ctx) {
+		sidebar = ctx.View.Browser
+	}
+
+	// When browsing files, mentions, follow-ups, members or emoji, they
+	// take the place of the Chat
+	var main termui.GridBufferer = ctx.View.Chat
+	switch ctx.Mode {
+	case context.FilesMode:
+		main = ctx.View.Files
+	case context.MentionsMode:
+		main = ctx.View.Mentions
+	case context.FollowUpsMode:
+		main = ctx.View.FollowUps
+	case context.MembersMode:
+		main = ctx.View.Members
+	case context.ReactionMode:
+		main = ctx.View.Emoji
+	}
+
 	columns := []*termui.Row{
-		termui.NewCol(ctx.Config.SidebarWidth, 0, ctx.View.Channels),
+		termui.NewCol(ctx.Config.SidebarWidth, 0, sidebar),
 	}
 
 	if threads && debug {
 		columns = append(
 			columns,
 			[]*termui.Row{
-				termui.NewCol(ctx.Config.MainWidth-ctx.Config.ThreadsWidth-3, 0, ctx.View.Chat),
+				termui.NewCol(ctx.Config.MainWidth-ctx.Config.ThreadsWidth-3, 0, main),
 				termui.NewCol(ctx.Config.ThreadsWidth, 0, ctx.View.Threads),
 				termui.NewCol(3, 0, ctx.View.Debug),
 			}...,
@@ -251,7 +705,7 @@ func actionRedrawGrid(ctx *context.AppContext, threads bool, debug bool) {
 		columns = append(
 			columns,
 			[]*termui.Row{
-				termui.NewCol(ctx.Config.MainWidth-ctx.Config.ThreadsWidth, 0, ctx.View.Chat),
+				termui.NewCol(ctx.Config.MainWidth-ctx.Config.ThreadsWidth, 0, main),
 				termui.NewCol(ctx.Config.ThreadsWidth, 0, ctx.View.Threads),
 			}...,
 		)
@@ -259,7 +713,7 @@ func actionRedrawGrid(ctx *context.AppContext, threads bool, debug bool) {
 		columns = append(
 			columns,
 			[]*termui.Row{
-				termui.NewCol(ctx.Config.MainWidth-5, 0, ctx.View.Chat),
+				termui.NewCol(ctx.Config.MainWidth-5, 0, main),
 				termui.NewCol(ctx.Config.MainWidth-6, 0, ctx.View.Debug),
 			}...,
 		)
@@ -267,7 +721,7 @@ func actionRedrawGrid(ctx *context.AppContext, threads bool, debug bool) {
 		columns = append(
 			columns,
 			[]*termui.Row{
-				termui.NewCol(ctx.Config.MainWidth, 0, ctx.View.Chat),
+				termui.NewCol(ctx.Config.MainWidth, 0, main),
 			}...,
 		)
 	}
@@ -305,6 +759,10 @@ func actionSpace(ctx *context.AppContext) {
 func actionBackSpace(ctx *context.AppContext) {
 	ctx.View.Input.Backspace()
 	termui.Render(ctx.View.Input)
+
+	if ctx.Mode == context.InsertMode {
+		actionUpdateCompletion(ctx)
+	}
 }
 
 func actionDelete(ctx *context.AppContext) {
@@ -322,350 +780,4315 @@ func actionMoveCursorLeft(ctx *context.AppContext) {
 	termui.Render(ctx.View.Input)
 }
 
-func actionSend(ctx *context.AppContext) {
-	if !ctx.View.Input.IsEmpty() {
-
-		// Clear message before sending, to combat
-		// quick succession of actionSend
-		message := ctx.View.Input.GetText()
-		ctx.View.Input.Clear()
-		termui.Render(ctx.View.Input)
-
-		// Send slash command
-		isCmd, err := ctx.Service.SendCommand(
-			ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID,
-			message,
-		)
-		if err != nil {
-			ctx.View.Debug.Println(
-				err.Error(),
-			)
+// actionComplete will complete the mention or emoji shortcode before the
+// cursor, e.g. "@ba" becomes "@backend-team" and ":par" becomes ":parrot:".
+// Completing again without changing the input will replace it with the
+// next candidate of the Completion popup.
+func actionComplete(ctx *context.AppContext) {
+	popup := ctx.View.Completion
+	if completion.text != "" && completion.text == ctx.View.Input.GetText() && popup.IsShown() {
+		popup.Selected = (popup.Selected + 1) % len(popup.Candidates)
+	} else {
+		word, start := ctx.View.Input.GetWordBeforeCursor()
+		if !isCompletable(word) {
+			return
 		}
 
-		// Send message
-		if !isCmd {
-			if ctx.Focus == context.ChatFocus {
-				err := ctx.Service.SendMessage(
-					ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID,
-					message,
-				)
-				if err != nil {
-					ctx.View.Debug.Println(
-						err.Error(),
-					)
-				}
-
-			}
-
-			if ctx.Focus == context.ThreadFocus {
-				err := ctx.Service.SendReply(
-					ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID,
-					ctx.View.Threads.ChannelItems[ctx.View.Threads.SelectedChannel].ID,
-					message,
-				)
-				if err != nil {
-					ctx.View.Debug.Println(
-						err.Error(),
-					)
-				}
+		if !popup.IsShown() {
+			candidates := getCompletionCandidates(ctx, word)
+			if len(candidates) == 0 {
+				return
 			}
+			popup.Show(ctx.View.Input, candidates)
 		}
 
-		// Clear notification icon if there is any
-		channelItem := ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel]
-		if channelItem.Notification {
-			ctx.Service.MarkAsRead(channelItem)
-			ctx.View.Channels.MarkAsRead(ctx.View.Channels.SelectedChannel)
-		}
-		termui.Render(ctx.View.Channels)
+		completion.start = start
 	}
-}
-
-// actionSearch will search through the channels based on the users
-// input. A time is implemented to make sure the actual searching
-// and changing of channels is done when the user's typing is paused.
-func actionSearch(ctx *context.AppContext, key rune) {
-	actionInput(ctx.View, key)
-
-	go func() {
-		if scrollTimer != nil {
-			scrollTimer.Stop()
-		}
-
-		scrollTimer = time.NewTimer(time.Second / 4)
-		<-scrollTimer.C
 
-		// Only actually search when the time expires
-		term := ctx.View.Input.GetText()
-		ctx.View.Channels.Search(term)
-		actionChangeChannel(ctx)
-	}()
+	ctx.View.Input.ReplaceBeforeCursor(
+		completion.start, popup.GetSelectedCandidate().Text,
+	)
+	completion.text = ctx.View.Input.GetText()
+	termui.Render(ctx.View.Input, popup)
 }
 
-// actionQuit will exit the program by using os.Exit, this is
-// done because we are using a custom termui EvtStream. Which
-// we won't be able to call termui.StopLoop() on. See main.go
-// for the customEvtStream and why this is done.
-func actionQuit(ctx *context.AppContext) {
-	termbox.Close()
-	os.Exit(0)
-}
+// actionAcceptCompletion will complete the word before the cursor with the
+// selected candidate of the Completion popup, and hide it
+func actionAcceptCompletion(ctx *context.AppContext) {
+	if completion.text != ctx.View.Input.GetText() {
+		_, completion.start = ctx.View.Input.GetWordBeforeCursor()
+	}
 
-func actionInsertMode(ctx *context.AppContext) {
-	ctx.Mode = context.InsertMode
-	ctx.View.Mode.SetInsertMode()
-}
+	ctx.View.Input.ReplaceBeforeCursor(
+		completion.start, ctx.View.Completion.GetSelectedCandidate().Text+" ",
+	)
+	termui.Render(ctx.View.Input)
 
-func actionCommandMode(ctx *context.AppContext) {
-	ctx.Mode = context.CommandMode
-	ctx.View.Mode.SetCommandMode()
+	actionHideCompletion(ctx)
 }
 
-func actionSearchMode(ctx *context.AppContext) {
-	ctx.Mode = context.SearchMode
-	ctx.View.Mode.SetSearchMode()
-}
+// actionUpdateCompletion will show the candidates for the mention or emoji
+// shortcode before the cursor in the Completion popup, once the typing is
+// paused. Users are searched as well, which can take a while the first
+// time.
+func actionUpdateCompletion(ctx *context.AppContext) {
+	if completionTimer != nil {
+		completionTimer.Stop()
+	}
 
-func actionGetMessages(ctx *context.AppContext) {
-	msgs, _, err := ctx.Service.GetMessages(
-		ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID,
-		ctx.View.Chat.GetMaxItems(),
-		1,
-	)
-	if err != nil {
-		termbox.Close()
-		log.Println(err)
-		os.Exit(0)
+	word, _ := ctx.View.Input.GetWordBeforeCursor()
+	if !isCompletable(word) ||
+		(strings.HasPrefix(word, ":") && len(word) < emojiCompletionLength) {
+		actionHideCompletion(ctx)
+		return
 	}
 
-	ctx.View.Chat.SetMessages(msgs)
+	text := ctx.View.Input.GetText()
+	completionTimer = time.AfterFunc(time.Second/4, func() {
+		candidates := getCompletionCandidates(ctx, word)
 
-	termui.Render(ctx.View.Chat)
-}
+		// The input has changed in the meantime
+		if ctx.Mode != context.InsertMode || text != ctx.View.Input.GetText() {
+			return
+		}
 
-// actionMoveCursorUpChannels will move the channel cursor up
-// without loading the channel
-func actionMoveCursorUpChannels(ctx *context.AppContext) {
-	ctx.View.Channels.MoveCursorUp()
-	termui.Render(ctx.View.Channels)
-}
+		if len(candidates) == 0 {
+			actionHideCompletion(ctx)
+			return
+		}
 
-// actionMoveCursorDownChannels will move the channel cursor down
-// without loading the channel
-func actionMoveCursorDownChannels(ctx *context.AppContext) {
-	ctx.View.Channels.MoveCursorDown()
-	termui.Render(ctx.View.Channels)
+		ctx.View.Completion.Show(ctx.View.Input, candidates)
+		termui.Render(ctx.View.Completion)
+	})
 }
 
-func actionMoveCursorTopChannels(ctx *context.AppContext) {
-	ctx.View.Channels.MoveCursorTop()
-	termui.Render(ctx.View.Channels)
-}
+// actionHideCompletion will hide the Completion popup, and render what was
+// underneath it
+func actionHideCompletion(ctx *context.AppContext) {
+	if !ctx.View.Completion.IsShown() {
+		return
+	}
 
-func actionMoveCursorBottomChannels(ctx *context.AppContext) {
-	ctx.View.Channels.MoveCursorBottom()
-	termui.Render(ctx.View.Channels)
-}
+	ctx.View.Completion.Hide()
+	completion.text = ""
 
-func actionSearchNextChannels(ctx *context.AppContext) {
-	ctx.View.Channels.SearchNext()
-	termui.Render(ctx.View.Channels)
+	actionRenderChat(ctx)
+	if ctx.View.Threads.HasThreads() {
+		termui.Render(ctx.View.Threads)
+	}
 }
 
-func actionSearchPrevChannels(ctx *context.AppContext) {
-	ctx.View.Channels.SearchPrev()
-	termui.Render(ctx.View.Channels)
+// emojiCompletionLength is the length of an emoji shortcode that is typed,
+// including the colon, from which the Completion popup is shown
+const emojiCompletionLength = 3
+
+// emojiShortcodeRegex matches an emoji shortcode that is being typed, e.g.
+// ":par", but not one that is complete or a time like "12:30"
+var emojiShortcodeRegex = regexp.MustCompile(`^:[a-z0-9_+'-]+$`)
+
+// isCompletable returns whether the word before the cursor is a mention or
+// an emoji shortcode that can be completed
+func isCompletable(word string) bool {
+	if strings.HasPrefix(word, "@") {
+		return len(word) > 1
+	}
+	return emojiShortcodeRegex.MatchString(strings.ToLower(word))
 }
 
-func actionJumpChannels(ctx *context.AppContext) {
-	ctx.View.Channels.Jump()
-	termui.Render(ctx.View.Channels)
+// getCompletionCandidates returns the candidates for completing word, which
+// is either a mention or an emoji shortcode
+func getCompletionCandidates(ctx *context.AppContext, word string) []components.CompletionItem {
+	if strings.HasPrefix(word, ":") {
+		return getEmojiCandidates(ctx, word[1:])
+	}
+	return getMentionCandidates(ctx, word[1:])
 }
 
-func actionChangeChannel(ctx *context.AppContext) {
-	// Clear messages from Chat pane
-	ctx.View.Chat.ClearMessages()
+// getEmojiCandidates returns the emoji of which the shortcode starts with
+// prefix, followed by the ones that contain it, with the emoji as preview.
+// The emoji that the user uses most often are listed first. The custom
+// emoji of the workspace don't have a preview.
+func getEmojiCandidates(ctx *context.AppContext, prefix string) []components.CompletionItem {
+	lower := strings.ToLower(prefix)
 
-	// Get messages of the SelectedChannel, and get the count of messages
-	// that fit into the Chat component
-	msgs, threads, err := ctx.Service.GetMessages(
-		ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID,
-		ctx.View.Chat.GetMaxItems(),
-		1,
-	)
-	if err != nil {
-		termbox.Close()
-		log.Println(err)
-		os.Exit(0)
+	rank := make(map[string]int)
+	for i, name := range ctx.Service.GetFrequentEmoji() {
+		rank[":"+name+":"] = i + 1
 	}
 
-	// Set messages for the channel
-	ctx.View.Chat.SetMessages(msgs)
+	var starts, contains []components.CompletionItem
+	add := func(name string, emoji string) {
+		item := components.CompletionItem{
+			Text:        ":" + name + ":",
+			Description: emoji,
+		}
 
-	// Set the threads identifiers in the threads pane
-	var haveThreads bool
-	if len(threads) > 0 {
-		haveThreads = true
+		if strings.HasPrefix(name, lower) {
+			starts = append(starts, item)
+		} else if strings.Contains(name, lower) {
+			contains = append(contains, item)
+		}
+	}
 
-		// Make the first thread the current Channel
-		ctx.View.Threads.SetChannels(
-			append(
-				[]components.ChannelItem{ctx.View.Channels.GetSelectedChannel()},
-				threads...,
-			),
-		)
+	for code, emoji := range config.EmojiCodemap {
+		add(strings.Trim(code, ":"), emoji)
+	}
+	for _, name := range ctx.Service.GetCustomEmoji() {
+		add(name, "")
+	}
 
-		// Reset position of SelectedChannel
-		ctx.View.Threads.MoveCursorTop()
+	for _, candidates := range [][]components.CompletionItem{starts, contains} {
+		sort.Slice(candidates, func(i, j int) bool {
+			a, b := rank[candidates[i].Text], rank[candidates[j].Text]
+			if a != b {
+				return a != 0 && (b == 0 || a < b)
+			}
+			return candidates[i].Text < candidates[j].Text
+		})
 	}
 
-	// Set channel name for the Chat pane
-	ctx.View.Chat.SetBorderLabel(
-		ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].GetChannelName(),
-	)
+	candidates := append(starts, contains...)
+	if len(candidates) > completionLimit {
+		candidates = candidates[:completionLimit]
+	}
 
-	// Clear notification icon if there is any
+	return candidates
+}
+
+// getMentionCandidates returns the user groups of which the handle starts
+// with prefix, followed by the users of which the name matches prefix
+func getMentionCandidates(ctx *context.AppContext, prefix string) []components.CompletionItem {
+	lower := strings.ToLower(prefix)
+
+	groups, err := ctx.Service.GetUserGroups(gocontext.Background())
+	if err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to get user groups: %v", err),
+		)
+	}
+
+	var candidates []components.CompletionItem
+	for _, group := range groups {
+		if strings.HasPrefix(strings.ToLower(group.Handle), lower) {
+			candidates = append(candidates, components.CompletionItem{
+				Text:        "@" + group.Handle,
+				Description: group.Name,
+			})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].Text < candidates[j].Text
+	})
+
+	users, err := ctx.Service.SearchUsers(gocontext.Background(), prefix)
+	if err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to search users: %v", err),
+		)
+	}
+	for _, user := range users {
+		candidates = append(candidates, components.CompletionItem{
+			Text:        "@" + user.Name,
+			Description: user.RealName,
+		})
+	}
+
+	if len(candidates) > completionLimit {
+		candidates = candidates[:completionLimit]
+	}
+
+	return candidates
+}
+
+// broadcastRegex matches the mentions that notify the members of a channel
+var broadcastRegex = regexp.MustCompile(`(^|\s)@(channel|here|everyone)\b`)
+
+func actionSend(ctx *context.AppContext) {
+	if ctx.View.Completion.IsShown() {
+		actionAcceptCompletion(ctx)
+		return
+	}
+
+	if ctx.View.Input.IsEmpty() {
+		return
+	}
+
+	// A broadcast to a large channel has to be confirmed first, see
+	// actionSendConfirmKey. Edits don't notify anyone.
+	if count, ok := actionIsLargeBroadcast(ctx, ctx.View.Input.GetText()); ok && editing.messageID == "" {
+		actionConfirm(ctx, "send-confirm",
+			fmt.Sprintf("notify all %d members of the channel?", count),
+		)
+		return
+	}
+
+	actionSendMessage(ctx)
+}
+
+// sendLaterHour is the hour of the morning in the timezone of the recipient
+// at which a message that is sent later is delivered
+const sendLaterHour = 9
+
+// actionSendLater will send the text of the input in a direct message when
+// the recipient is online. When they're not, it's scheduled for the next
+// working morning in their timezone.
+func actionSendLater(ctx *context.AppContext) {
+	actionHideCompletion(ctx)
+
+	message := ctx.View.Input.GetText()
+	if message == "" || editing.messageID != "" || strings.HasPrefix(message, "/") {
+		return
+	}
+
+	channel := ctx.View.Channels.GetSelectedChannel()
+	if channel.Type != components.ChannelTypeIM || ctx.Focus == context.ThreadFocus {
+		ctx.View.Input.SetStatus("messages can only be sent later in direct messages")
+		termui.Render(ctx.View.Input)
+		return
+	}
+
+	presence, err := ctx.Service.GetUserPresence(gocontext.Background(), channel.UserID)
+	if err == nil && presence == "active" {
+		actionSendMessage(ctx)
+		actionToast(ctx, fmt.Sprintf("sent right away, %s is online", channel.Name))
+		return
+	}
+
+	loc, err := ctx.Service.GetUserTimezone(gocontext.Background(), channel.UserID)
+	if err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to get the timezone of %s: %v", channel.Name, err),
+		)
+		return
+	}
+
+	postAt := nextWorkingMorning(time.Now().In(loc), sendLaterHour)
+	err = ctx.Service.ScheduleMessage(gocontext.Background(), channel.ID, message, postAt)
+	if err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to schedule message: %v", err),
+		)
+		return
+	}
+
+	ctx.View.Input.Clear()
+	termui.Render(ctx.View.Input)
+	actionToast(ctx, fmt.Sprintf(
+		"scheduled for %s their time, %s yours",
+		postAt.Format("Mon 15:04"), postAt.Local().Format("Mon 15:04"),
+	))
+}
+
+// nextWorkingMorning returns the first weekday after now at hour, in the
+// location of now
+func nextWorkingMorning(now time.Time, hour int) time.Time {
+	morning := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	if !morning.After(now) {
+		morning = morning.AddDate(0, 0, 1)
+	}
+
+	for morning.Weekday() == time.Saturday || morning.Weekday() == time.Sunday {
+		morning = morning.AddDate(0, 0, 1)
+	}
+
+	return morning
+}
+
+// toastDuration is how long a message in the Toast is shown
+const toastDuration = 3 * time.Second
+
+// actionToast will show a message that confirms an action in the Toast,
+// it's dismissed after the toastDuration. When a message is shown already
+// the message is queued.
+func actionToast(ctx *context.AppContext, message string) {
+	if ctx.View.Toast.Push(message) {
+		time.AfterFunc(toastDuration, func() {
+			actionDismissToast(ctx)
+		})
+	}
+	actionRenderToast(ctx)
+}
+
+// actionDismissToast will dismiss the message that is shown in the Toast,
+// and show the next message when there is one
+func actionDismissToast(ctx *context.AppContext) {
+	more := ctx.View.Toast.Pop()
+
+	// Render what was underneath the Toast, including the popups
+	termui.Render(termui.Body)
+	actionRenderChat(ctx)
+
+	if more {
+		time.AfterFunc(toastDuration, func() {
+			actionDismissToast(ctx)
+		})
+		actionRenderToast(ctx)
+	}
+}
+
+// actionRenderToast will render the Toast in the bottom right corner of
+// the Chat pane, when a message is shown
+func actionRenderToast(ctx *context.AppContext) {
+	if !ctx.View.Toast.IsShown() {
+		return
+	}
+
+	chat := ctx.View.Chat.List
+	ctx.View.Toast.Place(chat.X, chat.Y, chat.Width, chat.Height)
+	termui.Render(ctx.View.Toast)
+}
+
+// spinnerInterval is the time between the frames of the spinners
+const spinnerInterval = 100 * time.Millisecond
+
+// actionStartSpinner will show the spinner with the message on its pane,
+// until the operation is stopped with actionStopSpinner. It returns the id
+// of the operation.
+func actionStartSpinner(ctx *context.AppContext, spinner *components.Spinner, message string) int {
+	id, start := spinner.Start(message)
+	if start {
+		go func() {
+			ticker := time.NewTicker(spinnerInterval)
+			defer ticker.Stop()
+
+			for range ticker.C {
+				if !spinner.Tick() {
+					return
+				}
+				actionRenderSpinner(ctx, spinner)
+			}
+		}()
+	}
+
+	actionRenderSpinner(ctx, spinner)
+	return id
+}
+
+// actionSetSpinnerMessage will replace the message of the operation with
+// id, to show its progress
+func actionSetSpinnerMessage(ctx *context.AppContext, spinner *components.Spinner, id int, message string) {
+	spinner.SetMessage(id, message)
+	actionRenderSpinner(ctx, spinner)
+}
+
+// actionStopSpinner will stop the operation with id. When no operations
+// are in progress anymore, the pane is rendered to remove the spinner.
+func actionStopSpinner(ctx *context.AppContext, spinner *components.Spinner, id int) {
+	spinner.Stop(id)
+	if spinner.IsShown() {
+		actionRenderSpinner(ctx, spinner)
+		return
+	}
+
+	if !isSpinnerPaneShown(ctx, spinner) {
+		return
+	}
+
+	switch spinner {
+	case ctx.View.ChannelsSpinner:
+		termui.Render(ctx.View.Channels)
+	case ctx.View.ChatSpinner:
+		actionRenderChat(ctx)
+	case ctx.View.ThreadsSpinner:
+		termui.Render(ctx.View.Threads)
+	case ctx.View.FilesSpinner:
+		termui.Render(ctx.View.Files)
+	}
+}
+
+// actionRenderSpinner will render the spinner, when the pane it's drawn on
+// is shown
+func actionRenderSpinner(ctx *context.AppContext, spinner *components.Spinner) {
+	if isSpinnerPaneShown(ctx, spinner) {
+		termui.Render(spinner)
+	}
+}
+
+// isSpinnerPaneShown returns whether the pane that the spinner is drawn on
+// is shown
+func isSpinnerPaneShown(ctx *context.AppContext, spinner *components.Spinner) bool {
+	switch spinner {
+	case ctx.View.ChannelsSpinner:
+		return !isBrowsing(ctx)
+	case ctx.View.ChatSpinner:
+		return !isChatReplaced(ctx)
+	case ctx.View.ThreadsSpinner:
+		return ctx.View.Threads.HasThreads()
+	case ctx.View.FilesSpinner:
+		return ctx.Mode == context.FilesMode
+	}
+	return false
+}
+
+// task is an operation that is tracked by ctx.Tasks, and shown by a
+// spinner on the pane that it affects
+type task struct {
+	id          int
+	description string
+	spinner     *components.Spinner
+	spinnerID   int
+}
+
+// actionStartTask will start a task with the description, that is listed
+// in the Tasks popup and shown by the spinner, when it isn't nil. It
+// returns the context that the task has to use, which is cancelled when
+// the task is cancelled in the Tasks popup or when parent is cancelled.
+// The task is stopped with actionStopTask.
+func actionStartTask(ctx *context.AppContext, spinner *components.Spinner, parent gocontext.Context, description string) (gocontext.Context, task) {
+	taskCtx, id := ctx.Tasks.Start(parent, description)
+
+	t := task{id: id, description: description, spinner: spinner}
+	if spinner != nil {
+		t.spinnerID = actionStartSpinner(ctx, spinner, description)
+	}
+
+	return taskCtx, t
+}
+
+// actionSetTaskProgress will set the progress of the task, e.g. "42%"
+func actionSetTaskProgress(ctx *context.AppContext, t task, progress string) {
+	ctx.Tasks.SetProgress(t.id, progress)
+	if t.spinner != nil {
+		actionSetSpinnerMessage(ctx, t.spinner, t.spinnerID,
+			fmt.Sprintf("%s %s", t.description, progress),
+		)
+	}
+}
+
+// actionStopTask will stop the task, once it has finished or has been
+// cancelled
+func actionStopTask(ctx *context.AppContext, t task) {
+	ctx.Tasks.Done(t.id)
+	if t.spinner != nil {
+		actionStopSpinner(ctx, t.spinner, t.spinnerID)
+	}
+}
+
+// actionConfirm will ask the question in the Confirm popup, on top of the
+// Chat pane. The pending action is run with the key that answers it.
+func actionConfirm(ctx *context.AppContext, pending string, question string) {
+	ctx.PendingAction = pending
+
+	chat := ctx.View.Chat.List
+	ctx.View.Confirm.Show(question, chat.X, chat.Y, chat.Width, chat.Height)
+	termui.Render(ctx.View.Confirm)
+}
+
+// actionCloseConfirm will close the Confirm popup, and render what was
+// underneath it
+func actionCloseConfirm(ctx *context.AppContext) {
+	ctx.View.Confirm.Hide()
+	actionRedrawGrid(ctx, ctx.View.Threads.HasThreads(), ctx.Debug)
+}
+
+// actionSendConfirmKey will send the message when the broadcast has been
+// confirmed with y, otherwise the message is kept in the input to edit it
+func actionSendConfirmKey(ctx *context.AppContext, key rune) {
+	actionRenderStatus(ctx)
+
+	if key != 'y' {
+		return
+	}
+
+	actionSendMessage(ctx)
+}
+
+// actionIsLargeBroadcast returns whether the message mentions @channel,
+// @here or @everyone in a channel with more members than the broadcast_warn
+// setting, and the number of members. Replies in threads don't notify the
+// channel so they aren't considered.
+func actionIsLargeBroadcast(ctx *context.AppContext, message string) (int, bool) {
+	if ctx.Config.BroadcastWarn == 0 || ctx.Focus == context.ThreadFocus {
+		return 0, false
+	}
+
+	if strings.HasPrefix(message, "/") || !broadcastRegex.MatchString(message) {
+		return 0, false
+	}
+
+	presenceMu.Lock()
+	defer presenceMu.Unlock()
+
+	if members.channelID != ctx.View.Channels.GetSelectedChannel().ID {
+		return 0, false
+	}
+
+	count := len(members.userIDs)
+	return count, count > ctx.Config.BroadcastWarn
+}
+
+// actionSendMessage will send the text of the input as a message, or as a
+// reply when the Threads pane is focused
+func actionSendMessage(ctx *context.AppContext) {
+	// Clear message before sending, to combat
+	// quick succession of actionSend
+	message := ctx.View.Input.GetText()
+	ctx.View.Input.Clear()
+	termui.Render(ctx.View.Input)
+
+	if editing.messageID != "" {
+		actionSendEdit(ctx, message)
+		return
+	}
+
+	// Send slash command
+	isCmd, err := ctx.Service.SendCommand(
+		gocontext.Background(),
+		ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID,
+		message,
+	)
+	if err != nil {
+		// Keep the command in the input, so that a typo can be fixed
+		if _, ok := err.(*service.UnknownCommandError); ok {
+			ctx.View.Input.ReplaceBeforeCursor(0, message)
+			ctx.View.Input.SetStatus(err.Error())
+			termui.Render(ctx.View.Input)
+			return
+		}
+
+		ctx.View.Debug.Println(
+			err.Error(),
+		)
+	}
+
+	// Send message
+	if !isCmd {
+		if ctx.Focus != context.ThreadFocus {
+			err := ctx.Service.SendMessage(
+				gocontext.Background(),
+				ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID,
+				message,
+			)
+			if err != nil {
+				ctx.View.Debug.Println(
+					err.Error(),
+				)
+			}
+
+		} else {
+			err := ctx.Service.SendReply(
+				gocontext.Background(),
+				ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID,
+				ctx.View.Threads.GetSelectedThread().ID,
+				message,
+			)
+			if err != nil {
+				ctx.View.Debug.Println(
+					err.Error(),
+				)
+			}
+		}
+	}
+
+	// Clear notification icon if there is any
 	channelItem := ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel]
 	if channelItem.Notification {
-		ctx.Service.MarkAsRead(channelItem)
+		ctx.Service.MarkAsRead(gocontext.Background(), channelItem)
 		ctx.View.Channels.MarkAsRead(ctx.View.Channels.SelectedChannel)
+		actionRenderStatus(ctx)
+	}
+	termui.Render(ctx.View.Channels)
+}
+
+// actionEditMessage will load the most recent message of the current user
+// in the selected channel into the input, sending it will replace the text
+// of that message, see actionSendEdit
+func actionEditMessage(ctx *context.AppContext) {
+	msg, ok := ctx.View.Chat.GetLastMessage(ctx.Service.GetCurrentUserID())
+	if !ok {
+		ctx.View.Input.SetStatus("there is no message of yours to edit")
+		termui.Render(ctx.View.Input)
+		return
+	}
+
+	actionInsertMode(ctx)
+	if ctx.Mode != context.InsertMode {
+		return
+	}
+
+	editing.channelID = ctx.View.Channels.GetSelectedChannel().ID
+	editing.messageID = msg.ID
+
+	ctx.View.Input.Clear()
+	ctx.View.Input.ReplaceBeforeCursor(0, msg.Content)
+	ctx.View.Input.SetStatus("editing message, <esc> cancels")
+	termui.Render(ctx.View.Input)
+}
+
+// actionSendEdit will replace the text of the message that is being edited,
+// the Chat pane is updated right away instead of waiting for the event of
+// the edit
+func actionSendEdit(ctx *context.AppContext, message string) {
+	channelID, messageID := editing.channelID, editing.messageID
+	editing.channelID, editing.messageID = "", ""
+	actionRenderStatus(ctx)
+
+	err := ctx.Service.EditMessage(gocontext.Background(), channelID, messageID, message)
+	if err != nil {
+		ctx.View.Debug.Println(
+			err.Error(),
+		)
+		return
+	}
+
+	msg, ok := ctx.View.Chat.Messages[messageID]
+	if !ok || channelID != ctx.View.Channels.GetSelectedChannel().ID {
+		return
+	}
+
+	msg.Content = ctx.Service.PreviewMessage(gocontext.Background(), channelID, message).Content
+	msg.Edited = true
+	ctx.View.Chat.AddMessage(msg)
+	actionRenderChat(ctx)
+}
+
+// actionToggleAttachments will expand or collapse the attachments of the
+// message at the bottom of the Chat pane
+func actionToggleAttachments(ctx *context.AppContext) {
+	if ctx.View.Chat.ToggleAttachments() {
+		termui.Render(ctx.View.Chat)
+	}
+}
+
+// actionTogglePreview will show or hide the preview of the message that is
+// being composed
+func actionTogglePreview(ctx *context.AppContext) {
+	showPreview = !showPreview
+}
+
+// actionRenderPreview will show the message that is being composed in the
+// Chat pane, as it will be shown once it's sent. It's hidden outside of
+// insert mode, or when the input is empty.
+func actionRenderPreview(ctx *context.AppContext) {
+	if !showPreview || ctx.Mode != context.InsertMode || ctx.View.Input.IsEmpty() {
+		if ctx.View.Chat.Preview != nil {
+			ctx.View.Chat.SetPreview(nil)
+			termui.Render(ctx.View.Chat)
+		}
+		return
+	}
+
+	preview := ctx.Service.PreviewMessage(
+		gocontext.Background(),
+		ctx.View.Channels.GetSelectedChannel().ID,
+		ctx.View.Input.GetText(),
+	)
+	ctx.View.Chat.SetPreview(&preview)
+	termui.Render(ctx.View.Chat)
+}
+
+// actionSearch will search through the channels based on the users
+// input. A time is implemented to make sure the actual searching
+// and changing of channels is done when the user's typing is paused.
+func actionSearch(ctx *context.AppContext, key rune) {
+	actionInput(ctx.View, key)
+
+	go func() {
+		if scrollTimer != nil {
+			scrollTimer.Stop()
+		}
+
+		scrollTimer = time.NewTimer(time.Second / 4)
+		<-scrollTimer.C
+
+		// Only actually search when the time expires
+		term := ctx.View.Input.GetText()
+		ctx.View.Channels.Search(term)
+		actionChangeChannel(ctx)
+	}()
+}
+
+// actionShutdown will mark the channel that is open as read, and close the
+// service so the websocket is disconnected and the writes to the persistent
+// cache are finished. The deferred calls in main.go aren't run on quit.
+func actionShutdown(ctx *context.AppContext) {
+	reqCtx, cancel := gocontext.WithTimeout(gocontext.Background(), 2*time.Second)
+	defer cancel()
+
+	// The channels are empty when the last one has been left
+	if len(ctx.View.Channels.ChannelItems) > 0 {
+		channelItem := ctx.View.Channels.GetSelectedChannel()
+		if channelItem.Notification {
+			ctx.Service.MarkAsRead(reqCtx, channelItem)
+		}
+	}
+
+	if err := ctx.Service.Close(); err != nil {
+		ctx.View.Debug.Println(err.Error())
+	}
+}
+
+// actionQuit will exit the program by using os.Exit, this is
+// done because we are using a custom termui EvtStream. Which
+// we won't be able to call termui.StopLoop() on. See main.go
+// for the customEvtStream and why this is done.
+func actionQuit(ctx *context.AppContext) {
+	actionShutdown(ctx)
+	views.RestoreTitle()
+	termbox.Close()
+	os.Exit(0)
+}
+
+func actionInsertMode(ctx *context.AppContext) {
+	if status, ok := actionCanPost(ctx); !ok {
+		ctx.View.Input.SetStatus(status)
+		termui.Render(ctx.View.Input)
+		return
+	}
+
+	ctx.Mode = context.InsertMode
+	ctx.View.Mode.SetInsertMode()
+	actionRenderFocus(ctx)
+}
+
+func actionCommandMode(ctx *context.AppContext) {
+	actionHideCompletion(ctx)
+
+	// Leaving insert mode cancels the edit of a message
+	if editing.messageID != "" {
+		editing.channelID, editing.messageID = "", ""
+		ctx.View.Input.Clear()
+		actionRenderStatus(ctx)
+	}
+
+	ctx.Mode = context.CommandMode
+	ctx.View.Mode.SetCommandMode()
+	actionRenderFocus(ctx)
+}
+
+func actionSearchMode(ctx *context.AppContext) {
+	ctx.Mode = context.SearchMode
+	ctx.View.Mode.SetSearchMode()
+}
+
+func actionCommandLineMode(ctx *context.AppContext) {
+	ctx.Mode = context.CommandLineMode
+	ctx.View.Mode.SetCommandLineMode()
+}
+
+// actionRunCommand will run the command that has been typed in the
+// command line, e.g. "filter unread", and return to command mode
+func actionRunCommand(ctx *context.AppContext) {
+	args := strings.Fields(ctx.View.Input.GetText())
+	actionClearInput(ctx)
+
+	if len(args) == 0 {
+		return
+	}
+
+	command, ok := commandMap[args[0]]
+	if !ok {
+		ctx.View.Input.SetStatus(fmt.Sprintf("unknown command: %s", args[0]))
+		termui.Render(ctx.View.Input)
+		return
+	}
+
+	if err := command(ctx, args[1:]); err != nil {
+		ctx.View.Input.SetStatus(err.Error())
+		termui.Render(ctx.View.Input)
+	}
+}
+
+// commandFilter will restrict the channels that are shown, e.g.
+// ":filter unread". Without an argument all channels are shown again.
+func commandFilter(ctx *context.AppContext, args []string) error {
+	filter := components.FilterAll
+	if len(args) > 0 {
+		filter = args[0]
+	}
+
+	selected := ctx.View.Channels.SelectedChannel
+	if err := ctx.View.Channels.SetFilter(filter); err != nil {
+		return err
+	}
+
+	if ctx.View.Channels.SelectedChannel != selected {
+		actionChangeChannel(ctx)
+	}
+	actionRenderChannels(ctx)
+
+	return nil
+}
+
+// commandMore will extend the history that is fetched of the selected
+// channel by another window of history_days and history_count, and reloads
+// the channel
+func commandMore(ctx *context.AppContext, args []string) error {
+	if len(args) > 0 {
+		return errors.New("usage: more")
+	}
+
+	channelID := ctx.View.Channels.GetSelectedChannel().ID
+	ctx.Conversations.Update(channelID, func(state *context.Conversation) {
+		state.HistoryWindows++
+	})
+	actionChangeChannel(ctx)
+
+	count, days := actionGetHistoryWindow(ctx, channelID)
+	ctx.View.Input.SetStatus(
+		fmt.Sprintf("showing up to %d messages of the last %d days", count, days),
+	)
+	termui.Render(ctx.View.Input)
+
+	return nil
+}
+
+// commandAt will select the message with a timestamp in the selected
+// channel, e.g. ":at 1589026482.002700". The timestamp of a message is
+// copied with enter in select mode, the form of permalinks is accepted as
+// well, e.g. ":at p1589026482002700".
+func commandAt(ctx *context.AppContext, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: at <timestamp>")
+	}
+
+	ts := args[0]
+	if strings.HasPrefix(ts, "p") && !strings.Contains(ts, ".") && len(ts) > 7 {
+		ts = ts[1:len(ts)-6] + "." + ts[len(ts)-6:]
+	}
+
+	if !ctx.View.Chat.SelectMessage(ts) {
+		return fmt.Errorf("no message at %s, try :more to fetch more of the history", args[0])
+	}
+
+	ctx.Mode = context.SelectMode
+	ctx.View.Mode.SetSelectMode()
+	actionRenderChat(ctx)
+
+	return nil
+}
+
+// commandFlag will flag the selected message for follow-up, with an
+// optional due time at which a reminder is shown, e.g. ":flag 2h" or
+// ":flag 15:04". It's opened with f in select mode.
+func commandFlag(ctx *context.AppContext, args []string) error {
+	if len(args) > 1 {
+		return errors.New("usage: flag [2h|15:04]")
+	}
+
+	msg, ok := ctx.View.Chat.GetSelectedMessage()
+	if !ok {
+		return errors.New("select a message to flag with v")
+	}
+
+	channel := ctx.View.Channels.GetSelectedChannel()
+	followUp := components.FollowUpItem{
+		ChannelID:   channel.ID,
+		ChannelName: channel.Name,
+		MessageID:   msg.ID,
+		Name:        msg.Name,
+		Content:     msg.Content,
+	}
+
+	if len(args) == 1 {
+		due, err := parseDueTime(args[0], time.Now())
+		if err != nil {
+			return err
+		}
+		followUp.Due = due
+	}
+
+	if err := ctx.Service.SetFollowUp(followUp); err != nil {
+		return fmt.Errorf("unable to flag message: %v", err)
+	}
+
+	ctx.View.Chat.Selected = ""
+	actionRenderChat(ctx)
+
+	status := "flagged message for follow-up"
+	if !followUp.Due.IsZero() {
+		status = fmt.Sprintf("%s, due %s", status, followUp.Due.Format("01-02 15:04"))
+	}
+	actionToast(ctx, status)
+
+	return nil
+}
+
+// commandNewChannel will create a public channel, e.g.
+// ":new-channel #releases", and select it
+func commandNewChannel(ctx *context.AppContext, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: new-channel <name>")
+	}
+
+	name := strings.ToLower(strings.TrimLeft(args[0], "#"))
+	chanItem, err := ctx.Service.CreateChannel(gocontext.Background(), name)
+	if err != nil {
+		return fmt.Errorf("unable to create %s: %v", name, err)
+	}
+
+	actionSelectNewChannel(ctx, chanItem)
+
+	return nil
+}
+
+// commandDM will open the direct message with a user, e.g. ":dm @alice",
+// and select it. The direct message is created when there hasn't been one
+// with the user yet.
+func commandDM(ctx *context.AppContext, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: dm <user>")
+	}
+
+	name := strings.TrimLeft(args[0], "@")
+	users, err := ctx.Service.SearchUsers(gocontext.Background(), name)
+	if err != nil {
+		return fmt.Errorf("unable to find %s: %v", name, err)
+	}
+
+	var user *service.User
+	for i := range users {
+		if strings.EqualFold(users[i].Name, name) {
+			user = &users[i]
+			break
+		}
+	}
+	if user == nil {
+		return fmt.Errorf("unknown user: %s", name)
+	}
+
+	return actionOpenDirectMessage(ctx, user.ID, user.Name)
+}
+
+// commandLeave will ask to confirm leaving the selected channel, see
+// actionLeaveChannelKey
+func commandLeave(ctx *context.AppContext, args []string) error {
+	channel, err := getLeavableChannel(ctx, args, "leave")
+	if err != nil {
+		return err
+	}
+
+	actionConfirm(ctx, "channel-leave", fmt.Sprintf("leave %s?", channel.Name))
+
+	return nil
+}
+
+// commandArchive will ask to confirm archiving the selected channel, see
+// actionArchiveChannelKey
+func commandArchive(ctx *context.AppContext, args []string) error {
+	channel, err := getLeavableChannel(ctx, args, "archive")
+	if err != nil {
+		return err
+	}
+
+	actionConfirm(ctx, "channel-archive",
+		fmt.Sprintf("archive %s for everyone?", channel.Name),
+	)
+
+	return nil
+}
+
+// getLeavableChannel returns the selected channel when it can be left or
+// archived, which isn't possible for direct messages
+func getLeavableChannel(ctx *context.AppContext, args []string, command string) (components.ChannelItem, error) {
+	if len(args) > 0 {
+		return components.ChannelItem{}, fmt.Errorf("usage: %s", command)
+	}
+	if len(ctx.View.Channels.ChannelItems) == 0 {
+		return components.ChannelItem{}, errors.New("no channel is selected")
+	}
+
+	channel := ctx.View.Channels.GetSelectedChannel()
+	if channel.Type != components.ChannelTypeChannel &&
+		channel.Type != components.ChannelTypeGroup {
+		return channel, fmt.Errorf("unable to %s a direct message", command)
+	}
+
+	return channel, nil
+}
+
+// actionLeaveChannelKey will leave the selected channel when it has been
+// confirmed with y, and remove it from the channels
+func actionLeaveChannelKey(ctx *context.AppContext, key rune) {
+	actionRemoveChannelKey(ctx, key, ctx.Service.LeaveChannel, "leave")
+}
+
+// actionArchiveChannelKey will archive the selected channel when it has
+// been confirmed with y, and remove it from the channels
+func actionArchiveChannelKey(ctx *context.AppContext, key rune) {
+	actionRemoveChannelKey(ctx, key, ctx.Service.ArchiveChannel, "archive")
+}
+
+func actionRemoveChannelKey(
+	ctx *context.AppContext, key rune,
+	remove func(gocontext.Context, string) error, verb string,
+) {
+	actionRenderStatus(ctx)
+
+	if key != 'y' {
+		return
+	}
+
+	channel := ctx.View.Channels.GetSelectedChannel()
+	if err := remove(gocontext.Background(), channel.ID); err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to %s %s: %v", verb, channel.Name, err),
+		)
+		return
+	}
+
+	// The state of the channel isn't kept once it has been left
+	ctx.Conversations.Remove(channel.ID)
+	if chatChannelID == channel.ID {
+		chatChannelID = ""
+	}
+
+	ctx.View.Channels.RemoveChannel(channel.ID)
+	if len(ctx.View.Channels.ChannelItems) > 0 {
+		ctx.View.Channels.GotoPosition(ctx.View.Channels.SelectedChannel)
+		actionChangeChannel(ctx)
+	}
+}
+
+// commandMarkAllAsRead will ask to confirm marking all the channels as read,
+// see actionMarkAllAsReadKey
+func commandMarkAllAsRead(ctx *context.AppContext, args []string) error {
+	var unread int
+	for _, channel := range ctx.View.Channels.ChannelItems {
+		if channel.Notification || channel.Mention {
+			unread++
+		}
+	}
+	if unread == 0 {
+		return errors.New("there are no unread channels")
+	}
+
+	actionConfirm(ctx, "mark-all-read",
+		fmt.Sprintf("mark %d channels as read?", unread),
+	)
+
+	return nil
+}
+
+// actionMarkAllAsReadKey will mark every channel with unread messages as
+// read when it has been confirmed with y, muted and snoozed channels
+// included
+func actionMarkAllAsReadKey(ctx *context.AppContext, key rune) {
+	if key != 'y' {
+		actionRenderStatus(ctx)
+		return
+	}
+
+	var unread []components.ChannelItem
+	for i, channel := range ctx.View.Channels.ChannelItems {
+		if channel.Notification || channel.Mention {
+			unread = append(unread, channel)
+			ctx.View.Channels.MarkAsRead(i)
+		}
+	}
+	actionRenderChannels(ctx)
+	actionRenderStatus(ctx)
+
+	// Marking the channels as read is rate limited, it's done in the
+	// background
+	go func() {
+		for _, channel := range unread {
+			ctx.Service.MarkAsRead(gocontext.Background(), channel)
+		}
+	}()
+}
+
+// actionOpenDirectMessage will select the direct message with the user,
+// it's opened when it isn't part of the channels yet
+func actionOpenDirectMessage(ctx *context.AppContext, userID string, name string) error {
+	// The direct message can already be in the channels
+	for _, channel := range ctx.View.Channels.ChannelItems {
+		if channel.Type == components.ChannelTypeIM && channel.UserID == userID {
+			ctx.View.Channels.GotoChannel(channel.ID)
+			actionChangeChannel(ctx)
+			return nil
+		}
+	}
+
+	chanItem, err := ctx.Service.OpenDirectMessage(gocontext.Background(), userID)
+	if err != nil {
+		return fmt.Errorf("unable to open direct message with %s: %v", name, err)
+	}
+
+	actionSelectNewChannel(ctx, chanItem)
+
+	return nil
+}
+
+// actionSelectNewChannel will add a channel that the user is now a member
+// of to the channels, and load it
+func actionSelectNewChannel(ctx *context.AppContext, chanItem components.ChannelItem) {
+	if !ctx.View.Channels.GotoChannel(chanItem.ID) {
+		ctx.View.Channels.GotoPosition(
+			ctx.View.Channels.AddChannel(chanItem),
+		)
+	}
+
+	actionChangeChannel(ctx)
+}
+
+// parseDueTime returns the due time of a follow-up, which is either a
+// duration from now, e.g. "2h", or a time of day, e.g. "15:04". A time of
+// day that has passed is due tomorrow.
+func parseDueTime(value string, now time.Time) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		if duration <= 0 {
+			return time.Time{}, fmt.Errorf("%s isn't in the future", value)
+		}
+		return now.Add(duration), nil
+	}
+
+	clock, err := time.ParseInLocation("15:04", value, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("unable to parse %s, use e.g. 2h or 15:04", value)
+	}
+
+	due := time.Date(
+		now.Year(), now.Month(), now.Day(),
+		clock.Hour(), clock.Minute(), 0, 0, now.Location(),
+	)
+	if !due.After(now) {
+		due = due.AddDate(0, 0, 1)
+	}
+
+	return due, nil
+}
+
+// commandDigest will show a summary of the messages of the day in the Chat
+// pane, e.g. ":digest today" or ":digest yesterday". It's made from the
+// history that is stored in the persistent cache.
+func commandDigest(ctx *context.AppContext, args []string) error {
+	day := time.Now()
+	if len(args) > 1 {
+		return errors.New("usage: digest [today|yesterday]")
+	}
+	if len(args) == 1 {
+		switch args[0] {
+		case "today":
+		case "yesterday":
+			day = day.AddDate(0, 0, -1)
+		default:
+			return errors.New("usage: digest [today|yesterday]")
+		}
+	}
+
+	digest, err := ctx.Service.GetDigest(gocontext.Background(), day)
+	if err != nil {
+		return err
+	}
+
+	lines := []string{
+		fmt.Sprintf(
+			"Digest of %s, from the history of %d conversations",
+			digest.Day.Format("Monday 2 January"), digest.Conversations,
+		),
+		"",
+		fmt.Sprintf("%d messages, %d of them sent by you", digest.Messages, digest.Sent),
+	}
+
+	if len(digest.Active) > 0 {
+		lines = append(lines, "", "MOST ACTIVE", "")
+		for _, conversation := range digest.Active {
+			lines = append(lines, fmt.Sprintf(
+				"    %-24s%d", conversation.Name, conversation.Messages,
+			))
+		}
+	}
+
+	lines = append(lines, "", fmt.Sprintf("MENTIONS (%d)", len(digest.Mentions)), "")
+	for _, mention := range digest.Mentions {
+		lines = append(lines, fmt.Sprintf(
+			"    %s  %s  <%s> %s",
+			mention.Time.Format("15:04"), mention.ChannelName, mention.Name,
+			strings.Replace(mention.Content, "\n", " ", -1),
+		))
+	}
+
+	actionLeaveConversation(ctx)
+	ctx.View.Chat.SetText(lines)
+	ctx.View.Chat.SetBorderLabel("Digest")
+	actionRenderChat(ctx)
+
+	return nil
+}
+
+// actionToggleChannelType will hide or show the channels of channelType in
+// the sidebar, the first channel that is shown is loaded when the selected
+// channel is hidden
+func actionToggleChannelType(ctx *context.AppContext, channelType string) {
+	selected := ctx.View.Channels.SelectedChannel
+	hidden := !ctx.View.Channels.IsTypeHidden(channelType)
+	if err := ctx.View.Channels.SetTypeHidden(channelType, hidden); err != nil {
+		ctx.View.Debug.Println(err.Error())
+		return
+	}
+
+	if ctx.View.Channels.SelectedChannel != selected {
+		actionChangeChannel(ctx)
+	}
+	actionRenderChannels(ctx)
+}
+
+func actionToggleChannels(ctx *context.AppContext) {
+	actionToggleChannelType(ctx, components.ChannelTypeChannel)
+}
+
+func actionToggleGroups(ctx *context.AppContext) {
+	actionToggleChannelType(ctx, components.ChannelTypeGroup)
+}
+
+func actionToggleMpIMs(ctx *context.AppContext) {
+	actionToggleChannelType(ctx, components.ChannelTypeMpIM)
+}
+
+func actionToggleIMs(ctx *context.AppContext) {
+	actionToggleChannelType(ctx, components.ChannelTypeIM)
+}
+
+// commandDeactivated will toggle whether the direct messages with users
+// that have been deactivated are shown
+func commandDeactivated(ctx *context.AppContext, args []string) error {
+	ctx.View.Channels.ShowDeactivated = !ctx.View.Channels.ShowDeactivated
+	actionRenderChannels(ctx)
+
+	return nil
+}
+
+// commandSnooze will suppress the badges and notifications of a channel
+// for a duration, e.g. ":snooze #alerts 4h". Without a channel the selected
+// channel is snoozed, and a duration of 0 ends the snooze.
+func commandSnooze(ctx *context.AppContext, args []string) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New("usage: snooze [#channel] <duration>")
+	}
+
+	duration, err := time.ParseDuration(args[len(args)-1])
+	if err != nil || duration < 0 {
+		return fmt.Errorf("invalid duration: %s", args[len(args)-1])
+	}
+
+	index := ctx.View.Channels.SelectedChannel
+	if len(args) == 2 {
+		index = -1
+		name := strings.TrimLeft(args[0], "#@")
+		for i, channel := range ctx.View.Channels.ChannelItems {
+			if channel.Name == name {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return fmt.Errorf("unknown channel: %s", args[0])
+		}
+	}
+
+	if len(ctx.View.Channels.ChannelItems) == 0 {
+		return nil
+	}
+
+	channelID := ctx.View.Channels.ChannelItems[index].ID
+	if timer, ok := snoozeTimers[channelID]; ok {
+		timer.Stop()
+		delete(snoozeTimers, channelID)
+	}
+
+	ctx.View.Channels.ChannelItems[index].SnoozedUntil = time.Now().Add(duration)
+	if duration > 0 {
+		snoozeTimers[channelID] = time.AfterFunc(duration, func() {
+			actionRenderChannels(ctx)
+			actionRenderStatus(ctx)
+		})
+	}
+
+	actionRenderChannels(ctx)
+	actionRenderStatus(ctx)
+
+	return nil
+}
+
+func actionGetMessages(ctx *context.AppContext) {
+	channelID := ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID
+	count, days := actionGetHistoryWindow(ctx, channelID)
+
+	msgs, _, err := ctx.Service.GetMessages(
+		gocontext.Background(),
+		channelID,
+		count,
+		days,
+	)
+	if err != nil {
+		termbox.Close()
+		log.Println(err)
+		os.Exit(0)
+	}
+
+	ctx.View.Chat.SetMessages(msgs)
+
+	termui.Render(ctx.View.Chat)
+}
+
+// actionMoveCursorUpChannels will move the channel cursor up
+// without loading the channel
+func actionMoveCursorUpChannels(ctx *context.AppContext) {
+	ctx.View.Channels.MoveCursorUp()
+	termui.Render(ctx.View.Channels)
+}
+
+// actionMoveCursorDownChannels will move the channel cursor down
+// without loading the channel
+func actionMoveCursorDownChannels(ctx *context.AppContext) {
+	ctx.View.Channels.MoveCursorDown()
+	termui.Render(ctx.View.Channels)
+}
+
+func actionMoveCursorTopChannels(ctx *context.AppContext) {
+	ctx.View.Channels.MoveCursorTop()
+	termui.Render(ctx.View.Channels)
+}
+
+func actionMoveCursorBottomChannels(ctx *context.AppContext) {
+	ctx.View.Channels.MoveCursorBottom()
+	termui.Render(ctx.View.Channels)
+}
+
+func actionSearchNextChannels(ctx *context.AppContext) {
+	ctx.View.Channels.SearchNext()
+	termui.Render(ctx.View.Channels)
+}
+
+func actionSearchPrevChannels(ctx *context.AppContext) {
+	ctx.View.Channels.SearchPrev()
+	termui.Render(ctx.View.Channels)
+}
+
+// actionSearchNext will jump to the next match of the search of the
+// messages when there is one, and else to the next match of the search of
+// the channels
+func actionSearchNext(ctx *context.AppContext) {
+	if ctx.View.Chat.HasFind() {
+		actionFindNext(ctx, 1)
+		return
+	}
+	actionSearchNextChannels(ctx)
+}
+
+// actionSearchPrev is the reverse of actionSearchNext
+func actionSearchPrev(ctx *context.AppContext) {
+	if ctx.View.Chat.HasFind() {
+		actionFindNext(ctx, -1)
+		return
+	}
+	actionSearchPrevChannels(ctx)
+}
+
+func actionJumpChannels(ctx *context.AppContext) {
+	ctx.View.Channels.Jump()
+	termui.Render(ctx.View.Channels)
+}
+
+// actionToggleEmoji will toggle whether emoji are rendered, the selected
+// channel is loaded again to show the change
+func actionToggleEmoji(ctx *context.AppContext) {
+	ctx.Config.Emoji = !ctx.Config.Emoji
+	actionChangeChannel(ctx)
+}
+
+// actionToggleEmojiChannel will toggle whether emoji are rendered in the
+// selected channel only
+func actionToggleEmojiChannel(ctx *context.AppContext) {
+	channel := ctx.View.Channels.GetSelectedChannel()
+	ctx.Config.SetEmojiChannel(
+		channel.ID, !ctx.Config.EmojiEnabled(channel.ID, channel.Name),
+	)
+	actionChangeChannel(ctx)
+}
+
+// actionMoveUpChannels will move the selected channel above the channel
+// before it, the order is persisted
+func actionMoveUpChannels(ctx *context.AppContext) {
+	actionSetChannelOrder(ctx, ctx.View.Channels.MoveChannelUp())
+}
+
+// actionMoveDownChannels will move the selected channel below the channel
+// after it, the order is persisted
+func actionMoveDownChannels(ctx *context.AppContext) {
+	actionSetChannelOrder(ctx, ctx.View.Channels.MoveChannelDown())
+}
+
+func actionSetChannelOrder(ctx *context.AppContext, channelIDs []string) {
+	if channelIDs == nil {
+		return
+	}
+
+	if err := ctx.Service.SetChannelOrder(channelIDs); err != nil {
+		ctx.View.Debug.Println(
+			err.Error(),
+		)
+	}
+
+	termui.Render(ctx.View.Channels)
+}
+
+// actionJumpNextChannels will move the channel cursor to the next channel
+// with unread messages
+func actionJumpNextChannels(ctx *context.AppContext) {
+	ctx.View.Channels.JumpNext()
+	termui.Render(ctx.View.Channels)
+}
+
+// actionJumpPreviousChannels will move the channel cursor to the previous
+// channel with unread messages
+func actionJumpPreviousChannels(ctx *context.AppContext) {
+	ctx.View.Channels.JumpPrevious()
+	termui.Render(ctx.View.Channels)
+}
+
+// actionSetMark will wait for the next key, and uses it to set a mark on
+// the selected channel
+func actionSetMark(ctx *context.AppContext) {
+	ctx.PendingAction = "mark-set"
+}
+
+// actionJumpMark will wait for the next key, and uses it to jump to the
+// channel of that mark
+func actionJumpMark(ctx *context.AppContext) {
+	ctx.PendingAction = "mark-jump"
+}
+
+func actionSetMarkKey(ctx *context.AppContext, key rune) {
+	if !unicode.IsLetter(key) {
+		return
+	}
+
+	err := ctx.Service.SetMark(
+		string(key),
+		ctx.View.Channels.GetSelectedChannel().ID,
+	)
+	if err != nil {
+		ctx.View.Debug.Println(
+			err.Error(),
+		)
+	}
+}
+
+// actionJumpMarkKey will load the channel of the mark. Like vim, pressing
+// the jump key twice will jump to the first channel with a notification.
+func actionJumpMarkKey(ctx *context.AppContext, key rune) {
+	if key == '\'' {
+		actionJumpChannels(ctx)
+		return
+	}
+
+	channelID, ok := ctx.Service.GetMark(string(key))
+	if !ok {
+		return
+	}
+
+	if ctx.View.Channels.GotoChannel(channelID) {
+		actionChangeChannel(ctx)
+	}
+}
+
+// actionSetSlot will wait for the next key, and assigns the selected channel
+// to the slot of that number
+func actionSetSlot(ctx *context.AppContext) {
+	ctx.PendingAction = "slot-set"
+}
+
+// actionSetSlotKey will assign the selected channel to the slot of key, the
+// assignment is persisted like a mark and takes precedence over the slots
+// in the config
+func actionSetSlotKey(ctx *context.AppContext, key rune) {
+	if key < '1' || key > '0'+slotCount {
+		return
+	}
+
+	err := ctx.Service.SetMark(
+		slotMark(string(key)),
+		ctx.View.Channels.GetSelectedChannel().ID,
+	)
+	if err != nil {
+		ctx.View.Debug.Println(
+			err.Error(),
+		)
+	}
+}
+
+// actionJumpSlot will load the channel that has been assigned to the slot,
+// at runtime or in the config by channel name or id
+func actionJumpSlot(ctx *context.AppContext, slot string) {
+	channelID, ok := ctx.Service.GetMark(slotMark(slot))
+	if !ok {
+		name, ok := ctx.Config.Slots[slot]
+		if !ok {
+			return
+		}
+
+		channelID = name
+		for _, channel := range ctx.View.Channels.ChannelItems {
+			if channel.Name == name {
+				channelID = channel.ID
+				break
+			}
+		}
+	}
+
+	if ctx.View.Channels.GotoChannel(channelID) {
+		actionChangeChannel(ctx)
+	}
+}
+
+// slotMark returns the name of the mark a slot assignment is stored as,
+// which can't be set as a regular mark
+func slotMark(slot string) string {
+	return "slot-" + slot
+}
+
+// actionRenderChannels will render the Channels component, unless it has
+// been replaced by the Browser component in the sidebar
+func actionRenderChannels(ctx *context.AppContext) {
+	if isBrowsing(ctx) {
+		return
+	}
+	termui.Render(ctx.View.Channels)
+}
+
+// isChatReplaced returns whether the Chat component has been replaced by
+// the Files, Mentions, FollowUps, Members or EmojiPicker component
+func isChatReplaced(ctx *context.AppContext) bool {
+	return ctx.Mode == context.FilesMode || ctx.Mode == context.MentionsMode ||
+		ctx.Mode == context.FollowUpsMode || ctx.Mode == context.MembersMode ||
+		ctx.Mode == context.ReactionMode
+}
+
+// actionRenderChat will render the Chat component, unless it has been
+// replaced, see isChatReplaced
+func actionRenderChat(ctx *context.AppContext) {
+	if isChatReplaced(ctx) {
+		return
+	}
+	termui.Render(ctx.View.Chat)
+
+	// The Completion, Switcher and Info popups are shown on top of the
+	// Chat pane
+	if ctx.View.Completion.IsShown() {
+		termui.Render(ctx.View.Completion)
+	}
+	if ctx.Mode == context.SwitcherMode {
+		termui.Render(ctx.View.Switcher)
+	} else if ctx.Mode == context.InfoMode {
+		termui.Render(ctx.View.Info)
+	} else if ctx.Mode == context.TasksMode {
+		termui.Render(ctx.View.Tasks)
+	}
+	if ctx.View.Confirm.IsShown() {
+		termui.Render(ctx.View.Confirm)
+	}
+	actionRenderToast(ctx)
+}
+
+// actionRenderStatus will show the number of channels with unread messages
+// and mentions in the status bar
+func actionRenderStatus(ctx *context.AppContext) {
+	unread, mentions := ctx.View.Channels.GetUnreadSummary()
+
+	var status string
+	if unread > 0 {
+		status = fmt.Sprintf("%d unread", unread)
+	}
+	if mentions == 1 {
+		status = fmt.Sprintf("%s · 1 mention", status)
+	} else if mentions > 1 {
+		status = fmt.Sprintf("%s · %d mentions", status, mentions)
+	}
+
+	ctx.View.Input.SetStatus(status)
+	termui.Render(ctx.View.Input)
+
+	actionRenderTitle(ctx)
+}
+
+// actionRenderSegments will show the enabled segments at the right of the
+// status bar, e.g. "@alice on acme · 14:32"
+func actionRenderSegments(ctx *context.AppContext) {
+	var segments []string
+	if ctx.Config.StatusUser {
+		user := fmt.Sprintf("@%s", ctx.Service.GetCurrentUsername())
+		if team := ctx.Service.GetCurrentTeamName(); team != "" {
+			user = fmt.Sprintf("%s on %s", user, team)
+		}
+		segments = append(segments, user)
+	}
+	if ctx.Config.StatusClock != "" {
+		segments = append(segments, time.Now().Format(ctx.Config.StatusClock))
+	}
+
+	ctx.View.Input.SetSegments(strings.Join(segments, " · "))
+	termui.Render(ctx.View.Input)
+}
+
+// actionRunClock will keep the clock in the status bar up to date, it's
+// updated every second when the seconds are shown. This is disabled when
+// status_clock isn't set.
+func actionRunClock(ctx *context.AppContext) {
+	if ctx.Config.StatusClock == "" {
+		return
+	}
+
+	interval := time.Minute
+	if strings.Contains(ctx.Config.StatusClock, "05") {
+		interval = time.Second
+	}
+
+	for {
+		now := time.Now()
+		time.Sleep(now.Truncate(interval).Add(interval).Sub(now))
+		actionRenderSegments(ctx)
+	}
+}
+
+// actionRenderTitle will set the title of the terminal window to the
+// workspace, the selected channel and the number of unread channels, e.g.
+// "slack-term — acme (#general) [2 unread]"
+func actionRenderTitle(ctx *context.AppContext) {
+	title := "slack-term"
+	if team := ctx.Service.GetCurrentTeamName(); team != "" {
+		title = fmt.Sprintf("%s — %s", title, team)
+	}
+
+	if len(ctx.View.Channels.ChannelItems) > 0 {
+		channel := ctx.View.Channels.GetSelectedChannel()
+		switch channel.Type {
+		case components.ChannelTypeChannel, components.ChannelTypeGroup:
+			title = fmt.Sprintf("%s (#%s)", title, channel.Name)
+		default:
+			title = fmt.Sprintf("%s (%s)", title, channel.Name)
+		}
+	}
+
+	if unread, _ := ctx.View.Channels.GetUnreadSummary(); unread > 0 {
+		title = fmt.Sprintf("%s [%d unread]", title, unread)
+	}
+
+	views.SetTitle(title)
+}
+
+// isBrowsing returns whether the Browser has replaced the Channels
+// component in the sidebar
+func isBrowsing(ctx *context.AppContext) bool {
+	return ctx.Mode == context.BrowseMode || ctx.Mode == context.BrowseSearchMode
+}
+
+// actionBrowseMode will replace the Channels component in the sidebar with
+// the public channels that can be joined. The first page of channels is
+// loaded when the Browser is opened for the first time.
+func actionBrowseMode(ctx *context.AppContext) {
+	browserCtx, browserCancel = gocontext.WithCancel(gocontext.Background())
+
+	if len(ctx.View.Browser.Loaded) == 0 {
+		actionLoadBrowser(ctx, 1)
+	}
+
+	ctx.Mode = context.BrowseMode
+	ctx.View.Mode.SetBrowseMode()
+
+	actionRedrawGrid(ctx, ctx.View.Threads.HasThreads(), ctx.Debug)
+}
+
+// actionLoadBrowser will load pages of public channels into the Browser
+// until it shows at least count channels, or all pages have been loaded
+func actionLoadBrowser(ctx *context.AppContext, count int) {
+	browser := ctx.View.Browser
+	for len(browser.ChannelItems) < count && !browser.Complete {
+		chans, cursor, err := ctx.Service.GetPublicChannels(browserCtx, browser.NextCursor)
+		if err != nil {
+			ctx.View.Debug.Println(
+				err.Error(),
+			)
+			return
+		}
+
+		browser.AddPage(chans, cursor)
+	}
+}
+
+func actionMoveCursorUpBrowser(ctx *context.AppContext) {
+	ctx.View.Browser.MoveCursorUp()
+	termui.Render(ctx.View.Browser)
+}
+
+// actionMoveCursorDownBrowser will move the cursor down, and load the next
+// page when the cursor reaches the last loaded channel
+func actionMoveCursorDownBrowser(ctx *context.AppContext) {
+	actionLoadBrowser(ctx, ctx.View.Browser.SelectedChannel+2)
+	ctx.View.Browser.MoveCursorDown()
+	termui.Render(ctx.View.Browser)
+}
+
+func actionMoveCursorTopBrowser(ctx *context.AppContext) {
+	ctx.View.Browser.MoveCursorTop()
+	termui.Render(ctx.View.Browser)
+}
+
+func actionMoveCursorBottomBrowser(ctx *context.AppContext) {
+	ctx.View.Browser.MoveCursorBottom()
+	termui.Render(ctx.View.Browser)
+}
+
+// actionPeekBrowser will show the recent history of the selected channel
+// in the Browser, without joining it
+func actionPeekBrowser(ctx *context.AppContext) {
+	if len(ctx.View.Browser.ChannelItems) == 0 {
+		return
+	}
+
+	channel := ctx.View.Browser.GetSelectedChannel()
+
+	// We're fetching a larger window than usual, because the channels
+	// we're not a member of are likely to be less active
+	msgs, _, err := ctx.Service.GetMessages(
+		newChannelContext(),
+		channel.ID,
+		ctx.View.Chat.GetMaxItems(),
+		30,
+	)
+	if err != nil {
+		ctx.View.Debug.Println(
+			err.Error(),
+		)
+		return
+	}
+
+	actionLeaveConversation(ctx)
+
+	ctx.View.Chat.ClearMessages()
+	ctx.View.Chat.SetMessages(msgs)
+	ctx.View.Chat.SetHeader(channel)
+	ctx.View.Chat.SetBorderLabel(
+		fmt.Sprintf("%s (preview)", channel.GetName()),
+	)
+
+	termui.Render(ctx.View.Chat)
+}
+
+// actionJoinBrowser will join the selected channel in the Browser, add it
+// to the Channels component and load it
+func actionJoinBrowser(ctx *context.AppContext) {
+	if len(ctx.View.Browser.ChannelItems) == 0 {
+		return
+	}
+
+	channel := ctx.View.Browser.GetSelectedChannel()
+
+	chanItem, err := ctx.Service.JoinChannel(browserCtx, channel.ID)
+	if err != nil {
+		ctx.View.Debug.Println(
+			err.Error(),
+		)
+		return
+	}
+
+	ctx.View.Browser.RemoveChannel(channel.ID)
+	ctx.View.Channels.GotoPosition(
+		ctx.View.Channels.AddChannel(chanItem),
+	)
+
+	actionCloseBrowser(ctx)
+}
+
+func actionBrowseSearchMode(ctx *context.AppContext) {
+	ctx.Mode = context.BrowseSearchMode
+	ctx.View.Mode.SetSearchMode()
+}
+
+// actionSearchBrowser will filter the channels in the Browser by the input
+// of the user. Like actionSearch it waits until the typing is paused, and
+// then loads pages until the Browser is filled with matches.
+func actionSearchBrowser(ctx *context.AppContext, key rune) {
+	actionInput(ctx.View, key)
+
+	go func() {
+		if scrollTimer != nil {
+			scrollTimer.Stop()
+		}
+
+		scrollTimer = time.NewTimer(time.Second / 4)
+		<-scrollTimer.C
+
+		ctx.View.Browser.Filter(ctx.View.Input.GetText())
+		actionLoadBrowser(ctx, ctx.View.Browser.List.InnerHeight())
+		termui.Render(ctx.View.Browser)
+	}()
+}
+
+// actionBrowseSearchDone will keep the filter of the Browser, and return
+// to browse mode
+func actionBrowseSearchDone(ctx *context.AppContext) {
+	ctx.View.Input.Clear()
+	termui.Render(ctx.View.Input)
+
+	ctx.Mode = context.BrowseMode
+	ctx.View.Mode.SetBrowseMode()
+}
+
+// actionBrowseSearchClear will remove the filter of the Browser, and return
+// to browse mode
+func actionBrowseSearchClear(ctx *context.AppContext) {
+	ctx.View.Browser.Filter("")
+	termui.Render(ctx.View.Browser)
+
+	actionBrowseSearchDone(ctx)
+}
+
+// actionCloseBrowser will restore the Channels component in the sidebar
+// and load the selected channel
+func actionCloseBrowser(ctx *context.AppContext) {
+	if browserCancel != nil {
+		browserCancel()
+	}
+
+	actionCommandMode(ctx)
+	actionRedrawGrid(ctx, ctx.View.Threads.HasThreads(), ctx.Debug)
+	actionChangeChannel(ctx)
+}
+
+// actionFilesMode will replace the Chat component with the files that have
+// been shared most recently in the selected channel
+func actionFilesMode(ctx *context.AppContext) {
+	channel := ctx.View.Channels.GetSelectedChannel()
+
+	files, err := ctx.Service.GetFiles(channelCtx, channel.ID)
+	if err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to get files: %v", err),
+		)
+		return
+	}
+
+	ctx.View.Files.SetFiles(files)
+	ctx.View.Files.List.BorderLabel = fmt.Sprintf(
+		"Files of %s", channel.GetChannelName(),
+	)
+
+	ctx.Mode = context.FilesMode
+	ctx.View.Mode.SetFilesMode()
+	actionRedrawGrid(ctx, ctx.View.Threads.HasThreads(), ctx.Debug)
+}
+
+func actionMoveCursorUpFiles(ctx *context.AppContext) {
+	ctx.View.Files.ClosePreview()
+	ctx.View.Files.MoveCursorUp()
+	termui.Render(ctx.View.Files)
+}
+
+func actionMoveCursorDownFiles(ctx *context.AppContext) {
+	ctx.View.Files.ClosePreview()
+	ctx.View.Files.MoveCursorDown()
+	termui.Render(ctx.View.Files)
+}
+
+func actionMoveCursorTopFiles(ctx *context.AppContext) {
+	ctx.View.Files.ClosePreview()
+	ctx.View.Files.MoveCursorTop()
+	termui.Render(ctx.View.Files)
+}
+
+func actionMoveCursorBottomFiles(ctx *context.AppContext) {
+	ctx.View.Files.ClosePreview()
+	ctx.View.Files.MoveCursorBottom()
+	termui.Render(ctx.View.Files)
+}
+
+// actionPreviewFile will show the details of the selected file, and the
+// first lines of text files
+func actionPreviewFile(ctx *context.AppContext) {
+	if !ctx.View.Files.HasFiles() {
+		return
+	}
+
+	ctx.View.Files.ShowPreview()
+	termui.Render(ctx.View.Files)
+}
+
+// actionDownloadFile will download the selected file into the download_dir,
+// which defaults to the Downloads directory in the home directory
+func actionDownloadFile(ctx *context.AppContext) {
+	if !ctx.View.Files.HasFiles() {
+		return
+	}
+
+	file := ctx.View.Files.GetSelectedFile()
+
+	go func() {
+		path, err := downloadFile(ctx, file)
+		if err == gocontext.Canceled {
+			return
+		}
+		if err != nil {
+			ctx.View.Debug.Println(
+				fmt.Sprintf("unable to download %s: %v", file.Name, err),
+			)
+			return
+		}
+
+		actionToast(ctx, fmt.Sprintf("downloaded %s", path))
+	}()
+}
+
+// actionOpenFile will download the selected file, and open it with the
+// open_command, e.g. to play audio and video files
+func actionOpenFile(ctx *context.AppContext) {
+	if !ctx.View.Files.HasFiles() {
+		return
+	}
+
+	file := ctx.View.Files.GetSelectedFile()
+	if ctx.Config.OpenCommand == "" {
+		ctx.View.Input.SetStatus("set open_command in the config file to open files")
+		termui.Render(ctx.View.Input)
+		return
+	}
+
+	go func() {
+		path, err := downloadFile(ctx, file)
+		if err == gocontext.Canceled {
+			return
+		}
+		if err == nil {
+			err = openWithCommand(ctx.Config.OpenCommand, path)
+		}
+		if err != nil {
+			ctx.View.Debug.Println(
+				fmt.Sprintf("unable to open %s: %v", file.Name, err),
+			)
+			return
+		}
+
+		actionToast(ctx, fmt.Sprintf("opened %s", path))
+	}()
+}
+
+// openWithCommand will open the path or url with the command, it doesn't
+// wait for the command to finish. The target is passed as an argument of
+// the command, so that it isn't interpreted by the shell.
+func openWithCommand(command string, target string) error {
+	cmd := exec.Command("sh", "-c", command+` "$1"`, "sh", target)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+
+	return nil
+}
+
+// progressWriter is a writer that calls progress with the percentage of
+// the total that has been written, whenever it changes. Writing fails once
+// ctx is cancelled, to stop the download.
+type progressWriter struct {
+	ctx      gocontext.Context
+	w        io.Writer
+	total    int
+	written  int
+	percent  int
+	progress func(percent int)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	if err := p.ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	n, err := p.w.Write(b)
+
+	p.written += n
+	if p.total > 0 {
+		if percent := p.written * 100 / p.total; percent != p.percent {
+			p.percent = percent
+			p.progress(percent)
+		}
+	}
+
+	return n, err
+}
+
+// downloadFile will download the file into the download_dir, it returns
+// the path of the file. The progress of the download is shown in the Files
+// pane.
+func downloadFile(ctx *context.AppContext, file components.FileItem) (string, error) {
+	dir := ctx.Config.DownloadDir
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, "Downloads")
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	// Files aren't overwritten, a number is added to the name instead
+	ext := filepath.Ext(file.Name)
+	base := strings.TrimSuffix(filepath.Base(file.Name), ext)
+	path := filepath.Join(dir, base+ext)
+	for i := 1; ; i++ {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+		path = filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, i, ext))
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+
+	taskCtx, t := actionStartTask(
+		ctx, ctx.View.FilesSpinner, gocontext.Background(),
+		fmt.Sprintf("downloading %s", file.Name),
+	)
+	err = ctx.Service.DownloadFile(taskCtx, file, &progressWriter{
+		ctx:   taskCtx,
+		w:     f,
+		total: file.Size,
+		progress: func(percent int) {
+			actionSetTaskProgress(ctx, t, fmt.Sprintf("%d%%", percent))
+		},
+	})
+	actionStopTask(ctx, t)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	return path, nil
+}
+
+// actionDeleteFile will ask to confirm the deletion of the selected file,
+// which is deleted by actionDeleteFileKey
+func actionDeleteFile(ctx *context.AppContext) {
+	if !ctx.View.Files.HasFiles() {
+		return
+	}
+
+	actionConfirm(ctx, "files-delete",
+		fmt.Sprintf("delete %s?", ctx.View.Files.GetSelectedFile().Name),
+	)
+}
+
+// actionDeleteFileKey will delete the selected file when the deletion has
+// been confirmed with y
+func actionDeleteFileKey(ctx *context.AppContext, key rune) {
+	actionRenderStatus(ctx)
+
+	if key != 'y' {
+		return
+	}
+
+	file := ctx.View.Files.GetSelectedFile()
+	if err := ctx.Service.DeleteFile(gocontext.Background(), file.ID); err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to delete %s: %v", file.Name, err),
+		)
+		return
+	}
+
+	ctx.View.Files.RemoveFile(file.ID)
+	termui.Render(ctx.View.Files)
+}
+
+// actionCloseFiles will close the preview of a file, or when no file is
+// previewed it will restore the Chat component
+func actionCloseFiles(ctx *context.AppContext) {
+	if ctx.View.Files.Preview != nil {
+		ctx.View.Files.ClosePreview()
+		termui.Render(ctx.View.Files)
+		return
+	}
+
+	actionCommandMode(ctx)
+	actionRenderStatus(ctx)
+	actionRedrawGrid(ctx, ctx.View.Threads.HasThreads(), ctx.Debug)
+}
+
+// actionMentionsMode will replace the Chat component with the messages that
+// mention the user most recently
+func actionMentionsMode(ctx *context.AppContext) {
+	mentions, err := ctx.Service.GetMentions(gocontext.Background())
+	if err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to get mentions: %v", err),
+		)
+		return
+	}
+
+	// Show the channels by the names they have in the sidebar, these are
+	// the names of the users for direct messages
+	for i, mention := range mentions {
+		for _, channel := range ctx.View.Channels.ChannelItems {
+			if channel.ID == mention.ChannelID {
+				mentions[i].ChannelName = channel.Name
+				break
+			}
+		}
+	}
+
+	ctx.View.Mentions.SetMentions(mentions)
+
+	ctx.Mode = context.MentionsMode
+	ctx.View.Mode.SetMentionsMode()
+	actionRedrawGrid(ctx, ctx.View.Threads.HasThreads(), ctx.Debug)
+}
+
+func actionMoveCursorUpMentions(ctx *context.AppContext) {
+	ctx.View.Mentions.MoveCursorUp()
+	termui.Render(ctx.View.Mentions)
+}
+
+func actionMoveCursorDownMentions(ctx *context.AppContext) {
+	ctx.View.Mentions.MoveCursorDown()
+	termui.Render(ctx.View.Mentions)
+}
+
+func actionMoveCursorTopMentions(ctx *context.AppContext) {
+	ctx.View.Mentions.MoveCursorTop()
+	termui.Render(ctx.View.Mentions)
+}
+
+func actionMoveCursorBottomMentions(ctx *context.AppContext) {
+	ctx.View.Mentions.MoveCursorBottom()
+	termui.Render(ctx.View.Mentions)
+}
+
+// actionJumpMention will load the channel of the selected mention, and
+// scroll to the message when it's part of the loaded history
+func actionJumpMention(ctx *context.AppContext) {
+	if !ctx.View.Mentions.HasMentions() {
+		return
+	}
+
+	mention := ctx.View.Mentions.GetSelectedMention()
+	if !ctx.View.Channels.GotoChannel(mention.ChannelID) {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("%s isn't in the list of channels", mention.ChannelName),
+		)
+		return
+	}
+
+	actionCloseMentions(ctx)
+	actionLoadChannel(ctx, func() {
+		if ctx.View.Chat.ScrollToMessage(mention.MessageID) {
+			termui.Render(ctx.View.Chat)
+		}
+	})
+}
+
+// actionCloseMentions will restore the Chat component
+func actionCloseMentions(ctx *context.AppContext) {
+	actionCommandMode(ctx)
+	actionRedrawGrid(ctx, ctx.View.Threads.HasThreads(), ctx.Debug)
+}
+
+// actionFollowUpsMode will replace the Chat component with the messages
+// that have been flagged for follow-up
+func actionFollowUpsMode(ctx *context.AppContext) {
+	followUps, err := ctx.Service.GetFollowUps()
+	if err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to get follow-ups: %v", err),
+		)
+		return
+	}
+
+	// Show the channels by the names they have in the sidebar, these are
+	// the names of the users for direct messages
+	for i, followUp := range followUps {
+		for _, channel := range ctx.View.Channels.ChannelItems {
+			if channel.ID == followUp.ChannelID {
+				followUps[i].ChannelName = channel.Name
+				break
+			}
+		}
+	}
+
+	ctx.View.FollowUps.SetFollowUps(followUps)
+
+	ctx.Mode = context.FollowUpsMode
+	ctx.View.Mode.SetFollowUpsMode()
+	actionRedrawGrid(ctx, ctx.View.Threads.HasThreads(), ctx.Debug)
+}
+
+func actionMoveCursorUpFollowUps(ctx *context.AppContext) {
+	ctx.View.FollowUps.MoveCursorUp()
+	termui.Render(ctx.View.FollowUps)
+}
+
+func actionMoveCursorDownFollowUps(ctx *context.AppContext) {
+	ctx.View.FollowUps.MoveCursorDown()
+	termui.Render(ctx.View.FollowUps)
+}
+
+func actionMoveCursorTopFollowUps(ctx *context.AppContext) {
+	ctx.View.FollowUps.MoveCursorTop()
+	termui.Render(ctx.View.FollowUps)
+}
+
+func actionMoveCursorBottomFollowUps(ctx *context.AppContext) {
+	ctx.View.FollowUps.MoveCursorBottom()
+	termui.Render(ctx.View.FollowUps)
+}
+
+// actionJumpFollowUp will load the channel of the selected follow-up, and
+// scroll to the message when it's part of the loaded history
+func actionJumpFollowUp(ctx *context.AppContext) {
+	if !ctx.View.FollowUps.HasFollowUps() {
+		return
+	}
+
+	followUp := ctx.View.FollowUps.GetSelectedFollowUp()
+	if !ctx.View.Channels.GotoChannel(followUp.ChannelID) {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("%s isn't in the list of channels", followUp.ChannelName),
+		)
+		return
+	}
+
+	actionCloseMentions(ctx)
+	actionLoadChannel(ctx, func() {
+		if ctx.View.Chat.ScrollToMessage(followUp.MessageID) {
+			termui.Render(ctx.View.Chat)
+		}
+	})
+}
+
+// actionRemoveFollowUp will remove the flag of the selected follow-up, when
+// it has been followed up on
+func actionRemoveFollowUp(ctx *context.AppContext) {
+	if !ctx.View.FollowUps.HasFollowUps() {
+		return
+	}
+
+	followUp := ctx.View.FollowUps.GetSelectedFollowUp()
+	if err := ctx.Service.RemoveFollowUp(followUp.ChannelID, followUp.MessageID); err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to remove follow-up: %v", err),
+		)
+		return
+	}
+
+	ctx.View.FollowUps.RemoveFollowUp(followUp.ChannelID, followUp.MessageID)
+	termui.Render(ctx.View.FollowUps)
+}
+
+// actionMembersMode will replace the Chat component with the members of
+// the selected channel, the first page of them is loaded
+func actionMembersMode(ctx *context.AppContext) {
+	if len(ctx.View.Channels.ChannelItems) == 0 {
+		return
+	}
+
+	channel := ctx.View.Channels.GetSelectedChannel()
+	ctx.View.Members.Reset(channel.ID)
+	ctx.View.Members.List.BorderLabel = fmt.Sprintf("Members of %s", channel.GetName())
+	actionLoadMembers(ctx, 1)
+
+	ctx.Mode = context.MembersMode
+	ctx.View.Mode.SetMembersMode()
+	actionRedrawGrid(ctx, ctx.View.Threads.HasThreads(), ctx.Debug)
+}
+
+// actionLoadMembers will load pages of members into the Members component
+// until it holds count members, or all of them have been loaded. The
+// members get the last known presence of the users.
+func actionLoadMembers(ctx *context.AppContext, count int) {
+	view := ctx.View.Members
+	for len(view.MemberItems) < count && !view.Complete {
+		page, cursor, err := ctx.Service.GetMembersPage(
+			gocontext.Background(), view.ChannelID, view.NextCursor,
+		)
+		if err != nil {
+			ctx.View.Debug.Println(
+				fmt.Sprintf("unable to get members: %v", err),
+			)
+			return
+		}
+
+		presenceMu.Lock()
+		for i, member := range page {
+			if userPresence, ok := presence[member.UserID]; ok {
+				page[i].Presence = userPresence
+			}
+		}
+		presenceMu.Unlock()
+
+		view.AddPage(page, cursor)
+	}
+}
+
+func actionMoveCursorUpMembers(ctx *context.AppContext) {
+	ctx.View.Members.MoveCursorUp()
+	termui.Render(ctx.View.Members)
+}
+
+// actionMoveCursorDownMembers will move the cursor down, and load the next
+// page when the cursor reaches the last loaded member
+func actionMoveCursorDownMembers(ctx *context.AppContext) {
+	actionLoadMembers(ctx, ctx.View.Members.Selected+2)
+	ctx.View.Members.MoveCursorDown()
+	termui.Render(ctx.View.Members)
+}
+
+func actionMoveCursorTopMembers(ctx *context.AppContext) {
+	ctx.View.Members.MoveCursorTop()
+	termui.Render(ctx.View.Members)
+}
+
+func actionMoveCursorBottomMembers(ctx *context.AppContext) {
+	ctx.View.Members.MoveCursorBottom()
+	termui.Render(ctx.View.Members)
+}
+
+// actionOpenMemberDM will restore the Chat component, and select the
+// direct message with the selected member
+func actionOpenMemberDM(ctx *context.AppContext) {
+	if !ctx.View.Members.HasMembers() {
+		return
+	}
+
+	member := ctx.View.Members.GetSelectedMember()
+	actionCloseMentions(ctx)
+
+	if err := actionOpenDirectMessage(ctx, member.UserID, member.Name); err != nil {
+		ctx.View.Input.SetStatus(err.Error())
+		termui.Render(ctx.View.Input)
+	}
+}
+
+// actionFlagMessage will open the command line with the flag command, to
+// flag the selected message for follow-up with an optional due time
+func actionFlagMessage(ctx *context.AppContext) {
+	if _, ok := ctx.View.Chat.GetSelectedMessage(); !ok {
+		return
+	}
+
+	actionCommandLineMode(ctx)
+	ctx.View.Input.Clear()
+	ctx.View.Input.ReplaceBeforeCursor(0, "flag ")
+	ctx.View.Input.SetStatus("due time, e.g. 2h or 15:04, or none")
+	termui.Render(ctx.View.Input)
+}
+
+// followUpInterval is how often the follow-ups are checked for reminders
+// that are due
+const followUpInterval = 30 * time.Second
+
+// actionRunFollowUpReminders will show a notification for every follow-up
+// that is due, once
+func actionRunFollowUpReminders(ctx *context.AppContext) {
+	for range time.Tick(followUpInterval) {
+		followUps, err := ctx.Service.GetFollowUps()
+		if err != nil {
+			return
+		}
+
+		now := time.Now()
+		for _, followUp := range followUps {
+			if followUp.Reminded || !followUp.IsDue(now) {
+				continue
+			}
+
+			followUp.Reminded = true
+			if err := ctx.Service.SetFollowUp(followUp); err != nil {
+				ctx.View.Debug.Println(
+					fmt.Sprintf("unable to update follow-up: %v", err),
+				)
+				continue
+			}
+
+			actionNotifyFollowUp(ctx, followUp)
+		}
+	}
+}
+
+// actionNotifyFollowUp will show a desktop notification for a follow-up
+// that is due, clicking it will select the channel of the message
+func actionNotifyFollowUp(ctx *context.AppContext, followUp components.FollowUpItem) {
+	title := "Follow up"
+	if index := ctx.View.Channels.FindChannel(followUp.ChannelID); index != -1 {
+		title = fmt.Sprintf("Follow up in %s", ctx.View.Channels.ChannelItems[index].Name)
+	}
+
+	message := fmt.Sprintf("%s: %s", followUp.Name, followUp.Content)
+
+	open := notifyOpen(ctx, followUp.ChannelID)
+	if err := ctx.Notify.Push(title, message, open); err != nil {
+		ctx.View.Debug.Println(
+			err.Error(),
+		)
+	}
+}
+
+// actionSelectMode will select the message at the bottom of the Chat pane,
+// the selection is moved with the select actions
+func actionSelectMode(ctx *context.AppContext) {
+	if !ctx.View.Chat.SelectMessage("") {
+		return
+	}
+
+	ctx.Mode = context.SelectMode
+	ctx.View.Mode.SetSelectMode()
+	termui.Render(ctx.View.Chat)
+}
+
+func actionMoveSelectionUp(ctx *context.AppContext) {
+	ctx.View.Chat.MoveSelection(-1)
+	termui.Render(ctx.View.Chat)
+}
+
+func actionMoveSelectionDown(ctx *context.AppContext) {
+	ctx.View.Chat.MoveSelection(1)
+	termui.Render(ctx.View.Chat)
+}
+
+func actionMoveSelectionTop(ctx *context.AppContext) {
+	ctx.View.Chat.MoveSelection(-len(ctx.View.Chat.Messages))
+	termui.Render(ctx.View.Chat)
+}
+
+func actionMoveSelectionBottom(ctx *context.AppContext) {
+	ctx.View.Chat.MoveSelection(len(ctx.View.Chat.Messages))
+	termui.Render(ctx.View.Chat)
+}
+
+// actionReactionMode will replace the Chat component with the emoji that
+// can be added as a reaction to the selected message, they're filtered by
+// what is typed
+func actionReactionMode(ctx *context.AppContext) {
+	msg, ok := ctx.View.Chat.GetSelectedMessage()
+	if !ok {
+		return
+	}
+
+	ctx.View.Emoji.SetMessage(msg, ctx.Service.GetFrequentEmoji())
+	ctx.View.Input.Clear()
+
+	ctx.Mode = context.ReactionMode
+	ctx.View.Mode.SetReactionMode()
+	actionRedrawGrid(ctx, ctx.View.Threads.HasThreads(), ctx.Debug)
+}
+
+// actionSearchReaction will filter the emoji by the input of the user
+func actionSearchReaction(ctx *context.AppContext, key rune) {
+	actionInput(ctx.View, key)
+
+	ctx.View.Emoji.Filter(ctx.View.Input.GetText())
+	termui.Render(ctx.View.Emoji)
+}
+
+func actionBackSpaceReaction(ctx *context.AppContext) {
+	actionBackSpace(ctx)
+
+	ctx.View.Emoji.Filter(ctx.View.Input.GetText())
+	termui.Render(ctx.View.Emoji)
+}
+
+func actionMoveCursorUpReaction(ctx *context.AppContext) {
+	ctx.View.Emoji.MoveCursorUp()
+	termui.Render(ctx.View.Emoji)
+}
+
+func actionMoveCursorDownReaction(ctx *context.AppContext) {
+	ctx.View.Emoji.MoveCursorDown()
+	termui.Render(ctx.View.Emoji)
+}
+
+// actionToggleReaction will add the selected emoji as a reaction to the
+// selected message, or remove it when the user has already reacted with
+// it. The reactions under the message are updated by the event that
+// follows.
+func actionToggleReaction(ctx *context.AppContext) {
+	msg, ok := ctx.View.Chat.GetSelectedMessage()
+	if !ok || !ctx.View.Emoji.HasEmoji() {
+		return
+	}
+
+	emoji := ctx.View.Emoji.GetSelectedEmoji()
+	channelID := ctx.View.Channels.GetSelectedChannel().ID
+
+	var err error
+	if emoji.Reacted {
+		err = ctx.Service.RemoveReaction(gocontext.Background(), channelID, msg.ID, emoji.Name)
+	} else {
+		err = ctx.Service.AddReaction(gocontext.Background(), channelID, msg.ID, emoji.Name)
+	}
+	if err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to react with :%s: %v", emoji.Name, err),
+		)
+	}
+
+	actionCloseReaction(ctx)
+}
+
+// actionCloseReaction will restore the Chat component, and return to select
+// mode
+func actionCloseReaction(ctx *context.AppContext) {
+	ctx.View.Input.Clear()
+	termui.Render(ctx.View.Input)
+
+	ctx.Mode = context.SelectMode
+	ctx.View.Mode.SetSelectMode()
+	actionRedrawGrid(ctx, ctx.View.Threads.HasThreads(), ctx.Debug)
+}
+
+// actionSwitcherMode will open the Switcher popup, to jump to a
+// conversation by typing a part of its name
+func actionSwitcherMode(ctx *context.AppContext) {
+	if len(ctx.View.Channels.ChannelItems) == 0 {
+		return
+	}
+
+	actionHideCompletion(ctx)
+
+	ctx.Mode = context.SwitcherMode
+	ctx.View.Mode.SetSwitcherMode()
+	actionShowSwitcher(ctx, "")
+}
+
+// actionShowSwitcher will show the Switcher popup on top of the Chat pane
+// with the conversations that match term
+func actionShowSwitcher(ctx *context.AppContext, term string) {
+	chat := ctx.View.Chat.List
+	ctx.View.Switcher.Show(
+		ctx.View.Channels.ChannelItems,
+		chat.X, chat.Y, chat.Width, chat.Height,
+	)
+	ctx.View.Switcher.Filter(term)
+	termui.Render(ctx.View.Switcher)
+}
+
+// actionSearchSwitcher will filter the conversations in the Switcher by
+// what is typed
+func actionSearchSwitcher(ctx *context.AppContext, key rune) {
+	ctx.View.Switcher.Filter(ctx.View.Switcher.Term + string(key))
+	termui.Render(ctx.View.Switcher)
+}
+
+func actionBackSpaceSwitcher(ctx *context.AppContext) {
+	ctx.View.Switcher.Backspace()
+	termui.Render(ctx.View.Switcher)
+}
+
+func actionMoveCursorUpSwitcher(ctx *context.AppContext) {
+	ctx.View.Switcher.MoveCursorUp()
+	termui.Render(ctx.View.Switcher)
+}
+
+func actionMoveCursorDownSwitcher(ctx *context.AppContext) {
+	ctx.View.Switcher.MoveCursorDown()
+	termui.Render(ctx.View.Switcher)
+}
+
+// actionJumpSwitcher will load the conversation that is selected in the
+// Switcher popup
+func actionJumpSwitcher(ctx *context.AppContext) {
+	if !ctx.View.Switcher.HasMatches() {
+		return
+	}
+
+	channel := ctx.View.Switcher.GetSelectedMatch()
+	actionCloseSwitcher(ctx)
+
+	if ctx.View.Channels.GotoChannel(channel.ID) {
+		actionChangeChannel(ctx)
+	}
+}
+
+// actionCloseSwitcher will close the Switcher popup, and render what was
+// underneath it
+func actionCloseSwitcher(ctx *context.AppContext) {
+	actionCommandMode(ctx)
+	actionRedrawGrid(ctx, ctx.View.Threads.HasThreads(), ctx.Debug)
+}
+
+// actionInfoMode will open the Info popup, with the details and the
+// bookmarks of the selected channel. It's shown with what is known of the
+// channel right away, and filled in when the rest has been fetched.
+func actionInfoMode(ctx *context.AppContext) {
+	if len(ctx.View.Channels.ChannelItems) == 0 {
+		return
+	}
+
+	actionHideCompletion(ctx)
+
+	channel := ctx.View.Channels.GetSelectedChannel()
+
+	ctx.Mode = context.InfoMode
+	ctx.View.Mode.SetInfoMode()
+	ctx.View.Info.SetDetails(components.ChannelDetails{
+		Topic:       channel.Topic,
+		Purpose:     channel.Purpose,
+		IsExtShared: channel.IsExtShared,
+	})
+	ctx.View.Info.Canvas = ""
+	ctx.View.Info.SetBookmarks(nil)
+	actionShowInfo(ctx)
+
+	details, err := ctx.Service.GetChannelInfo(gocontext.Background(), channel.ID)
+	if err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to get channel info: %v", err),
+		)
+	} else {
+		ctx.View.Info.SetDetails(details)
+	}
+
+	canvas, err := ctx.Service.GetCanvasLink(gocontext.Background(), channel.ID)
+	if err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to get canvas: %v", err),
+		)
+	}
+	ctx.View.Info.Canvas = canvas
+
+	bookmarks, err := ctx.Service.GetBookmarks(gocontext.Background(), channel.ID)
+	if err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to get bookmarks: %v", err),
+		)
+	}
+	ctx.View.Info.SetBookmarks(bookmarks)
+
+	termui.Render(ctx.View.Info)
+}
+
+// actionShowInfo will show the Info popup on top of the Chat pane, with
+// the details of the selected channel
+func actionShowInfo(ctx *context.AppContext) {
+	chat := ctx.View.Chat.List
+	ctx.View.Info.Show(
+		ctx.View.Channels.GetSelectedChannel(),
+		chat.X, chat.Y, chat.Width, chat.Height,
+	)
+	termui.Render(ctx.View.Info)
+}
+
+func actionMoveCursorUpInfo(ctx *context.AppContext) {
+	ctx.View.Info.MoveCursorUp()
+	termui.Render(ctx.View.Info)
+}
+
+func actionMoveCursorDownInfo(ctx *context.AppContext) {
+	ctx.View.Info.MoveCursorDown()
+	termui.Render(ctx.View.Info)
+}
+
+// actionOpenBookmark will open the link of the bookmark that is selected
+// in the Info popup with the open_command
+func actionOpenBookmark(ctx *context.AppContext) {
+	if !ctx.View.Info.HasBookmarks() {
+		return
+	}
+
+	actionOpenLink(ctx, ctx.View.Info.GetSelectedBookmark().Link)
+}
+
+// actionOpenCanvas will open the canvas of the channel in the Info popup
+// with the open_command
+func actionOpenCanvas(ctx *context.AppContext) {
+	if !ctx.View.Info.HasCanvas() {
+		return
+	}
+
+	actionOpenLink(ctx, ctx.View.Info.Canvas)
+}
+
+// actionOpenLink will open the link with the open_command, e.g. in the
+// browser
+func actionOpenLink(ctx *context.AppContext, link string) {
+	if ctx.Config.OpenCommand == "" {
+		ctx.View.Input.SetStatus("set open_command in the config file to open links")
+		termui.Render(ctx.View.Input)
+		return
+	}
+
+	if err := openWithCommand(ctx.Config.OpenCommand, link); err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to open %s: %v", link, err),
+		)
+		return
+	}
+
+	actionToast(ctx, fmt.Sprintf("opened %s", link))
+}
+
+// actionCloseInfo will close the Info popup, and render what was
+// underneath it
+func actionCloseInfo(ctx *context.AppContext) {
+	actionCommandMode(ctx)
+	actionRedrawGrid(ctx, ctx.View.Threads.HasThreads(), ctx.Debug)
+}
+
+// actionTasksMode will open the Tasks popup on top of the Chat pane, with
+// the tasks that are in progress. It's updated every second while it's
+// open, to show how long the tasks have been running.
+func actionTasksMode(ctx *context.AppContext) {
+	actionHideCompletion(ctx)
+
+	ctx.Mode = context.TasksMode
+	ctx.View.Mode.SetTasksMode()
+	actionShowTasks(ctx)
+
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			if ctx.Mode != context.TasksMode {
+				return
+			}
+			actionRenderTasks(ctx)
+		}
+	}()
+}
+
+func actionShowTasks(ctx *context.AppContext) {
+	chat := ctx.View.Chat.List
+	ctx.View.Tasks.Show(chat.X, chat.Y, chat.Width, chat.Height)
+	actionRenderTasks(ctx)
+}
+
+// actionRenderTasks will fill the Tasks popup with the tasks that are in
+// progress, and render it when it's open
+func actionRenderTasks(ctx *context.AppContext) {
+	if ctx.Mode != context.TasksMode {
+		return
+	}
+
+	var items []components.TaskItem
+	for _, t := range ctx.Tasks.List() {
+		items = append(items, components.TaskItem{
+			ID:          t.ID,
+			Description: t.Description,
+			Progress:    t.Progress,
+			Started:     t.Started,
+		})
+	}
+
+	ctx.View.Tasks.SetTasks(items)
+	termui.Render(ctx.View.Tasks)
+}
+
+func actionMoveCursorUpTasks(ctx *context.AppContext) {
+	ctx.View.Tasks.MoveCursorUp()
+	termui.Render(ctx.View.Tasks)
+}
+
+func actionMoveCursorDownTasks(ctx *context.AppContext) {
+	ctx.View.Tasks.MoveCursorDown()
+	termui.Render(ctx.View.Tasks)
+}
+
+// actionCancelTask will cancel the task that is selected in the Tasks
+// popup, it's removed from the popup once it has stopped
+func actionCancelTask(ctx *context.AppContext) {
+	if !ctx.View.Tasks.HasTasks() {
+		return
+	}
+
+	t := ctx.View.Tasks.GetSelectedTask()
+	if ctx.Tasks.Cancel(t.ID) {
+		actionToast(ctx, fmt.Sprintf("cancelled %s", t.Description))
+	}
+}
+
+// actionFindMode will start a search of the messages that are loaded in
+// the Chat pane, the input holds the term while it's typed. The draft is
+// kept in the conversation, and put back when the search is done.
+func actionFindMode(ctx *context.AppContext) {
+	actionSaveConversation(ctx)
+
+	ctx.Mode = context.FindMode
+	ctx.View.Mode.SetFindMode()
+
+	ctx.View.Input.Clear()
+	ctx.View.Input.SetStatus("")
+	termui.Render(ctx.View.Input)
+
+	actionUpdateFind(ctx)
+}
+
+// actionFind will add the key to the term, and jump to the newest message
+// that matches it
+func actionFind(ctx *context.AppContext, key rune) {
+	actionInput(ctx.View, key)
+	actionUpdateFind(ctx)
+}
+
+func actionBackSpaceFind(ctx *context.AppContext) {
+	actionBackSpace(ctx)
+	actionUpdateFind(ctx)
+}
+
+func actionDeleteFind(ctx *context.AppContext) {
+	actionDelete(ctx)
+	actionUpdateFind(ctx)
+}
+
+func actionSpaceFind(ctx *context.AppContext) {
+	actionFind(ctx, ' ')
+}
+
+// actionUpdateFind will search for the term in the input, and highlight its
+// matches. The Chat pane stays where it is when nothing matches.
+func actionUpdateFind(ctx *context.AppContext) {
+	term := ctx.View.Input.GetText()
+	offset := ctx.View.Chat.Offset
+
+	if !ctx.View.Chat.SetFind(term) {
+		ctx.View.Chat.Offset = offset
+	}
+
+	actionRenderFindStatus(ctx)
+	actionRenderChat(ctx)
+}
+
+// actionFindDone will keep the matches of the search highlighted, they're
+// jumped between with search-next and search-prev
+func actionFindDone(ctx *context.AppContext) {
+	if ctx.View.Input.GetText() == "" {
+		actionFindClear(ctx)
+		return
+	}
+
+	actionLeaveFind(ctx)
+	actionRenderFindStatus(ctx)
+}
+
+// actionFindClear will stop the search, and remove the highlights of its
+// matches
+func actionFindClear(ctx *context.AppContext) {
+	ctx.View.Chat.ClearFind()
+	actionRenderChat(ctx)
+
+	actionLeaveFind(ctx)
+	actionRenderStatus(ctx)
+}
+
+// actionLeaveFind will return to command mode, with the draft of the
+// conversation back in the input
+func actionLeaveFind(ctx *context.AppContext) {
+	ctx.Mode = context.CommandMode
+	actionRestoreDraft(ctx, chatChannelID)
+	actionCommandMode(ctx)
+}
+
+// actionFindNext will jump to the match that is count matches older than
+// the one that was jumped to last, or newer when count is negative
+func actionFindNext(ctx *context.AppContext, count int) {
+	ctx.View.Chat.FindNext(count)
+	actionRenderFindStatus(ctx)
+	actionRenderChat(ctx)
+}
+
+// actionRenderFindStatus will show which of the matches of the search was
+// jumped to in the status bar, e.g. "/deploy 2 of 5"
+func actionRenderFindStatus(ctx *context.AppContext) {
+	var status string
+	if term := ctx.View.Chat.Find; term != "" {
+		position, total := ctx.View.Chat.FindPosition()
+		if total == 0 {
+			status = fmt.Sprintf("/%s no matches", term)
+		} else {
+			status = fmt.Sprintf("/%s %d of %d", term, position, total)
+		}
+	}
+
+	ctx.View.Input.SetStatus(status)
+	termui.Render(ctx.View.Input)
+}
+
+// actionCopyTimestamp will copy the timestamp of the selected message to
+// the clipboard, the message can be selected again with ":at <timestamp>"
+func actionCopyTimestamp(ctx *context.AppContext) {
+	msg, ok := ctx.View.Chat.GetSelectedMessage()
+	if !ok {
+		return
+	}
+
+	if err := copyToClipboard(ctx.Config.ClipboardCommand, msg.ID); err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to copy the timestamp: %v", err),
+		)
+		return
+	}
+
+	actionToast(ctx, fmt.Sprintf("copied %s", msg.ID))
+}
+
+// copyToClipboard will copy text to the clipboard with the clipboard_command,
+// or when that isn't set with the OSC 52 escape sequence of the terminal
+func copyToClipboard(command string, text string) error {
+	if command != "" {
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+
+	_, err := fmt.Fprintf(
+		os.Stdout, "\x1b]52;c;%s\a",
+		base64.StdEncoding.EncodeToString([]byte(text)),
+	)
+	return err
+}
+
+// actionDeleteMessage will ask to confirm the deletion of the selected
+// message, only messages of the current user can be deleted
+func actionDeleteMessage(ctx *context.AppContext) {
+	msg, ok := ctx.View.Chat.GetSelectedMessage()
+	if !ok {
+		return
+	}
+
+	if msg.UserID != ctx.Service.GetCurrentUserID() {
+		ctx.View.Input.SetStatus("only your own messages can be deleted")
+		termui.Render(ctx.View.Input)
+		return
+	}
+
+	actionConfirm(ctx, "select-delete", "delete this message?")
+}
+
+// actionDeleteMessageKey will delete the selected message when the deletion
+// has been confirmed with y
+func actionDeleteMessageKey(ctx *context.AppContext, key rune) {
+	actionRenderStatus(ctx)
+
+	if key != 'y' {
+		return
+	}
+
+	msg, ok := ctx.View.Chat.GetSelectedMessage()
+	if !ok {
+		return
+	}
+
+	channelID := ctx.View.Channels.GetSelectedChannel().ID
+	if err := ctx.Service.DeleteMessage(gocontext.Background(), channelID, msg.ID); err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to delete message: %v", err),
+		)
+		return
+	}
+
+	// The message above it is selected next, the event of the deletion
+	// will find the message removed already
+	ctx.View.Chat.MoveSelection(-1)
+	actionRemoveMessage(ctx, channelID, msg.ID)
+}
+
+// actionRemoveMessage will remove a message that has been deleted from the
+// Chat pane, when it shows the channel of the message
+func actionRemoveMessage(ctx *context.AppContext, channelID string, messageID string) {
+	if channelID != ctx.View.Channels.GetSelectedChannel().ID || isBrowsing(ctx) {
+		return
+	}
+
+	if !ctx.View.Chat.RemoveMessage(messageID) {
+		return
+	}
+
+	// When the selected message is gone, select the one at the bottom
+	if ctx.Mode == context.SelectMode {
+		if _, ok := ctx.View.Chat.GetSelectedMessage(); !ok && !ctx.View.Chat.SelectMessage("") {
+			actionCloseSelect(ctx)
+			return
+		}
+	}
+
+	actionRenderChat(ctx)
+}
+
+// actionCloseSelect will clear the selection, and return to command mode
+func actionCloseSelect(ctx *context.AppContext) {
+	ctx.View.Chat.Selected = ""
+	actionCommandMode(ctx)
+}
+
+// actionSaveConversation will keep the state of the channel that is shown
+// in the Chat pane: its messages, the message at the bottom of the Chat
+// pane, and the message that is being composed. The input holds the search
+// term in search mode, it isn't kept as the draft.
+func actionSaveConversation(ctx *context.AppContext) {
+	if chatChannelID == "" {
+		return
+	}
+
+	ctx.Conversations.Update(chatChannelID, func(state *context.Conversation) {
+		state.Messages = components.SortMessages(ctx.View.Chat.Messages)
+		state.ScrollPosition = ctx.View.Chat.GetBottomMessage()
+		if ctx.Mode != context.SearchMode && ctx.Mode != context.FindMode && editing.messageID == "" {
+			state.Draft = ctx.View.Input.GetText()
+		}
+	})
+}
+
+// actionLeaveConversation will keep the state of the channel that is shown
+// in the Chat pane, before the Chat pane shows something else, e.g. a
+// digest
+func actionLeaveConversation(ctx *context.AppContext) {
+	actionSaveConversation(ctx)
+	chatChannelID = ""
+}
+
+// actionRestoreScrollPosition will scroll the Chat pane to where the
+// channel was scrolled to when it was left, when the message is still
+// part of the loaded messages
+func actionRestoreScrollPosition(ctx *context.AppContext, channelID string) {
+	chatChannelID = channelID
+
+	if messageID := ctx.Conversations.Get(channelID).ScrollPosition; messageID != "" {
+		ctx.View.Chat.ScrollToMessage(messageID)
+	}
+}
+
+// actionRestoreDraft will put the message that was being composed in the
+// channel back into the input
+func actionRestoreDraft(ctx *context.AppContext, channelID string) {
+	if ctx.Mode == context.SearchMode || ctx.Mode == context.FindMode || editing.messageID != "" {
+		return
+	}
+
+	ctx.View.Input.Clear()
+	for _, r := range ctx.Conversations.Get(channelID).Draft {
+		ctx.View.Input.Insert(r)
+	}
+	termui.Render(ctx.View.Input)
+}
+
+// newChannelContext will cancel the requests that are made for the
+// previously selected channel, and returns the context for the requests of
+// the newly selected channel
+func newChannelContext() gocontext.Context {
+	if channelCancel != nil {
+		channelCancel()
+	}
+
+	channelCtx, channelCancel = gocontext.WithCancel(gocontext.Background())
+
+	return channelCtx
+}
+
+// actionChangeChannel will show the selected channel, its history is
+// fetched in the background, see actionLoadChannel
+func actionChangeChannel(ctx *context.AppContext) {
+	actionLoadChannel(ctx, nil)
+}
+
+// actionLoadChannel will show the selected channel with the messages of
+// when it was left, or its cached history, and fetch its history in the
+// background. The fetch is cancelled when another channel is selected, or
+// in the Tasks popup. The history is shown on the goroutine that handles
+// the keys, after which loaded is called when it isn't nil, e.g. to scroll
+// to a message of the history.
+func actionLoadChannel(ctx *context.AppContext, loaded func()) {
+	// Keep the state of the previous channel, e.g. where it was scrolled
+	// to
+	actionSaveConversation(ctx)
+
+	// Clear messages from Chat pane, the search of the messages ends with
+	// the channel
+	ctx.View.Chat.ClearMessages()
+	ctx.View.Chat.ClearFind()
+
+	// Cancel the requests that are still being made for the previously
+	// selected channel
+	reqCtx := newChannelContext()
+
+	// Show the messages of the channel from when it was left, or its
+	// cached history, while the messages are being fetched
+	channelID := ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID
+	actionRestoreDraft(ctx, channelID)
+
+	cached := ctx.Conversations.Get(channelID).Messages
+	ok := len(cached) > 0
+	if !ok {
+		cached, _, ok = ctx.Service.GetCachedMessages(reqCtx, channelID)
+	}
+	if ok {
+		ctx.View.Chat.SetMessages(cached)
+		actionRestoreScrollPosition(ctx, channelID)
+		actionRenderChatLabel(ctx)
+	}
+
+	// Get messages of the SelectedChannel, and get the count of messages
+	// that fit into the Chat component
+	count, days := actionGetHistoryWindow(ctx, channelID)
+	taskCtx, t := actionStartTask(
+		ctx, ctx.View.ChatSpinner, reqCtx,
+		fmt.Sprintf("loading history of %s", ctx.View.Channels.GetSelectedChannel().GetName()),
+	)
+
+	go func() {
+		msgs, threads, err := ctx.Service.GetMessages(
+			taskCtx,
+			channelID,
+			count,
+			days,
+		)
+		actionStopTask(ctx, t)
+
+		ctx.ActionQueue <- func() {
+			// Another channel has been selected in the meantime, the
+			// response can have arrived before the request was
+			// cancelled
+			if reqCtx.Err() != nil || !isSelectedChannel(ctx, channelID) {
+				return
+			}
+
+			if err != nil {
+				// Loading the history has been cancelled, the cached
+				// history is kept
+				if taskCtx.Err() != nil {
+					actionRenderChatLabel(ctx)
+					actionRenderChat(ctx)
+					return
+				}
+
+				termbox.Close()
+				log.Println(err)
+				os.Exit(0)
+			}
+
+			actionShowHistory(ctx, reqCtx, channelID, cached, msgs, threads, count)
+			if loaded != nil {
+				loaded()
+			}
+		}
+	}()
+}
+
+// isSelectedChannel returns whether the channel with channelID is selected
+func isSelectedChannel(ctx *context.AppContext, channelID string) bool {
+	return len(ctx.View.Channels.ChannelItems) > 0 &&
+		ctx.View.Channels.GetSelectedChannel().ID == channelID
+}
+
+// actionShowHistory will show the fetched history of the selected channel,
+// with the threads and the members. The cached history that was shown
+// while it was fetched is kept when messages are missing between them.
+func actionShowHistory(ctx *context.AppContext, reqCtx gocontext.Context, channelID string, cached []components.Message, msgs []components.Message, threads []components.ChannelItem, count int) {
+	// Set messages for the channel, and return to where it was scrolled
+	// to when it was left. When the cached history ends before the
+	// fetched history starts, the messages in between have been missed.
+	// The cached history is kept, with a marker to fetch them.
+	ctx.View.Chat.ClearMessages()
+	if len(cached) > 0 && isGap(cached[len(cached)-1].ID, msgs, count) {
+		ctx.View.Chat.SetMessages(cached)
+		ctx.View.Chat.Gap = cached[len(cached)-1].ID
+	}
+	ctx.View.Chat.SetMessages(msgs)
+	actionRestoreScrollPosition(ctx, channelID)
+
+	// Set the threads identifiers in the threads pane, and show the most
+	// recent thread
+	hadThreads := ctx.View.Threads.HasThreads()
+	haveThreads := len(threads) > 0
+
+	ctx.View.Threads.SetThreads(threads)
+	if haveThreads {
+		ctx.View.Threads.SetThread(
+			ctx.View.Chat.Messages[ctx.View.Threads.GetSelectedThread().ID],
+		)
+	}
+
+	// Get the workspaces and organizations the channel is shared with
+	actionGetChannelTeams(ctx)
+
+	// Get the members of the channel, to show how many of them are
+	// online. They're cached, so they're available when composing a
+	// message as well.
+	go actionGetChannelMembers(ctx, reqCtx, channelID)
+
+	// Get whether messages can be posted in the channel, before the user
+	// switches to insert mode
+	go actionGetPostingPolicy(ctx, reqCtx, channelID)
+
+	// Set channel name and topic for the Chat pane
+	actionRenderChatLabel(ctx)
+
+	// Clear notification icon if there is any
+	channelItem := ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel]
+	if channelItem.Notification {
+		ctx.Service.MarkAsRead(gocontext.Background(), channelItem)
+		ctx.View.Channels.MarkAsRead(ctx.View.Channels.SelectedChannel)
+		actionRenderStatus(ctx)
+	}
+
+	actionRenderTitle(ctx)
+
+	// Redraw grid, necessary when threads and/or debug is set. We will redraw
+	// the grid when there are threads, or we just came from a channel with
+	// threads and went to a channel without threads.
+	if haveThreads || hadThreads {
+		actionRedrawGrid(ctx, haveThreads, ctx.Debug)
+	} else {
+		termui.Render(ctx.View.Channels)
+		termui.Render(ctx.View.Chat)
+	}
+
+	// Set focus, necessary to know when replying to thread or chat
+	ctx.Focus = context.ChatFocus
+	actionRenderFocus(ctx)
+
+	// Fetch the replies of the thread that is shown after the messages are
+	// rendered, the other threads only show a summary until they're opened
+	if haveThreads {
+		actionLoadReplies(
+			ctx, reqCtx, channelItem.ID, ctx.View.Threads.GetSelectedThread().ID,
+		)
+	}
+
+	// Prefetch the history of the next channels in the sidebar
+	var channelIDs []string
+	for i := 1; i <= prefetchCount; i++ {
+		index := ctx.View.Channels.SelectedChannel + i
+		if index >= len(ctx.View.Channels.ChannelItems) {
+			break
+		}
+		channelIDs = append(channelIDs, ctx.View.Channels.ChannelItems[index].ID)
+	}
+	go actionPrefetchHistory(ctx, reqCtx, channelIDs)
+}
+
+// isGap returns whether messages have been missed between the message with
+// id newest and the fetched messages, they're as many as have been asked for
+// and all of them are newer
+func isGap(newest string, msgs []components.Message, count int) bool {
+	return newest != "" && len(msgs) > 0 && len(msgs) >= count && newest < msgs[0].ID
+}
+
+// actionReconnected will add the messages of the selected channel that
+// have been posted while the connection was lost. When not all of them fit
+// the history window, a marker to fetch the rest is shown. It's called for
+// the events of the real time api, the messages are fetched in the
+// background and added on the goroutine that handles the keys.
+func actionReconnected(ctx *context.AppContext) {
+	ctx.ActionQueue <- func() {
+		if isBrowsing(ctx) || len(ctx.View.Channels.ChannelItems) == 0 {
+			return
+		}
+
+		var newest string
+		if msgs := components.SortMessages(ctx.View.Chat.Messages); len(msgs) > 0 {
+			newest = msgs[len(msgs)-1].ID
+		}
+
+		reqCtx := channelCtx
+		channelID := ctx.View.Channels.GetSelectedChannel().ID
+		count, days := actionGetHistoryWindow(ctx, channelID)
+		taskCtx, t := actionStartTask(
+			ctx, ctx.View.ChatSpinner, reqCtx, "fetching missed messages",
+		)
+
+		go func() {
+			msgs, _, err := ctx.Service.GetMessages(taskCtx, channelID, count, days)
+			actionStopTask(ctx, t)
+
+			ctx.ActionQueue <- func() {
+				// Another channel has been selected in the meantime
+				if reqCtx.Err() != nil || !isSelectedChannel(ctx, channelID) ||
+					isBrowsing(ctx) {
+					return
+				}
+
+				if err != nil {
+					if taskCtx.Err() != nil {
+						return
+					}
+
+					ctx.View.Debug.Println(
+						fmt.Sprintf("unable to get missed messages: %v", err),
+					)
+					return
+				}
+
+				if isGap(newest, msgs, count) {
+					ctx.View.Chat.Gap = newest
+				}
+				for _, msg := range msgs {
+					if _, ok := ctx.View.Chat.Messages[msg.ID]; !ok {
+						ctx.View.Chat.AddMessage(msg)
+					}
+				}
+				actionRenderChat(ctx)
+			}
+		}()
+	}
+}
+
+// actionFetchGap will fetch the messages that have been missed, shown by
+// the marker in the Chat pane. The newest of them are fetched first, the
+// marker stays until all of them have been fetched.
+func actionFetchGap(ctx *context.AppContext) {
+	end := ctx.View.Chat.GetGapEnd()
+	if end == "" {
+		return
+	}
+
+	channelID := ctx.View.Channels.GetSelectedChannel().ID
+	count, _ := actionGetHistoryWindow(ctx, channelID)
+	gap := ctx.View.Chat.Gap
+	taskCtx, t := actionStartTask(
+		ctx, ctx.View.ChatSpinner, gocontext.Background(), "fetching missed messages",
+	)
+
+	// The messages are fetched in the background, so the fetch can be
+	// cancelled in the Tasks popup
+	go func() {
+		msgs, hasMore, err := ctx.Service.GetMissedMessages(
+			taskCtx, channelID, gap, end, count,
+		)
+		actionStopTask(ctx, t)
+		if err != nil {
+			// The fetch has been cancelled in the Tasks popup, the
+			// context of a task is done once it has stopped
+			if taskCtx.Err() != nil {
+				return
+			}
+
+			ctx.View.Debug.Println(
+				fmt.Sprintf("unable to get missed messages: %v", err),
+			)
+			return
+		}
+
+		ctx.ActionQueue <- func() {
+			// Another channel has been selected in the meantime, or the
+			// gap has been fetched already
+			if !isSelectedChannel(ctx, channelID) || ctx.View.Chat.Gap != gap {
+				return
+			}
+
+			ctx.View.Chat.FillGap(msgs, !hasMore)
+			actionRenderChat(ctx)
+		}
+	}()
+}
+
+// actionGetHistoryWindow returns the number of messages, and the number of
+// days, of the history that is fetched of a channel. By default as many
+// messages are fetched as fit the Chat pane, the more command extends the
+// window by another history_days and history_count.
+func actionGetHistoryWindow(ctx *context.AppContext, channelID string) (int, int) {
+	count := ctx.Config.HistoryCount
+	if count == 0 {
+		count = ctx.View.Chat.GetMaxItems()
+	}
+	days := ctx.Config.HistoryDays
+
+	windows := ctx.Conversations.Get(channelID).HistoryWindows + 1
+	count *= windows
+	days *= windows
+
+	if count > config.HistoryMaxCount {
+		count = config.HistoryMaxCount
+	}
+
+	return count, days
+}
+
+// actionPrefetchHistory will fetch the history of channels into the
+// persistent cache, so switching to them doesn't have to wait for the
+// messages to be fetched. It stops when reqCtx is cancelled.
+func actionPrefetchHistory(ctx *context.AppContext, reqCtx gocontext.Context, channelIDs []string) {
+	if len(channelIDs) == 0 {
+		return
+	}
+
+	// Prefetching isn't shown by a spinner, it's only listed in the Tasks
+	// popup
+	taskCtx, t := actionStartTask(ctx, nil, reqCtx, "prefetching history")
+	defer actionStopTask(ctx, t)
+
+	for i, channelID := range channelIDs {
+		actionSetTaskProgress(ctx, t, fmt.Sprintf("(%d/%d)", i+1, len(channelIDs)))
+
+		count, days := actionGetHistoryWindow(ctx, channelID)
+		if err := ctx.Service.PrefetchMessages(taskCtx, channelID, count, days); err != nil {
+			if taskCtx.Err() != nil {
+				return
+			}
+
+			ctx.View.Debug.Println(
+				fmt.Sprintf("unable to prefetch history of channel %s: %v", channelID, err),
+			)
+			return
+		}
+	}
+}
+
+// actionAddReaction will update the reactions under a message of the
+// selected channel, a negative count removes them
+func actionAddReaction(ctx *context.AppContext, channelID string, messageID string, reaction string, userID string, count int) {
+	if channelID != ctx.View.Channels.GetSelectedChannel().ID || isBrowsing(ctx) {
+		return
+	}
+
+	name := ctx.Service.GetReactionName(channelID, reaction)
+	own := userID == ctx.Service.GetCurrentUserID()
+	parentID, ok := ctx.View.Chat.AddReaction(messageID, name, count, own)
+	if !ok {
+		return
+	}
+	actionRenderChat(ctx)
+
+	// Update the Threads pane when it shows the thread
+	if ctx.View.Threads.HasThreads() && ctx.View.Threads.GetSelectedThread().ID == parentID {
+		ctx.View.Threads.SetThread(ctx.View.Chat.Messages[parentID])
+		termui.Render(ctx.View.Threads)
+	}
+}
+
+// actionAddReply will add a reply to its parent in the Chat pane. When it is
+// the first reply, the parent becomes a thread and is added to the Threads
+// pane.
+func actionAddReply(ctx *context.AppContext, threadTimestamp string, msg components.Message) {
+	parent, ok := ctx.View.Chat.Messages[threadTimestamp]
+	isNewThread := ok && parent.Thread == ""
+	if isNewThread {
+		parent.Thread = ctx.Service.GetThreadPrefix(threadTimestamp)
+		parent.RepliesLoaded = true
+		ctx.View.Chat.Messages[threadTimestamp] = parent
+	}
+
+	ctx.View.Chat.AddReply(threadTimestamp, msg)
+	actionRenderChat(ctx)
+
+	if isNewThread {
+		hadThreads := ctx.View.Threads.HasThreads()
+		ctx.View.Threads.AddThread(ctx.Service.CreateThreadItem(parent))
+
+		// The Threads pane needs to be added to the grid
+		if !hadThreads {
+			ctx.View.Threads.SetThread(ctx.View.Chat.Messages[threadTimestamp])
+			actionRedrawGrid(ctx, true, ctx.Debug)
+		}
+		return
+	}
+
+	// Update the Threads pane when it shows the thread
+	if ctx.View.Threads.HasThreads() && ctx.View.Threads.GetSelectedThread().ID == threadTimestamp {
+		ctx.View.Threads.SetThread(ctx.View.Chat.Messages[threadTimestamp])
+		termui.Render(ctx.View.Threads)
+	}
+}
+
+// actionLoadReplies will load the replies of a thread when it is opened,
+// and updates the message in place. Until then the Chat pane only shows the
+// number of replies from the history. The replies are fetched in the
+// background, and added on the goroutine that handles the keys. They're
+// dropped when another channel has been selected in the meantime, which
+// cancels reqCtx.
+func actionLoadReplies(ctx *context.AppContext, reqCtx gocontext.Context, channelID string, threadID string) {
+	parent, ok := ctx.View.Chat.Messages[threadID]
+	if !ok || parent.RepliesLoaded {
+		return
+	}
+
+	taskCtx, t := actionStartTask(ctx, ctx.View.ThreadsSpinner, reqCtx, "loading replies")
+
+	go func() {
+		parent, err := ctx.Service.LoadReplies(taskCtx, parent, channelID)
+		actionStopTask(ctx, t)
+
+		ctx.ActionQueue <- func() {
+			if reqCtx.Err() != nil {
+				return
+			}
+			if err != nil {
+				// Loading the replies has been cancelled in the
+				// Tasks popup
+				if taskCtx.Err() != nil {
+					return
+				}
+
+				ctx.View.Debug.Println(
+					fmt.Sprintf("unable to load replies of thread %s, scroll up in the thread to retry: %v", parent.ID, err),
+				)
+				return
+			}
+
+			if !isSelectedChannel(ctx, channelID) || isBrowsing(ctx) {
+				return
+			}
+			if _, ok := ctx.View.Chat.Messages[parent.ID]; !ok {
+				return
+			}
+
+			ctx.View.Chat.Messages[parent.ID] = parent
+			termui.Render(ctx.View.Chat)
+
+			if ctx.View.Threads.HasThreads() && ctx.View.Threads.GetSelectedThread().ID == parent.ID {
+				ctx.View.Threads.SetThread(parent)
+				termui.Render(ctx.View.Threads)
+			}
+		}
+	}()
+}
+
+// actionGetChannelTeams will get the names of the workspaces the selected
+// channel belongs to when part of an Enterprise Grid, and of the external
+// organizations it is shared with. Only when they haven't been fetched.
+func actionGetChannelTeams(ctx *context.AppContext) {
+	channelItem := &ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel]
+	if channelItem.SharedTeams != nil || channelItem.Type == components.ChannelTypeIM {
+		return
+	}
+	if !channelItem.IsExtShared && !ctx.Config.IsEnterprise {
+		return
+	}
+
+	workspaces, external, err := ctx.Service.GetChannelTeams(channelCtx, channelItem.ID)
+	if err != nil {
+		ctx.View.Debug.Println(
+			err.Error(),
+		)
+		return
+	}
+
+	if ctx.Config.IsEnterprise {
+		channelItem.Workspaces = workspaces
+	}
+	channelItem.SharedTeams = external
+}
+
+// actionChangeThread will show the selected thread in the Threads pane, and
+// sets the focus on it, so that messages are sent as a reply to the thread
+func actionChangeThread(ctx *context.AppContext) {
+	if !ctx.View.Threads.HasThreads() {
+		return
+	}
+
+	// The parent of the thread is usually already present in the Chat
+	// pane, only when it's not we'll fetch it
+	thread := ctx.View.Threads.GetSelectedThread()
+	parent, ok := ctx.View.Chat.Messages[thread.ID]
+	if !ok {
+		msgs, err := ctx.Service.GetMessageByID(
+			channelCtx,
+			thread.ID,
+			ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID,
+		)
+		if err != nil || len(msgs) == 0 {
+			ctx.View.Debug.Println(
+				fmt.Sprintf("unable to load thread %s: %v", thread.ID, err),
+			)
+			return
+		}
+		parent = msgs[0]
+	}
+
+	ctx.View.Threads.SetThread(parent)
+	ctx.Focus = context.ThreadFocus
+
+	actionRenderFocus(ctx)
+
+	// Only now that the thread is opened its replies are fetched
+	if ok && !parent.RepliesLoaded {
+		actionLoadReplies(
+			ctx,
+			channelCtx,
+			ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID,
+			thread.ID,
+		)
+	}
+}
+
+// actionScrollUpThreads will scroll up the Threads pane, when it is
+// already at the top the older replies are loaded
+func actionScrollUpThreads(ctx *context.AppContext) {
+	if ctx.View.Threads.AtTop() && ctx.View.Threads.HasMoreReplies() {
+		parent, _ := ctx.View.Threads.GetThread()
+		go actionLoadMoreReplies(
+			ctx, channelCtx, ctx.View.Channels.GetSelectedChannel().ID, parent,
+		)
+		return
+	}
+
+	ctx.View.Threads.ScrollUp()
+	termui.Render(ctx.View.Threads)
+}
+
+func actionScrollDownThreads(ctx *context.AppContext) {
+	ctx.View.Threads.ScrollDown()
+	termui.Render(ctx.View.Threads)
+}
+
+// actionLoadMoreReplies will fetch the page of older replies of the thread
+// in the background, and add them when the thread is still shown
+func actionLoadMoreReplies(ctx *context.AppContext, reqCtx gocontext.Context, channelID string, parent components.Message) {
+	replies, cursor, err := ctx.Service.GetReplies(
+		reqCtx, parent.ID, channelID, parent.RepliesCursor,
+	)
+
+	ctx.ActionQueue <- func() {
+		if reqCtx.Err() != nil {
+			return
+		}
+		if err != nil {
+			ctx.View.Debug.Println(
+				err.Error(),
+			)
+			return
+		}
+
+		// Another thread has been opened in the meantime
+		if shown, ok := ctx.View.Threads.GetThread(); !ok || shown.ID != parent.ID ||
+			shown.RepliesCursor != parent.RepliesCursor {
+			return
+		}
+
+		parent := ctx.View.Threads.AddReplies(replies, cursor)
+
+		// Keep the parent in the Chat pane in sync
+		if _, ok := ctx.View.Chat.Messages[parent.ID]; ok {
+			ctx.View.Chat.Messages[parent.ID] = parent
+			termui.Render(ctx.View.Chat)
+		}
+		termui.Render(ctx.View.Threads)
+	}
+}
+
+func actionMoveCursorUpThreads(ctx *context.AppContext) {
+	go func() {
+		if scrollTimer != nil {
+			scrollTimer.Stop()
+		}
+
+		ctx.View.Threads.MoveCursorUp()
+
+		scrollTimer = time.NewTimer(time.Second / 4)
+		<-scrollTimer.C
+
+		// Only actually change channel when the timer expires
+		actionChangeThread(ctx)
+	}()
+}
+
+func actionMoveCursorDownThreads(ctx *context.AppContext) {
+	go func() {
+		if scrollTimer != nil {
+			scrollTimer.Stop()
+		}
+
+		ctx.View.Threads.MoveCursorDown()
+
+		scrollTimer = time.NewTimer(time.Second / 4)
+		<-scrollTimer.C
+
+		// Only actually change thread when the timer expires
+		actionChangeThread(ctx)
+	}()
+}
+
+// isUnreadMessage returns whether a message counts as unread, like it's
+// counted by slack. Edits, deletions and replies in threads that aren't
+// sent to the channel aren't counted.
+func isUnreadMessage(ev *slack.MessageEvent) bool {
+	switch ev.SubType {
+	case "", "file_share", "me_message", "thread_broadcast":
+	default:
+		return false
+	}
+
+	return ev.ThreadTimestamp == "" || ev.ThreadTimestamp == ev.Timestamp ||
+		ev.SubType == "thread_broadcast"
+}
+
+// actionMarkedAsRead will remove the unread badge and count of a channel,
+// when it has been read in another client
+func actionMarkedAsRead(ctx *context.AppContext, channelID string) {
+	index := ctx.View.Channels.FindChannel(channelID)
+	if index == -1 {
+		return
+	}
+
+	ctx.View.Channels.MarkAsRead(index)
+	actionRenderChannels(ctx)
+	actionRenderStatus(ctx)
+}
+
+// actionNewMessage will set the new message indicator for a channel, and
+// if configured will also display a desktop notification
+func actionNewMessage(ctx *context.AppContext, ev *slack.MessageEvent, msg components.Message) {
+	if isMention(ctx, ev) {
+		ctx.View.Channels.MarkAsMentioned(ev.Channel)
+	} else {
+		ctx.View.Channels.MarkAsUnread(ev.Channel)
+	}
+	if isUnreadMessage(ev) {
+		ctx.View.Channels.IncrementUnreadCount(ev.Channel)
+	}
+	actionRenderChannels(ctx)
+	actionRenderStatus(ctx)
+
+	// Muted and snoozed channels are marked as unread, without a badge
+	// or notification
+	for _, channel := range ctx.View.Channels.ChannelItems {
+		if channel.ID == ev.Channel && channel.IsSilenced() {
+			return
+		}
+	}
+
+	// Terminal bell
+	fmt.Print("\a")
+
+	// Desktop notification
+	if ctx.Config.Notify == config.NotifyMention {
+		if isMention(ctx, ev) {
+			createNotifyMessage(ctx, ev, msg)
+		}
+	} else if ctx.Config.Notify == config.NotifyAll {
+		createNotifyMessage(ctx, ev, msg)
+	}
+}
+
+// actionToggleMute will mute or unmute the selected channel, muted channels
+// don't show badges and don't create notifications
+func actionToggleMute(ctx *context.AppContext) {
+	if len(ctx.View.Channels.ChannelItems) == 0 {
+		return
+	}
+
+	index := ctx.View.Channels.SelectedChannel
+	channel := ctx.View.Channels.ChannelItems[index]
+
+	if err := ctx.Service.SetMute(channel.ID, !channel.Muted); err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to mute %s: %v", channel.Name, err),
+		)
+		return
+	}
+
+	// Channels that are muted in slack or in the config stay muted
+	muted := ctx.Service.IsMuted(channel)
+	ctx.View.Channels.ChannelItems[index].Muted = muted
+	if channel.Muted && muted {
+		ctx.View.Input.SetStatus(
+			fmt.Sprintf("%s is muted in slack or in muted_channels", channel.Name),
+		)
+		termui.Render(ctx.View.Input)
+	}
+
+	actionRenderChannels(ctx)
+	actionRenderStatus(ctx)
+}
+
+// actionToggleStar will star or unstar the selected channel, starred
+// channels are shown at the top of the channels
+func actionToggleStar(ctx *context.AppContext) {
+	if len(ctx.View.Channels.ChannelItems) == 0 {
+		return
+	}
+
+	channel := ctx.View.Channels.GetSelectedChannel()
+	starred := !channel.Starred
+	if err := ctx.Service.SetStar(gocontext.Background(), channel.ID, starred); err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to star %s: %v", channel.Name, err),
+		)
+		return
+	}
+
+	ctx.View.Channels.SetStarred(channel.ID, starred)
+	actionRenderChannels(ctx)
+}
+
+// actionSetSlackMutes will update which channels are muted, when the
+// muted_channels preference has been changed in slack. The value of the
+// preference is a comma separated list of channel ids.
+func actionSetSlackMutes(ctx *context.AppContext, value json.RawMessage) {
+	var mutedChannels string
+	if err := json.Unmarshal(value, &mutedChannels); err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to update muted channels: %v", err),
+		)
+		return
+	}
+
+	ctx.Service.SetSlackMutes(service.ParseMutedChannels(mutedChannels))
+	for i, channel := range ctx.View.Channels.ChannelItems {
+		ctx.View.Channels.ChannelItems[i].Muted = ctx.Service.IsMuted(channel)
+	}
+
+	actionRenderChannels(ctx)
+	actionRenderStatus(ctx)
+}
+
+// actionSetPresence will set the presence of a user, on the direct message
+// with the user and in the member count of the selected channel
+func actionSetPresence(ctx *context.AppContext, userID string, userPresence string) {
+	presenceMu.Lock()
+	presence[userID] = userPresence
+	isMember := false
+	for _, memberID := range members.userIDs {
+		if memberID == userID {
+			isMember = true
+			break
+		}
+	}
+	presenceMu.Unlock()
+
+	for _, chn := range ctx.View.Channels.ChannelItems {
+		if chn.Type == components.ChannelTypeIM && chn.UserID == userID {
+			ctx.View.Channels.SetPresence(chn.ID, userPresence)
+			actionRenderChannels(ctx)
+			break
+		}
+	}
+
+	if isMember {
+		actionRenderChatLabel(ctx)
+	}
+
+	if ctx.View.Members.SetPresence(userID, userPresence) && ctx.Mode == context.MembersMode {
+		termui.Render(ctx.View.Members)
+	}
+}
+
+// actionGetChannelMembers will get the members of a channel, and subscribe
+// to their presence changes
+func actionGetChannelMembers(ctx *context.AppContext, reqCtx gocontext.Context, channelID string) {
+	userIDs, err := ctx.Service.GetChannelMembers(reqCtx, channelID)
+	if err != nil {
+		if reqCtx.Err() == nil {
+			ctx.View.Debug.Println(
+				fmt.Sprintf("unable to get members: %v", err),
+			)
+		}
+		return
+	}
+
+	// Another channel has been selected in the meantime
+	if reqCtx.Err() != nil {
+		return
+	}
+
+	presenceMu.Lock()
+	members.channelID = channelID
+	members.userIDs = userIDs
+	presenceMu.Unlock()
+
+	ctx.Service.SubscribePresence(userIDs)
+	actionRenderChatLabel(ctx)
+}
+
+// actionGetPostingPolicy will get whether the current user is allowed to
+// post in the selected channel
+func actionGetPostingPolicy(ctx *context.AppContext, reqCtx gocontext.Context, channelID string) {
+	policy, err := ctx.Service.GetPostingPolicy(reqCtx, channelID)
+	if err != nil {
+		if reqCtx.Err() == nil {
+			ctx.View.Debug.Println(
+				fmt.Sprintf("unable to get posting policy: %v", err),
+			)
+		}
+		return
 	}
 
-	// Redraw grid, necessary when threads and/or debug is set. We will redraw
-	// the grid when there are threads, or we just came from a thread and went
-	// to a channel without threads. Hence the clearing of ChannelItems of
-	// Threads.
-	if haveThreads {
-		actionRedrawGrid(ctx, haveThreads, ctx.Debug)
-	} else if !haveThreads && len(ctx.View.Threads.ChannelItems) > 0 {
-		ctx.View.Threads.SetChannels([]components.ChannelItem{})
-		actionRedrawGrid(ctx, haveThreads, ctx.Debug)
-	} else {
-		termui.Render(ctx.View.Threads)
-		termui.Render(ctx.View.Channels)
-		termui.Render(ctx.View.Chat)
+	// Another channel has been selected in the meantime
+	if reqCtx.Err() != nil {
+		return
 	}
 
-	// Set focus, necessary to know when replying to thread or chat
-	ctx.Focus = context.ChatFocus
+	posting.mu.Lock()
+	posting.channelID = channelID
+	posting.policy = policy
+	posting.mu.Unlock()
 }
 
-func actionChangeThread(ctx *context.AppContext) {
-	// Clear messages from Chat pane
-	ctx.View.Chat.ClearMessages()
+// actionCanPost returns whether a message can be posted in the selected
+// channel or thread, when it can't the status explains why. When the posting
+// policy isn't known yet posting is allowed.
+func actionCanPost(ctx *context.AppContext) (string, bool) {
+	posting.mu.Lock()
+	defer posting.mu.Unlock()
 
-	// The first channel in the Thread list is current Channel. Set context
-	// Focus and messages accordingly.
-	var err error
-	msgs := []components.Message{}
-	if ctx.View.Threads.SelectedChannel == 0 {
-		ctx.Focus = context.ChatFocus
+	if posting.channelID != ctx.View.Channels.GetSelectedChannel().ID {
+		return "", true
+	}
 
-		msgs, _, err = ctx.Service.GetMessages(
-			ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID,
-			ctx.View.Chat.GetMaxItems(),
-			1,
-		)
-		if err != nil {
-			termbox.Close()
-			log.Println(err)
-			os.Exit(0)
+	if ctx.Focus == context.ThreadFocus {
+		if posting.policy.ThreadsReadOnly {
+			return "replies can't be posted in the threads of this channel", false
 		}
-	} else {
-		ctx.Focus = context.ThreadFocus
+		return "", true
+	}
 
-		msgs, err = ctx.Service.GetMessageByID(
-			ctx.View.Threads.ChannelItems[ctx.View.Threads.SelectedChannel].ID,
-			ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel].ID,
+	if posting.policy.ReadOnly {
+		if !posting.policy.ThreadsReadOnly {
+			return "this channel is read-only, select a thread to reply", false
+		}
+		return "this channel is read-only", false
+	}
+
+	return "", true
+}
+
+// actionRenderChatLabel will set the header of the Chat component to the
+// selected channel, and its label to the name of the channel followed by
+// the number of its members and how many of them are online, e.g.
+// "general · 42 members · 7 online"
+func actionRenderChatLabel(ctx *context.AppContext) {
+	channel := ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel]
+	label := channel.GetName()
+
+	presenceMu.Lock()
+	if channel.Type != components.ChannelTypeIM && members.channelID == channel.ID {
+		online := 0
+		for _, userID := range members.userIDs {
+			if presence[userID] == "active" {
+				online++
+			}
+		}
+		label = fmt.Sprintf(
+			"%s · %d members · %d online",
+			label, len(members.userIDs), online,
 		)
-		if err != nil {
-			termbox.Close()
-			log.Println(err)
-			os.Exit(0)
+	}
+	presenceMu.Unlock()
+
+	ctx.View.Chat.SetHeader(channel)
+	ctx.View.Chat.SetBorderLabel(label)
+	actionSetChatBorder(ctx)
+	actionRenderChat(ctx)
+}
+
+// actionSetTopic will set the topic of a channel, and update the header
+// of the Chat component when it's the selected channel
+func actionSetTopic(ctx *context.AppContext, channelID string, topic string) {
+	for i, channel := range ctx.View.Channels.ChannelItems {
+		if channel.ID == channelID {
+			ctx.View.Channels.ChannelItems[i].Topic = topic
+
+			if i == ctx.View.Channels.SelectedChannel && !isBrowsing(ctx) {
+				actionRenderChatLabel(ctx)
+			}
+			return
 		}
 	}
+}
 
-	// Set messages for the channel
-	ctx.View.Chat.SetMessages(msgs)
+// actionPresenceAll will set the presence of the user list. Because the
+// requests to the endpoint are rate limited we implement a timeout here.
+func actionSetPresenceAll(ctx *context.AppContext) {
+	for _, chn := range ctx.Service.GetConversations() {
+		if chn.IsIM {
 
-	termui.Render(ctx.View.Channels)
-	termui.Render(ctx.View.Threads)
-	termui.Render(ctx.View.Chat)
+			userPresence, err := ctx.Service.GetUserPresence(gocontext.Background(), chn.User)
+			if err != nil {
+				userPresence = "away"
+			}
+			actionSetPresence(ctx, chn.User, userPresence)
+
+			time.Sleep(1200 * time.Millisecond)
+		}
+	}
 }
 
-func actionMoveCursorUpThreads(ctx *context.AppContext) {
-	go func() {
-		if scrollTimer != nil {
-			scrollTimer.Stop()
+// actionLoadUnreadCounts will fetch the unread counts of all the
+// conversations, and mark the channels with unread messages as they come in.
+func actionLoadUnreadCounts(ctx *context.AppContext) {
+	var channelIDs []string
+	for _, chn := range ctx.Service.GetConversations() {
+		channelIDs = append(channelIDs, chn.ID)
+	}
+
+	for result := range ctx.Service.GetUnreadCounts(gocontext.Background(), channelIDs, unreadWorkers) {
+		if result.Err != nil {
+			ctx.View.Debug.Println(
+				fmt.Sprintf("unable to get unread count of channel %s: %v", result.ChannelID, result.Err),
+			)
+			continue
 		}
 
-		ctx.View.Threads.MoveCursorUp()
-		termui.Render(ctx.View.Threads)
+		// The selected channel is being read
+		if result.Count == 0 || result.ChannelID == ctx.View.Channels.GetSelectedChannel().ID {
+			continue
+		}
 
-		scrollTimer = time.NewTimer(time.Second / 4)
-		<-scrollTimer.C
+		// The channel has been left in the meantime
+		index := ctx.View.Channels.FindChannel(result.ChannelID)
+		if index == -1 {
+			continue
+		}
 
-		// Only actually change channel when the timer expires
-		actionChangeThread(ctx)
-	}()
+		ctx.View.Channels.SetUnreadCount(result.ChannelID, result.Count)
+
+		// Every message in a direct message is a mention
+		channel := ctx.View.Channels.ChannelItems[index]
+		if channel.Type == components.ChannelTypeIM {
+			ctx.View.Channels.MarkAsMentioned(result.ChannelID)
+		} else {
+			ctx.View.Channels.MarkAsUnread(result.ChannelID)
+		}
+		actionRenderChannels(ctx)
+		actionRenderStatus(ctx)
+	}
 }
 
-func actionMoveCursorDownThreads(ctx *context.AppContext) {
-	go func() {
-		if scrollTimer != nil {
-			scrollTimer.Stop()
+// actionLoadChannels will load the remaining pages of conversations, and
+// add them to the sidebar as they come in. It returns once all the pages
+// have been added.
+func actionLoadChannels(ctx *context.AppContext) {
+	cursor := ctx.View.ChannelsCursor
+	if cursor == "" {
+		return
+	}
+
+	taskCtx, t := actionStartTask(
+		ctx, ctx.View.ChannelsSpinner, gocontext.Background(), "loading channels",
+	)
+
+	var loaded int
+	for cursor != "" {
+		channels, next, err := ctx.Service.GetChannelsPage(taskCtx, cursor)
+		if err != nil {
+			actionStopTask(ctx, t)
+			if taskCtx.Err() != nil {
+				return
+			}
+
+			ctx.View.Debug.Println(
+				fmt.Sprintf("unable to load channels: %v", err),
+			)
+			return
 		}
+		cursor = next
 
-		ctx.View.Threads.MoveCursorDown()
-		termui.Render(ctx.View.Threads)
+		// The pages are added on the goroutine that handles the keys,
+		// the channels are used by them
+		ctx.ActionQueue <- func() {
+			ctx.View.Channels.AddChannels(channels)
+			ctx.View.ChannelsCursor = next
+			actionRenderChannels(ctx)
+		}
 
-		scrollTimer = time.NewTimer(time.Second / 4)
-		<-scrollTimer.C
+		loaded += len(channels)
+		actionSetTaskProgress(ctx, t, fmt.Sprintf("(%d)", loaded))
+	}
+	actionStopTask(ctx, t)
 
-		// Only actually change thread when the timer expires
-		actionChangeThread(ctx)
-	}()
+	// Wait for the pages to be added, the presence and unread counts of
+	// the channels are loaded next
+	added := make(chan struct{})
+	ctx.ActionQueue <- func() {
+		close(added)
+	}
+	<-added
 }
 
-// actionNewMessage will set the new message indicator for a channel, and
-// if configured will also display a desktop notification
-func actionNewMessage(ctx *context.AppContext, ev *slack.MessageEvent) {
-	ctx.View.Channels.MarkAsUnread(ev.Channel)
-	termui.Render(ctx.View.Channels)
+// actionRefreshChannels will periodically fetch the conversations, and add
+// new and remove archived channels from the sidebar.
+func actionRefreshChannels(ctx *context.AppContext) {
+	if ctx.Config.ChannelRefresh == 0 {
+		return
+	}
 
-	// Terminal bell
-	fmt.Print("\a")
+	ticker := time.NewTicker(time.Duration(ctx.Config.ChannelRefresh) * time.Minute)
+	for range ticker.C {
+		taskCtx, t := actionStartTask(
+			ctx, ctx.View.ChannelsSpinner, gocontext.Background(), "refreshing channels",
+		)
+		channels, err := ctx.Service.GetChannels(taskCtx)
+		actionStopTask(ctx, t)
+		if err != nil {
+			// The refresh has been cancelled in the Tasks popup, the
+			// context of a task is done once it has stopped
+			if taskCtx.Err() != nil {
+				continue
+			}
 
-	// Desktop notification
-	if ctx.Config.Notify == config.NotifyMention {
-		if isMention(ctx, ev) {
-			createNotifyMessage(ctx, ev)
+			ctx.View.Debug.Println(
+				fmt.Sprintf("unable to refresh channels: %v", err),
+			)
+			continue
+		}
+
+		// The sidebar is updated on the goroutine that handles the
+		// keys, the selection is used by them
+		ctx.ActionQueue <- func() {
+			// When the selected channel has been left or archived
+			// elsewhere, the channel that took its place is shown
+			if !ctx.View.Channels.UpdateChannels(channels) &&
+				len(ctx.View.Channels.ChannelItems) > 0 {
+				ctx.View.Channels.GotoPosition(ctx.View.Channels.SelectedChannel)
+				actionChangeChannel(ctx)
+			}
+			actionRenderChannels(ctx)
 		}
-	} else if ctx.Config.Notify == config.NotifyAll {
-		createNotifyMessage(ctx, ev)
 	}
 }
 
-func actionSetPresence(ctx *context.AppContext, channelID string, presence string) {
-	ctx.View.Channels.SetPresence(channelID, presence)
-	termui.Render(ctx.View.Channels)
+// actionActivity will restart the idle timer, when the user was set as
+// away because of inactivity the presence is set back to auto. This is
+// disabled when away_after is 0.
+func actionActivity(ctx *context.AppContext) {
+	if ctx.Config.AwayAfter == 0 {
+		return
+	}
+
+	awayMu.Lock()
+	defer awayMu.Unlock()
+
+	if idleTimer != nil {
+		idleTimer.Stop()
+	}
+	idleTimer = time.AfterFunc(
+		time.Duration(ctx.Config.AwayAfter)*time.Minute,
+		func() { actionSetAway(ctx) },
+	)
+
+	if away {
+		away = false
+		go actionSetUserPresence(ctx, "auto")
+	}
 }
 
-// actionPresenceAll will set the presence of the user list. Because the
-// requests to the endpoint are rate limited we implement a timeout here.
-func actionSetPresenceAll(ctx *context.AppContext) {
-	for _, chn := range ctx.Service.Conversations {
-		if chn.IsIM {
+// actionSetAway will set the user as away because of inactivity
+func actionSetAway(ctx *context.AppContext) {
+	awayMu.Lock()
+	away = true
+	awayMu.Unlock()
 
-			presence, err := ctx.Service.GetUserPresence(chn.User)
-			if err != nil {
-				presence = "away"
-			}
-			ctx.View.Channels.SetPresence(chn.ID, presence)
+	actionSetUserPresence(ctx, "away")
+}
 
-			termui.Render(ctx.View.Channels)
-			time.Sleep(1200 * time.Millisecond)
+// actionLoadDoNotDisturb will get the do not disturb status of the user for
+// the auto-responder, it's kept up to date by the events
+func actionLoadDoNotDisturb(ctx *context.AppContext) {
+	if ctx.Config.AutoReply == "" {
+		return
+	}
+
+	status, err := ctx.Service.GetDoNotDisturb(gocontext.Background())
+	if err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to get do not disturb status: %v", err),
+		)
+		return
+	}
+
+	autoReply.mu.Lock()
+	autoReply.dnd = status
+	autoReply.mu.Unlock()
+}
+
+// teamPlans are the names of the paid plans of a workspace, by the plan that
+// team.info returns
+var teamPlans = map[string]string{
+	"std":        "Pro",
+	"plus":       "Business+",
+	"compliance": "Enterprise",
+	"enterprise": "Enterprise",
+}
+
+// actionLoadTeam will show the name of the workspace in the border of the
+// sidebar instead of "Channels", with its plan, e.g. "acme · Business+".
+// Workspaces of an Enterprise Grid organization show the organization,
+// e.g. "acme · Acme Corp (Enterprise)".
+func actionLoadTeam(ctx *context.AppContext) {
+	team, err := ctx.Service.GetCurrentTeam(gocontext.Background())
+	if err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to get workspace info: %v", err),
+		)
+		return
+	}
+	if team.Name == "" {
+		return
+	}
+
+	title := team.Name
+	if team.EnterpriseID != "" {
+		enterprise := team.EnterpriseName
+		if enterprise == "" {
+			enterprise = team.EnterpriseID
+		}
+		title = fmt.Sprintf("%s · %s (Enterprise)", title, enterprise)
+	} else if plan, ok := teamPlans[team.Plan]; ok {
+		title = fmt.Sprintf("%s · %s", title, plan)
+	}
+
+	ctx.View.Channels.SetTitle(title)
+	actionRenderChannels(ctx)
+}
+
+// isDoNotDisturb returns whether notifications are paused by the do not
+// disturb status at now
+func isDoNotDisturb(status slack.DNDStatus, now time.Time) bool {
+	if status.SnoozeEnabled && int64(status.SnoozeEndTime) > now.Unix() {
+		return true
+	}
+
+	return status.Enabled &&
+		int64(status.NextStartTimestamp) <= now.Unix() &&
+		now.Unix() < int64(status.NextEndTimestamp)
+}
+
+// actionAutoReply will reply to a direct message with the auto_reply
+// message, when the user is away because of inactivity or do not disturb
+// is active. Every sender is replied to once per auto_reply_cooldown, and
+// messages of bots aren't replied to so that auto-responders don't reply
+// to each other.
+func actionAutoReply(ctx *context.AppContext, ev *slack.MessageEvent) {
+	if ctx.Config.AutoReply == "" || ev.SubType != "" || ev.BotID != "" || ev.User == "" {
+		return
+	}
+
+	index := ctx.View.Channels.FindChannel(ev.Channel)
+	if index == -1 || ctx.View.Channels.ChannelItems[index].Type != components.ChannelTypeIM {
+		return
+	}
+
+	awayMu.Lock()
+	isAway := away
+	awayMu.Unlock()
+
+	now := time.Now()
+	cooldown := time.Duration(ctx.Config.AutoReplyCooldown) * time.Minute
+
+	autoReply.mu.Lock()
+	if !isAway && !isDoNotDisturb(autoReply.dnd, now) {
+		autoReply.mu.Unlock()
+		return
+	}
+	if now.Sub(autoReply.replied[ev.User]) < cooldown {
+		autoReply.mu.Unlock()
+		return
+	}
+	if autoReply.replied == nil {
+		autoReply.replied = make(map[string]time.Time)
+	}
+	autoReply.replied[ev.User] = now
+	autoReply.mu.Unlock()
+
+	go func() {
+		err := ctx.Service.SendMessage(gocontext.Background(), ev.Channel, ctx.Config.AutoReply)
+		if err != nil {
+			ctx.View.Debug.Println(
+				fmt.Sprintf("unable to send auto reply: %v", err),
+			)
 		}
+	}()
+}
+
+func actionSetUserPresence(ctx *context.AppContext, presence string) {
+	err := ctx.Service.SetUserPresence(gocontext.Background(), presence)
+	if err != nil {
+		ctx.View.Debug.Println(
+			fmt.Sprintf("unable to set presence to %s: %v", presence, err),
+		)
 	}
 }
 
@@ -679,7 +5102,147 @@ func actionScrollDownChat(ctx *context.AppContext) {
 	termui.Render(ctx.View.Chat)
 }
 
+// actionScrollBottomChat will jump to the latest message of the Chat pane,
+// and mark the channel as read
+func actionScrollBottomChat(ctx *context.AppContext) {
+	ctx.View.Chat.ScrollToBottom()
+	actionRenderChat(ctx)
+
+	channelItem := ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel]
+	if channelItem.Notification {
+		ctx.Service.MarkAsRead(gocontext.Background(), channelItem)
+		ctx.View.Channels.MarkAsRead(ctx.View.Channels.SelectedChannel)
+		actionRenderChannels(ctx)
+		actionRenderStatus(ctx)
+	}
+}
+
+// actionScrollBottom will move to the bottom of the pane that has focus
+func actionScrollBottom(ctx *context.AppContext) {
+	switch ctx.Focus {
+	case context.ChannelsFocus:
+		actionMoveCursorBottomChannels(ctx)
+	case context.ChatFocus:
+		actionScrollBottomChat(ctx)
+	case context.ThreadFocus:
+		ctx.View.Threads.ScrollToBottom()
+		termui.Render(ctx.View.Threads)
+	}
+}
+
+// actionScrollUp will scroll up the pane that has focus
+func actionScrollUp(ctx *context.AppContext) {
+	switch ctx.Focus {
+	case context.ChannelsFocus:
+		for i := 0; i < ctx.View.Channels.List.InnerHeight(); i++ {
+			ctx.View.Channels.MoveCursorUp()
+		}
+		actionRenderChannels(ctx)
+	case context.ChatFocus:
+		actionScrollUpChat(ctx)
+	case context.ThreadFocus:
+		actionScrollUpThreads(ctx)
+	}
+}
+
+// actionScrollDown will scroll down the pane that has focus
+func actionScrollDown(ctx *context.AppContext) {
+	switch ctx.Focus {
+	case context.ChannelsFocus:
+		for i := 0; i < ctx.View.Channels.List.InnerHeight(); i++ {
+			ctx.View.Channels.MoveCursorDown()
+		}
+		actionRenderChannels(ctx)
+	case context.ChatFocus:
+		actionScrollDownChat(ctx)
+	case context.ThreadFocus:
+		actionScrollDownThreads(ctx)
+	}
+}
+
+// actionFocusNext will move the focus to the next pane, in the order
+// channels, chat, threads and input. The Threads pane is skipped when it
+// isn't shown, and focusing the Input switches to insert mode.
+func actionFocusNext(ctx *context.AppContext) {
+	switch ctx.Focus {
+	case context.ChannelsFocus:
+		ctx.Focus = context.ChatFocus
+	case context.ChatFocus:
+		if ctx.View.Threads.HasThreads() {
+			ctx.Focus = context.ThreadFocus
+		} else {
+			actionInsertMode(ctx)
+			return
+		}
+	case context.ThreadFocus:
+		actionInsertMode(ctx)
+		return
+	}
+
+	actionRenderFocus(ctx)
+}
+
+// actionFocusPrev will move the focus to the previous pane, from the
+// channels it wraps around to the input
+func actionFocusPrev(ctx *context.AppContext) {
+	switch ctx.Focus {
+	case context.ChannelsFocus:
+		actionInsertMode(ctx)
+		return
+	case context.ChatFocus:
+		ctx.Focus = context.ChannelsFocus
+	case context.ThreadFocus:
+		ctx.Focus = context.ChatFocus
+	}
+
+	actionRenderFocus(ctx)
+}
+
+// actionSetChatBorder will set the border of the Chat pane to the style of
+// the selected channel, unless the pane has focus
+func actionSetChatBorder(ctx *context.AppContext) {
+	channel := ctx.View.Channels.ChannelItems[ctx.View.Channels.SelectedChannel]
+
+	borderFg := termui.ThemeAttr("border.fg")
+	if channel.StyleBorder != "" {
+		borderFg = termui.StringToAttribute(channel.StyleBorder)
+	}
+	if ctx.Mode != context.InsertMode && ctx.Focus == context.ChatFocus {
+		borderFg = termui.ThemeAttr("focus.fg")
+	}
+
+	ctx.View.Chat.List.BorderFg = borderFg
+}
+
+// actionRenderFocus will highlight the border of the pane that has focus
+func actionRenderFocus(ctx *context.AppContext) {
+	borderFg := termui.ThemeAttr("border.fg")
+	focusFg := termui.ThemeAttr("focus.fg")
+
+	ctx.View.Channels.List.BorderFg = borderFg
+	ctx.View.Threads.List.BorderFg = borderFg
+	ctx.View.Input.Par.BorderFg = borderFg
+	actionSetChatBorder(ctx)
+
+	switch {
+	case ctx.Mode == context.InsertMode:
+		ctx.View.Input.Par.BorderFg = focusFg
+	case ctx.Focus == context.ChannelsFocus:
+		ctx.View.Channels.List.BorderFg = focusFg
+	case ctx.Focus == context.ThreadFocus:
+		ctx.View.Threads.List.BorderFg = focusFg
+	}
+
+	actionRenderChannels(ctx)
+	actionRenderChat(ctx)
+	if ctx.View.Threads.HasThreads() {
+		termui.Render(ctx.View.Threads)
+	}
+	termui.Render(ctx.View.Input)
+}
+
 func actionHelp(ctx *context.AppContext) {
+	actionLeaveConversation(ctx)
 	ctx.View.Chat.ClearMessages()
 	ctx.View.Chat.Help(ctx.Usage, ctx.Config)
 	termui.Render(ctx.View.Chat)
@@ -712,7 +5275,7 @@ func getKeyString(e termbox.Event) string {
 
 		if e.Key <= 0x7F {
 			pre = "C-"
-			k = string('a' - 1 + int(e.Key))
+			k = string(rune('a' - 1 + int(e.Key)))
 			kmap := map[termbox.Key][2]string{
 				termbox.KeyCtrlSpace:     {"C-", "<space>"},
 				termbox.KeyBackspace:     {"", "<backspace>"},
@@ -738,9 +5301,8 @@ func getKeyString(e termbox.Event) string {
 // isMention check if the message event either contains a
 // mention or is posted on an IM channel.
 func isMention(ctx *context.AppContext, ev *slack.MessageEvent) bool {
-	channel := ctx.View.Channels.ChannelItems[ctx.View.Channels.FindChannel(ev.Channel)]
-
-	if channel.Type == components.ChannelTypeIM {
+	index := ctx.View.Channels.FindChannel(ev.Channel)
+	if index != -1 && ctx.View.Channels.ChannelItems[index].Type == components.ChannelTypeIM {
 		return true
 	}
 
@@ -750,7 +5312,7 @@ func isMention(ctx *context.AppContext, ev *slack.MessageEvent) bool {
 	r := regexp.MustCompile(`\<@(\w+\|*\w+)\>`)
 	matches := r.FindAllString(ev.Text, -1)
 	for _, match := range matches {
-		if strings.Contains(match, ctx.Service.CurrentUserID) {
+		if strings.Contains(match, ctx.Service.GetCurrentUserID()) {
 			return true
 		}
 	}
@@ -758,7 +5320,37 @@ func isMention(ctx *context.AppContext, ev *slack.MessageEvent) bool {
 	return false
 }
 
-func createNotifyMessage(ctx *context.AppContext, ev *slack.MessageEvent) {
+// notifyOpen returns the function that is called when a notification is
+// clicked. It's called by the notifier, so the channel with channelID is
+// selected on the goroutine that handles the keys.
+func notifyOpen(ctx *context.AppContext, channelID string) func() {
+	return func() {
+		ctx.ActionQueue <- func() {
+			if ctx.View.Channels.GotoChannel(channelID) {
+				actionChangeChannel(ctx)
+			}
+		}
+	}
+}
+
+// createNotifyMessage will show a desktop notification with the channel and
+// author of the message in the title. The content of the message is only
+// shown when notify_preview is set.
+func createNotifyMessage(ctx *context.AppContext, ev *slack.MessageEvent, msg components.Message) {
+	// The title is shown without the channel when it isn't in the sidebar
+	title := msg.Name
+	if index := ctx.View.Channels.FindChannel(ev.Channel); index != -1 {
+		channel := ctx.View.Channels.ChannelItems[index]
+		switch channel.Type {
+		case components.ChannelTypeChannel, components.ChannelTypeGroup:
+			title = fmt.Sprintf("%s in #%s", msg.Name, channel.Name)
+		case components.ChannelTypeIM:
+			title = msg.Name
+		default:
+			title = fmt.Sprintf("%s in %s", msg.Name, channel.Name)
+		}
+	}
+
 	go func() {
 		if notifyTimer != nil {
 			notifyTimer.Stop()
@@ -768,19 +5360,17 @@ func createNotifyMessage(ctx *context.AppContext, ev *slack.MessageEvent) {
 		notifyTimer = time.NewTimer(time.Second * 2)
 		<-notifyTimer.C
 
-		var message string
-		channel := ctx.View.Channels.ChannelItems[ctx.View.Channels.FindChannel(ev.Channel)]
-		switch channel.Type {
-		case components.ChannelTypeChannel:
-			message = fmt.Sprintf("Message received on channel: %s", channel.Name)
-		case components.ChannelTypeGroup:
-			message = fmt.Sprintf("Message received in group: %s", channel.Name)
-		case components.ChannelTypeIM:
-			message = fmt.Sprintf("Message received from: %s", channel.Name)
-		default:
-			message = fmt.Sprintf("Message received from: %s", channel.Name)
+		message := "New message"
+		if ctx.Config.NotifyPreview {
+			message = msg.Content
 		}
 
-		ctx.Notify.Push("slack-term", message, "", notificator.UR_NORMAL)
+		// Clicking the notification will select the channel
+		open := notifyOpen(ctx, ev.Channel)
+		if err := ctx.Notify.Push(title, message, open); err != nil {
+			ctx.View.Debug.Println(
+				err.Error(),
+			)
+		}
 	}()
 }
@@ -0,0 +1,371 @@
+package handlers
+
+import (
+	gocontext "context"
+	"io/ioutil"
+	"os"
+	fp "path/filepath"
+	"testing"
+	"time"
+
+	termbox "github.com/nsf/termbox-go"
+	"github.com/slack-go/slack"
+
+	"github.com/erroneousboat/slack-term/components"
+	"github.com/erroneousboat/slack-term/config"
+	"github.com/erroneousboat/slack-term/context"
+	"github.com/erroneousboat/slack-term/service"
+	"github.com/erroneousboat/slack-term/tasks"
+	"github.com/erroneousboat/slack-term/views"
+)
+
+// newTestContext creates the context of the application with the view of
+// the FakeService, with the default config
+func newTestContext(t *testing.T, svc *service.FakeService) *context.AppContext {
+	dir, err := ioutil.TempDir("", "slack-term")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg, err := config.NewConfig(fp.Join(dir, "config"))
+	if err != nil {
+		t.Fatalf("NewConfig: %v", err)
+	}
+	cfg.HistoryCount = 50
+
+	view, err := views.CreateView(cfg, svc, &views.Progress{})
+	if err != nil {
+		t.Fatalf("CreateView: %v", err)
+	}
+
+	return &context.AppContext{
+		EventQueue:    make(chan termbox.Event, 20),
+		Service:       svc,
+		View:          view,
+		Config:        cfg,
+		Mode:          context.CommandMode,
+		Tasks:         tasks.NewManager(),
+		Conversations: context.NewConversations(),
+		ActionQueue:   make(chan func(), 20),
+	}
+}
+
+// newTestService creates a FakeService with the channels general and random,
+// that have a message each
+func newTestService() *service.FakeService {
+	svc := service.NewFakeService("U1", []components.ChannelItem{
+		{ID: "C1", Name: "general", Type: components.ChannelTypeChannel},
+		{ID: "C2", Name: "random", Type: components.ChannelTypeChannel},
+	})
+	svc.AddMessage("C1", "", "U2", "hello general")
+	svc.AddMessage("C2", "", "U2", "hello random")
+
+	return svc
+}
+
+// runAction will run the next action that is queued to run on the goroutine
+// that handles the keys, e.g. showing the history of a channel
+func runAction(t *testing.T, ctx *context.AppContext) {
+	nextAction(t, ctx)()
+}
+
+// nextAction waits for the next action that is queued to run on the
+// goroutine that handles the keys, and returns it without running it
+func nextAction(t *testing.T, ctx *context.AppContext) func() {
+	select {
+	case action := <-ctx.ActionQueue:
+		return action
+	case <-time.After(5 * time.Second):
+		t.Fatal("no action has been queued")
+	}
+	return nil
+}
+
+// hasChannel returns whether the channel is shown in the sidebar
+func hasChannel(ctx *context.AppContext, channelID string) bool {
+	for _, channel := range ctx.View.Channels.ChannelItems {
+		if channel.ID == channelID {
+			return true
+		}
+	}
+	return false
+}
+
+// chatContents returns the contents of the messages in the Chat pane
+func chatContents(ctx *context.AppContext) map[string]bool {
+	contents := make(map[string]bool)
+	for _, msg := range ctx.View.Chat.Messages {
+		contents[msg.Content] = true
+	}
+	return contents
+}
+
+func TestChangeChannel(t *testing.T) {
+	svc := newTestService()
+	ctx := newTestContext(t, svc)
+
+	if contents := chatContents(ctx); !contents["hello general"] {
+		t.Fatalf("Chat pane shows %v, expected the messages of general", contents)
+	}
+
+	ctx.View.Channels.SetSelectedChannel(1)
+	actionChangeChannel(ctx)
+	runAction(t, ctx)
+
+	contents := chatContents(ctx)
+	if !contents["hello random"] || contents["hello general"] {
+		t.Errorf("Chat pane shows %v, expected the messages of random", contents)
+	}
+}
+
+func TestChangeChannelAgain(t *testing.T) {
+	svc := newTestService()
+	ctx := newTestContext(t, svc)
+
+	// The history of random arrives after general has been selected
+	// again, it isn't shown
+	ctx.View.Channels.SetSelectedChannel(1)
+	actionChangeChannel(ctx)
+	showRandom := nextAction(t, ctx)
+
+	ctx.View.Channels.SetSelectedChannel(0)
+	actionChangeChannel(ctx)
+	runAction(t, ctx)
+	showRandom()
+
+	contents := chatContents(ctx)
+	if !contents["hello general"] || contents["hello random"] {
+		t.Errorf("Chat pane shows %v, expected the messages of general", contents)
+	}
+}
+
+func TestChangeChannelMarksAsRead(t *testing.T) {
+	svc := newTestService()
+	svc.Unread["C2"] = 1
+	ctx := newTestContext(t, svc)
+	ctx.View.Channels.ChannelItems[1].Notification = true
+	ctx.View.Channels.ChannelItems[1].UnreadCount = 1
+
+	ctx.View.Channels.SetSelectedChannel(1)
+	actionChangeChannel(ctx)
+	runAction(t, ctx)
+
+	if channel := ctx.View.Channels.ChannelItems[1]; channel.Notification || channel.UnreadCount != 0 {
+		t.Errorf("random is shown as unread after it has been opened")
+	}
+	if _, ok := svc.Unread["C2"]; ok {
+		t.Errorf("random isn't marked as read in the service")
+	}
+}
+
+func TestMarkedAsReadEvent(t *testing.T) {
+	svc := newTestService()
+	ctx := newTestContext(t, svc)
+	ctx.View.Channels.ChannelItems[1].Notification = true
+
+	// The channel has been read in another client
+	ev := &slack.ChannelMarkedEvent{}
+	ev.Channel = "C2"
+	handleRTMEvent(ctx, slack.RTMEvent{Type: "channel_marked", Data: ev})
+
+	if ctx.View.Channels.ChannelItems[1].Notification {
+		t.Errorf("random is shown as unread after it has been read elsewhere")
+	}
+}
+
+func TestMessageEvents(t *testing.T) {
+	svc := newTestService()
+	ctx := newTestContext(t, svc)
+
+	// A message that is sent is received again as an event
+	if err := svc.SendMessage(gocontext.Background(), "C1", "sent"); err != nil {
+		t.Fatalf("SendMessage: %v", err)
+	}
+	sent := <-svc.IncomingEvents()
+	handleRTMEvent(ctx, sent)
+
+	if contents := chatContents(ctx); !contents["sent"] {
+		t.Fatalf("Chat pane shows %v, expected the sent message", contents)
+	}
+
+	// A message in a channel that isn't selected marks it as unread
+	msg := svc.AddMessage("C2", "", "U2", "elsewhere")
+	ev := &slack.MessageEvent{}
+	ev.Channel = "C2"
+	ev.User = "U2"
+	ev.Text = msg.Content
+	ev.Timestamp = msg.ID
+	handleRTMEvent(ctx, slack.RTMEvent{Type: "message", Data: ev})
+
+	if contents := chatContents(ctx); contents["elsewhere"] {
+		t.Errorf("Chat pane shows %v, expected the message of random to be left out", contents)
+	}
+	if !ctx.View.Channels.ChannelItems[1].Notification {
+		t.Errorf("random isn't shown as unread after a message has been received")
+	}
+
+	// A message that is deleted is removed
+	deleted := &slack.MessageEvent{}
+	deleted.Channel = "C1"
+	deleted.SubType = "message_deleted"
+	deleted.DeletedTimestamp = sent.Data.(*slack.MessageEvent).Timestamp
+	handleRTMEvent(ctx, slack.RTMEvent{Type: "message", Data: deleted})
+
+	if contents := chatContents(ctx); contents["sent"] {
+		t.Errorf("Chat pane shows %v, expected the deleted message to be removed", contents)
+	}
+}
+
+func TestLoadReplies(t *testing.T) {
+	svc := newTestService()
+	parent := svc.AddMessage("C2", "", "U2", "thread")
+	svc.AddMessage("C2", parent.ID, "U3", "reply")
+	ctx := newTestContext(t, svc)
+
+	ctx.View.Channels.SetSelectedChannel(1)
+	actionChangeChannel(ctx)
+	runAction(t, ctx)
+
+	// The replies of the thread that is shown are loaded after the history
+	if ctx.View.Chat.Messages[parent.ID].RepliesLoaded {
+		t.Fatal("replies are loaded before their action has run")
+	}
+	runAction(t, ctx)
+
+	thread := ctx.View.Chat.Messages[parent.ID]
+	if !thread.RepliesLoaded || len(thread.Messages) != 1 {
+		t.Errorf("thread has %d replies loaded, expected 1", len(thread.Messages))
+	}
+}
+
+func TestReconnected(t *testing.T) {
+	svc := newTestService()
+	ctx := newTestContext(t, svc)
+	rtmConnected = true
+
+	// The message has been posted while the connection was lost
+	svc.AddMessage("C1", "", "U2", "missed")
+	handleRTMEvent(ctx, slack.RTMEvent{Type: "connected", Data: &slack.ConnectedEvent{}})
+	runAction(t, ctx)
+	runAction(t, ctx)
+
+	if contents := chatContents(ctx); !contents["missed"] {
+		t.Errorf("Chat pane shows %v, expected the missed message", contents)
+	}
+}
+
+func TestReconnectedWithoutChannels(t *testing.T) {
+	svc := newTestService()
+	ctx := newTestContext(t, svc)
+	rtmConnected = true
+
+	// All the channels have been left
+	ctx.View.Channels.SetChannels(nil)
+	handleRTMEvent(ctx, slack.RTMEvent{Type: "connected", Data: &slack.ConnectedEvent{}})
+	runAction(t, ctx)
+
+	select {
+	case <-ctx.ActionQueue:
+		t.Error("missed messages are fetched without a selected channel")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestFetchGap(t *testing.T) {
+	svc := newTestService()
+	missed := svc.AddMessage("C1", "", "U2", "missed")
+	svc.AddMessage("C1", "", "U2", "newest")
+	ctx := newTestContext(t, svc)
+
+	// The messages between the first and the newest have been missed
+	ctx.View.Chat.RemoveMessage(missed.ID)
+	for _, msg := range ctx.View.Chat.Messages {
+		if msg.Content == "hello general" {
+			ctx.View.Chat.Gap = msg.ID
+		}
+	}
+
+	actionFetchGap(ctx)
+	runAction(t, ctx)
+
+	if contents := chatContents(ctx); !contents["missed"] {
+		t.Errorf("Chat pane shows %v, expected the missed message", contents)
+	}
+	if ctx.View.Chat.Gap != "" {
+		t.Errorf("gap after %s is shown, expected it to be filled", ctx.View.Chat.Gap)
+	}
+}
+
+func TestLoadChannels(t *testing.T) {
+	svc := newTestService()
+	ctx := newTestContext(t, svc)
+
+	ctx.View.ChannelsCursor = "next"
+	svc.Channels = append(svc.Channels, components.ChannelItem{
+		ID: "C3", Name: "dev", Type: components.ChannelTypeChannel,
+	})
+
+	done := make(chan struct{})
+	go func() {
+		actionLoadChannels(ctx)
+		close(done)
+	}()
+
+	// The page is added once its action runs
+	add := nextAction(t, ctx)
+	if hasChannel(ctx, "C3") {
+		t.Error("dev is shown before the page has been added")
+	}
+	add()
+	if !hasChannel(ctx, "C3") {
+		t.Error("dev isn't shown after the page has been added")
+	}
+	if ctx.View.ChannelsCursor != "" {
+		t.Errorf("cursor is %s after the last page, expected none", ctx.View.ChannelsCursor)
+	}
+
+	runAction(t, ctx)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("loading the channels didn't finish once the pages were added")
+	}
+}
+
+func TestLoadOlderReplies(t *testing.T) {
+	svc := newTestService()
+	parent := svc.AddMessage("C2", "", "U2", "thread")
+	older := svc.AddMessage("C2", parent.ID, "U3", "older reply")
+	latest := svc.AddMessage("C2", parent.ID, "U3", "latest reply")
+	ctx := newTestContext(t, svc)
+
+	ctx.View.Channels.SetSelectedChannel(1)
+	actionChangeChannel(ctx)
+	runAction(t, ctx)
+	runAction(t, ctx)
+
+	// Only the latest page of replies has been loaded
+	thread, ok := ctx.View.Threads.GetThread()
+	if !ok || thread.ID != parent.ID {
+		t.Fatal("the thread isn't shown")
+	}
+	delete(thread.Messages, older.ID)
+	thread.RepliesCursor = latest.ID
+	ctx.View.Threads.SetThread(thread)
+	ctx.View.Chat.Messages[thread.ID] = thread
+
+	actionScrollUpThreads(ctx)
+	runAction(t, ctx)
+
+	thread, _ = ctx.View.Threads.GetThread()
+	if _, ok := thread.Messages[older.ID]; !ok {
+		t.Error("the older reply isn't loaded when scrolled up to the top of the thread")
+	}
+	if thread.RepliesCursor != "" {
+		t.Errorf("thread has cursor %s after all replies are loaded, expected none", thread.RepliesCursor)
+	}
+	if ctx.View.Chat.Messages[thread.ID].RepliesCursor != "" {
+		t.Error("the thread in the Chat pane isn't updated")
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"unsafe"
+
+	"github.com/erroneousboat/termui"
+
+	"github.com/erroneousboat/slack-term/views"
+)
+
+// testTerminalEnv is set when the tests run on the pseudo terminal
+const testTerminalEnv = "SLACK_TERM_TEST_TERMINAL"
+
+// TestMain runs the tests on a pseudo terminal, because the handlers render
+// the view with termui. The tests are run again in a process that has the
+// pseudo terminal as its terminal, the output of the terminal is discarded.
+func TestMain(m *testing.M) {
+	if os.Getenv(testTerminalEnv) != "" {
+		views.TitleOutput = ioutil.Discard
+		if err := termui.Init(); err != nil {
+			fmt.Fprintf(os.Stderr, "unable to initialize the terminal: %v\n", err)
+			os.Exit(1)
+		}
+		code := m.Run()
+		termui.Close()
+		os.Exit(code)
+	}
+
+	master, slave, err := openTerminal(80, 24)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to open a pseudo terminal: %v\n", err)
+		os.Exit(1)
+	}
+	go io.Copy(ioutil.Discard, master)
+
+	cmd := exec.Command(os.Args[0], os.Args[1:]...)
+	cmd.Env = append(os.Environ(), testTerminalEnv+"=1", "TERM=xterm")
+	cmd.Stdin = slave
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true, Setctty: true, Ctty: 0}
+
+	err = cmd.Run()
+	slave.Close()
+	master.Close()
+
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		os.Exit(exitErr.ExitCode())
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to run the tests: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// openTerminal opens a pseudo terminal with the size of width by height,
+// and returns its master and slave
+func openTerminal(width int, height int) (*os.File, *os.File, error) {
+	master, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var n uint32
+	if err := ioctl(master, syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+
+	var unlock int32
+	if err := ioctl(master, syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+
+	slave, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		master.Close()
+		return nil, nil, err
+	}
+
+	size := struct{ rows, cols, x, y uint16 }{uint16(height), uint16(width), 0, 0}
+	if err := ioctl(slave, syscall.TIOCSWINSZ, uintptr(unsafe.Pointer(&size))); err != nil {
+		slave.Close()
+		master.Close()
+		return nil, nil, err
+	}
+
+	return master, slave, nil
+}
+
+func ioctl(f *os.File, request uintptr, arg uintptr) error {
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), request, arg)
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/erroneousboat/slack-term/context"
 	"github.com/erroneousboat/slack-term/handlers"
+	"github.com/erroneousboat/slack-term/views"
 )
 
 const (
@@ -20,7 +21,13 @@ const (
     slack-term - slack client for your terminal
 
 USAGE:
-    slack-term -config [path-to-config]
+    slack-term -config [path-to-config] [command]
+
+COMMANDS:
+   encrypt-config    encrypt the slack token and cookie of the config file
+   tail              print the new messages of a conversation
+   unreads           print the conversations with unread messages and mentions
+   warm-cache        store the users, emoji and conversations in the cache
 
 VERSION:
     %s
@@ -96,6 +103,20 @@ func init() {
 }
 
 func main() {
+	// Run a subcommand instead of the terminal user interface
+	if flag.NArg() > 0 {
+		subcommand, ok := subcommands[flag.Arg(0)]
+		if !ok {
+			flag.Usage()
+			os.Exit(1)
+		}
+
+		if err := subcommand(flag.Args()[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	// Start terminal user interface
 	err := termui.Init()
 	if err != nil {
@@ -103,6 +124,15 @@ func main() {
 	}
 	defer termui.Close()
 
+	// The title of the terminal window is set to the selected channel, and
+	// restored on exit
+	views.SaveTitle()
+	defer views.RestoreTitle()
+
+	// Report escape followed by a key as alt+key, used by the
+	// key mappings with the M- modifier
+	termbox.SetInputMode(termbox.InputAlt)
+
 	// Create custom event stream for termui because
 	// termui's one has data race conditions with its
 	// event handling. We're circumventing it here until
@@ -124,9 +154,7 @@ func main() {
 	}
 
 	// Cleanup persistent cache on exit
-	if ctx.Service.PersistentCache != nil {
-		defer ctx.Service.PersistentCache.Close()
-	}
+	defer ctx.Service.Close()
 
 	// Initialize handlers
 	handlers.Initialize(ctx)
@@ -0,0 +1,71 @@
+package notify
+
+import (
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// linuxNotifier uses notify-send, which sends the notification over D-Bus
+// to the notification daemon. Notifications get an "Open" action, which
+// notify-send prints when it has been clicked.
+type linuxNotifier struct {
+	appName string
+
+	// actions is cleared when notify-send doesn't support actions, which
+	// have been added in libnotify 0.7.10. It's cleared by the goroutines
+	// that wait on notify-send.
+	actions   bool
+	actionsMu sync.Mutex
+}
+
+func newLinuxNotifier(appName string) *linuxNotifier {
+	return &linuxNotifier{
+		appName: appName,
+		actions: true,
+	}
+}
+
+func (n *linuxNotifier) Push(title string, message string, open func()) error {
+	if !n.hasActions() || open == nil {
+		return exec.Command(
+			"notify-send", "--app-name", n.appName, title, message,
+		).Run()
+	}
+
+	// notify-send will wait until the notification has been clicked or
+	// closed, so it isn't waited on
+	cmd := exec.Command(
+		"notify-send",
+		"--app-name", n.appName,
+		"--action", "open=Open",
+		"--wait",
+		title, message,
+	)
+	go func() {
+		// An older notify-send exits with an error because of the unknown
+		// options, the notification is sent again without the action
+		out, err := cmd.Output()
+		if err != nil {
+			n.actionsMu.Lock()
+			n.actions = false
+			n.actionsMu.Unlock()
+
+			n.Push(title, message, nil)
+			return
+		}
+
+		if strings.TrimSpace(string(out)) == "open" {
+			open()
+		}
+	}()
+
+	return nil
+}
+
+func (n *linuxNotifier) hasActions() bool {
+	n.actionsMu.Lock()
+	defer n.actionsMu.Unlock()
+
+	return n.actions
+}
@@ -0,0 +1,48 @@
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// macNotifier uses terminal-notifier when it is installed, and otherwise
+// falls back to osascript, which is available since OS X Mavericks
+type macNotifier struct {
+	appName          string
+	terminalNotifier string
+}
+
+func newMacNotifier(appName string) *macNotifier {
+	path, _ := exec.LookPath("terminal-notifier")
+	return &macNotifier{
+		appName:          appName,
+		terminalNotifier: path,
+	}
+}
+
+func (n *macNotifier) Push(title string, message string, open func()) error {
+	if n.terminalNotifier != "" {
+		return exec.Command(
+			n.terminalNotifier,
+			"-title", n.appName,
+			"-subtitle", title,
+			"-message", message,
+		).Run()
+	}
+
+	script := fmt.Sprintf(
+		"display notification %s with title %s subtitle %s",
+		appleScriptString(message),
+		appleScriptString(n.appName),
+		appleScriptString(title),
+	)
+	return exec.Command("osascript", "-e", script).Run()
+}
+
+// appleScriptString will quote s as an AppleScript string literal
+func appleScriptString(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	return fmt.Sprintf(`"%s"`, s)
+}
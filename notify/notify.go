@@ -0,0 +1,40 @@
+package notify
+
+import (
+	"errors"
+	"runtime"
+
+	"github.com/0xAX/notificator"
+)
+
+// Notifier shows desktop notifications. When the notifier supports it,
+// open is called when the notification has been clicked, on a goroutine of
+// the notifier.
+type Notifier interface {
+	Push(title string, message string, open func()) error
+}
+
+// New creates the Notifier for the OS slack-term is running on
+func New(appName string) (Notifier, error) {
+	switch runtime.GOOS {
+	case "darwin":
+		return newMacNotifier(appName), nil
+	case "linux":
+		return newLinuxNotifier(appName), nil
+	case "windows":
+		return &defaultNotifier{
+			notificator: notificator.New(notificator.Options{AppName: appName}),
+		}, nil
+	default:
+		return nil, errors.New("desktop notifications are not supported for your OS")
+	}
+}
+
+// defaultNotifier uses notificator, which runs growlnotify on windows
+type defaultNotifier struct {
+	notificator *notificator.Notificator
+}
+
+func (n *defaultNotifier) Push(title string, message string, open func()) error {
+	return n.notificator.Push(title, message, "", notificator.UR_NORMAL)
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// callAPI will call a method of the slack web api that isn't (fully)
+// supported by the slack client library, the response is decoded into
+// intf. Responses should embed slack.SlackResponse in order to check
+// whether the call succeeded.
+//
+// https://api.slack.com/web
+func (s *SlackService) callAPI(ctx context.Context, method string, values url.Values, intf interface{}) error {
+	// Rate limit
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	values.Set("token", s.accessToken())
+
+	return s.postAPI(ctx, method, values, intf)
+}
+
+// postAPI will post values to a method of the slack web api, and decode the
+// response into intf
+func (s *SlackService) postAPI(ctx context.Context, method string, values url.Values, intf interface{}) error {
+	req, err := http.NewRequest(
+		"POST", s.apiURL+method, strings.NewReader(values.Encode()),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack api %s: %s", method, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(intf)
+}
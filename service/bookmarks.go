@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/slack-go/slack"
+
+	"github.com/erroneousboat/slack-term/components"
+)
+
+type bookmark struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Link  string `json:"link"`
+	Emoji string `json:"emoji"`
+	Type  string `json:"type"`
+}
+
+type bookmarksResponse struct {
+	slack.SlackResponse
+	Bookmarks []bookmark `json:"bookmarks"`
+}
+
+// GetBookmarks will get the links that are bookmarked in the channel with
+// channelID, in the order they're shown in slack
+func (s *SlackService) GetBookmarks(ctx context.Context, channelID string) ([]components.BookmarkItem, error) {
+	// https://api.slack.com/methods/bookmarks.list
+	values := url.Values{
+		"channel_id": {channelID},
+	}
+
+	var response bookmarksResponse
+	if err := s.callAPI(ctx, "bookmarks.list", values, &response); err != nil {
+		return nil, err
+	}
+	if err := response.Err(); err != nil {
+		return nil, err
+	}
+
+	items := make([]components.BookmarkItem, 0, len(response.Bookmarks))
+	for _, bm := range response.Bookmarks {
+		// Folders and other bookmarks without a link can't be opened
+		if bm.Link == "" {
+			continue
+		}
+
+		items = append(items, components.BookmarkItem{
+			ID:    bm.ID,
+			Title: bm.Title,
+			Link:  bm.Link,
+			Emoji: parseEmoji(bm.Emoji),
+		})
+	}
+
+	return items, nil
+}
@@ -2,43 +2,140 @@ package service
 
 import (
 	"database/sql"
+	"errors"
 	"os"
 	fp "path/filepath"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/OpenPeeDeeP/xdg"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// schemas contains the tables that are created in the persistent cache
+var schemas = []string{
+	`CREATE TABLE IF NOT EXISTS users (
+		user_id TEXT PRIMARY KEY,
+		username TEXT NOT NULL,
+		updated_at INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS marks (
+		team_id TEXT NOT NULL,
+		mark TEXT NOT NULL,
+		channel_id TEXT NOT NULL,
+		PRIMARY KEY (team_id, mark)
+	)`,
+	`CREATE TABLE IF NOT EXISTS history (
+		channel_id TEXT PRIMARY KEY,
+		messages TEXT NOT NULL,
+		updated_at INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS members (
+		channel_id TEXT PRIMARY KEY,
+		user_ids TEXT NOT NULL,
+		updated_at INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS channel_order (
+		team_id TEXT NOT NULL,
+		channel_id TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		PRIMARY KEY (team_id, channel_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS mutes (
+		team_id TEXT NOT NULL,
+		channel_id TEXT NOT NULL,
+		PRIMARY KEY (team_id, channel_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS tokens (
+		client_id TEXT PRIMARY KEY,
+		access_token TEXT NOT NULL,
+		refresh_token TEXT NOT NULL,
+		expires_at INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS emoji (
+		team_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		value TEXT NOT NULL,
+		PRIMARY KEY (team_id, name)
+	)`,
+	`CREATE TABLE IF NOT EXISTS follow_ups (
+		team_id TEXT NOT NULL,
+		channel_id TEXT NOT NULL,
+		message_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		content TEXT NOT NULL,
+		due INTEGER NOT NULL,
+		reminded INTEGER NOT NULL,
+		PRIMARY KEY (team_id, channel_id, message_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS emoji_usage (
+		team_id TEXT NOT NULL,
+		name TEXT NOT NULL,
+		count INTEGER NOT NULL,
+		PRIMARY KEY (team_id, name)
+	)`,
+}
+
+// migrations alter the tables of an existing persistent cache. A migration
+// will fail when it has already been applied, that's why errors are ignored.
+var migrations = []string{
+	`ALTER TABLE users ADD COLUMN real_name TEXT NOT NULL DEFAULT ''`,
+	`ALTER TABLE users ADD COLUMN deleted INTEGER NOT NULL DEFAULT 0`,
+}
+
+// errCacheClosed is returned when the persistent cache is written to after
+// it has been closed
+var errCacheClosed = errors.New("the persistent cache is closed")
+
 type UserCache struct {
 	db *sql.DB
+
+	// mu is held for reading by every write, Close holds it for writing so
+	// that the writes that are still running are finished first
+	mu     sync.RWMutex
+	closed bool
 }
 
 func NewUserCache() (*UserCache, error) {
+	// The cache holds the messages and the tokens of the user, so it's only
+	// accessible by the user. The permissions of an existing directory and
+	// database aren't changed by MkdirAll and OpenFile.
 	cacheDir := fp.Join(xdg.CacheHome(), "slack-term",)
-	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+	if err := os.MkdirAll(cacheDir, 0700); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(cacheDir, 0700); err != nil {
 		return nil, err
 	}
 
 	dbPath := fp.Join(cacheDir, "users.db")
-	db, err := sql.Open("sqlite3", dbPath)
+	file, err := os.OpenFile(dbPath, os.O_RDWR|os.O_CREATE, 0600)
 	if err != nil {
 		return nil, err
 	}
+	file.Close()
+	if err := os.Chmod(dbPath, 0600); err != nil {
+		return nil, err
+	}
 
-	// Create table if not exists
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS users (
-			user_id TEXT PRIMARY KEY,
-			username TEXT NOT NULL,
-			updated_at INTEGER NOT NULL
-		)
-	`)
+	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
-		db.Close()
 		return nil, err
 	}
 
+	// Create tables if not exists
+	for _, schema := range schemas {
+		if _, err = db.Exec(schema); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	for _, migration := range migrations {
+		db.Exec(migration)
+	}
+
 	return &UserCache{db: db}, nil
 }
 
@@ -63,17 +160,499 @@ func (c *UserCache) Get(userID string) (string, bool) {
 	return username, true
 }
 
-func (c *UserCache) Set(userID, username string) error {
-	_, err := c.db.Exec(
-		"INSERT OR REPLACE INTO users (user_id, username, updated_at) VALUES (?, ?, ?)",
-		userID, username, time.Now().Unix(),
+// GetRealName returns the real name of a user, it follows the same
+// expiration as Get
+func (c *UserCache) GetRealName(userID string) (string, bool) {
+	var realName string
+	var updatedAt int64
+
+	err := c.db.QueryRow(
+		"SELECT real_name, updated_at FROM users WHERE user_id = ?",
+		userID,
+	).Scan(&realName, &updatedAt)
+
+	if err != nil {
+		return "", false
+	}
+
+	// Cache expires after 7 days
+	if time.Now().Unix()-updatedAt > 7*24*60*60 {
+		return "", false
+	}
+
+	return realName, true
+}
+
+// IsDeleted returns whether a user has been deactivated, it follows the
+// same expiration as Get
+func (c *UserCache) IsDeleted(userID string) (bool, bool) {
+	var deleted bool
+	var updatedAt int64
+
+	err := c.db.QueryRow(
+		"SELECT deleted, updated_at FROM users WHERE user_id = ?",
+		userID,
+	).Scan(&deleted, &updatedAt)
+
+	if err != nil {
+		return false, false
+	}
+
+	// Cache expires after 7 days
+	if time.Now().Unix()-updatedAt > 7*24*60*60 {
+		return false, false
+	}
+
+	return deleted, true
+}
+
+func (c *UserCache) Set(userID, username, realName string, deleted bool) error {
+	return c.exec(
+		"INSERT OR REPLACE INTO users (user_id, username, real_name, deleted, updated_at) VALUES (?, ?, ?, ?, ?)",
+		userID, username, realName, deleted, time.Now().Unix(),
+	)
+}
+
+// CachedUser is a user as it's stored in the persistent cache
+type CachedUser struct {
+	ID       string
+	Username string
+	RealName string
+	Deleted  bool
+}
+
+// GetUsers returns the users that haven't been deleted, it follows the same
+// expiration as Get
+func (c *UserCache) GetUsers() ([]CachedUser, error) {
+	rows, err := c.db.Query(
+		"SELECT user_id, username, real_name FROM users WHERE deleted = 0 AND updated_at > ?",
+		time.Now().Unix()-7*24*60*60,
 	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []CachedUser
+	for rows.Next() {
+		var user CachedUser
+		if err := rows.Scan(&user.ID, &user.Username, &user.RealName); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// SetUsers will persist users at once, which is a lot faster than setting
+// them one by one
+func (c *UserCache) SetUsers(users []CachedUser) error {
+	now := time.Now().Unix()
+	return c.transaction(func(tx *sql.Tx) error {
+		for _, user := range users {
+			_, err := tx.Exec(
+				"INSERT OR REPLACE INTO users (user_id, username, real_name, deleted, updated_at) VALUES (?, ?, ?, ?, ?)",
+				user.ID, user.Username, user.RealName, user.Deleted, now,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetMarks returns all the channel marks that have been set for a team,
+// keyed by the mark
+func (c *UserCache) GetMarks(teamID string) (map[string]string, error) {
+	rows, err := c.db.Query(
+		"SELECT mark, channel_id FROM marks WHERE team_id = ?",
+		teamID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	marks := make(map[string]string)
+	for rows.Next() {
+		var mark, channelID string
+		if err := rows.Scan(&mark, &channelID); err != nil {
+			return nil, err
+		}
+		marks[mark] = channelID
+	}
+
+	return marks, rows.Err()
+}
+
+// SetMark will persist the channel a mark points to
+func (c *UserCache) SetMark(teamID, mark, channelID string) error {
+	return c.exec(
+		"INSERT OR REPLACE INTO marks (team_id, mark, channel_id) VALUES (?, ?, ?)",
+		teamID, mark, channelID,
+	)
+}
+
+// CachedFollowUp is a message that has been flagged for follow-up, Due is
+// the unix time of the reminder or 0 without one
+type CachedFollowUp struct {
+	ChannelID string
+	MessageID string
+	Name      string
+	Content   string
+	Due       int64
+	Reminded  bool
+}
+
+// GetFollowUps returns the messages that have been flagged for follow-up in
+// a team
+func (c *UserCache) GetFollowUps(teamID string) ([]CachedFollowUp, error) {
+	rows, err := c.db.Query(
+		"SELECT channel_id, message_id, name, content, due, reminded FROM follow_ups WHERE team_id = ?",
+		teamID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var followUps []CachedFollowUp
+	for rows.Next() {
+		var f CachedFollowUp
+		if err := rows.Scan(&f.ChannelID, &f.MessageID, &f.Name, &f.Content, &f.Due, &f.Reminded); err != nil {
+			return nil, err
+		}
+		followUps = append(followUps, f)
+	}
+
+	return followUps, rows.Err()
+}
+
+// SetFollowUp will persist a message that has been flagged for follow-up
+func (c *UserCache) SetFollowUp(teamID string, f CachedFollowUp) error {
+	return c.exec(
+		"INSERT OR REPLACE INTO follow_ups (team_id, channel_id, message_id, name, content, due, reminded) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		teamID, f.ChannelID, f.MessageID, f.Name, f.Content, f.Due, f.Reminded,
+	)
+}
+
+// DeleteFollowUp will remove the follow-up flag of a message
+func (c *UserCache) DeleteFollowUp(teamID, channelID, messageID string) error {
+	return c.exec(
+		"DELETE FROM follow_ups WHERE team_id = ? AND channel_id = ? AND message_id = ?",
+		teamID, channelID, messageID,
+	)
+}
+
+// GetChannelOrder returns the custom positions of the channels of a team,
+// keyed by the channel id
+func (c *UserCache) GetChannelOrder(teamID string) (map[string]int, error) {
+	rows, err := c.db.Query(
+		"SELECT channel_id, position FROM channel_order WHERE team_id = ?",
+		teamID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	order := make(map[string]int)
+	for rows.Next() {
+		var channelID string
+		var position int
+		if err := rows.Scan(&channelID, &position); err != nil {
+			return nil, err
+		}
+		order[channelID] = position
+	}
+
+	return order, rows.Err()
+}
+
+// SetChannelOrder will persist the custom positions of channels
+func (c *UserCache) SetChannelOrder(teamID string, order map[string]int) error {
+	return c.transaction(func(tx *sql.Tx) error {
+		for channelID, position := range order {
+			_, err := tx.Exec(
+				"INSERT OR REPLACE INTO channel_order (team_id, channel_id, position) VALUES (?, ?, ?)",
+				teamID, channelID, position,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetMutes returns the ids of the channels of a team that have been muted
+func (c *UserCache) GetMutes(teamID string) (map[string]bool, error) {
+	rows, err := c.db.Query(
+		"SELECT channel_id FROM mutes WHERE team_id = ?",
+		teamID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mutes := make(map[string]bool)
+	for rows.Next() {
+		var channelID string
+		if err := rows.Scan(&channelID); err != nil {
+			return nil, err
+		}
+		mutes[channelID] = true
+	}
+
+	return mutes, rows.Err()
+}
+
+// SetMute will persist whether a channel is muted
+func (c *UserCache) SetMute(teamID, channelID string, muted bool) error {
+	if !muted {
+		return c.exec(
+			"DELETE FROM mutes WHERE team_id = ? AND channel_id = ?",
+			teamID, channelID,
+		)
+	}
+
+	return c.exec(
+		"INSERT OR REPLACE INTO mutes (team_id, channel_id) VALUES (?, ?)",
+		teamID, channelID,
+	)
+}
+
+// GetEmoji returns the custom emoji of a team, keyed by their name. The
+// value is the url of the image, or "alias:" followed by the name of
+// another emoji.
+func (c *UserCache) GetEmoji(teamID string) (map[string]string, error) {
+	rows, err := c.db.Query(
+		"SELECT name, value FROM emoji WHERE team_id = ?",
+		teamID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	emoji := make(map[string]string)
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			return nil, err
+		}
+		emoji[name] = value
+	}
+
+	return emoji, rows.Err()
+}
+
+// SetEmoji will persist the custom emoji of a team, replacing the ones that
+// were stored before
+func (c *UserCache) SetEmoji(teamID string, emoji map[string]string) error {
+	return c.transaction(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM emoji WHERE team_id = ?", teamID); err != nil {
+			return err
+		}
+
+		for name, value := range emoji {
+			_, err := tx.Exec(
+				"INSERT INTO emoji (team_id, name, value) VALUES (?, ?, ?)",
+				teamID, name, value,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetEmojiUsage returns how often the emoji of a team have been used by the
+// user, keyed by their name
+func (c *UserCache) GetEmojiUsage(teamID string) (map[string]int, error) {
+	rows, err := c.db.Query(
+		"SELECT name, count FROM emoji_usage WHERE team_id = ?",
+		teamID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := make(map[string]int)
+	for rows.Next() {
+		var name string
+		var count int
+		if err := rows.Scan(&name, &count); err != nil {
+			return nil, err
+		}
+		usage[name] = count
+	}
+
+	return usage, rows.Err()
+}
+
+// AddEmojiUsage will count the use of emoji by the user
+func (c *UserCache) AddEmojiUsage(teamID string, names []string) error {
+	return c.transaction(func(tx *sql.Tx) error {
+		for _, name := range names {
+			_, err := tx.Exec(
+				"INSERT OR IGNORE INTO emoji_usage (team_id, name, count) VALUES (?, ?, 0)",
+				teamID, name,
+			)
+			if err != nil {
+				return err
+			}
+
+			_, err = tx.Exec(
+				"UPDATE emoji_usage SET count = count + 1 WHERE team_id = ? AND name = ?",
+				teamID, name,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetHistory returns the encoded message history of a channel, together with
+// the time it was stored
+func (c *UserCache) GetHistory(channelID string) ([]byte, time.Time, bool) {
+	var messages []byte
+	var updatedAt int64
+
+	err := c.db.QueryRow(
+		"SELECT messages, updated_at FROM history WHERE channel_id = ?",
+		channelID,
+	).Scan(&messages, &updatedAt)
+
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+
+	return messages, time.Unix(updatedAt, 0), true
+}
+
+// SetHistory will persist the encoded message history of a channel
+func (c *UserCache) SetHistory(channelID string, messages []byte) error {
+	return c.exec(
+		"INSERT OR REPLACE INTO history (channel_id, messages, updated_at) VALUES (?, ?, ?)",
+		channelID, messages, time.Now().Unix(),
+	)
+}
+
+// GetMembers returns the ids of the members of a channel, together with the
+// time they were stored
+func (c *UserCache) GetMembers(channelID string) ([]string, time.Time, bool) {
+	var userIDs string
+	var updatedAt int64
+
+	err := c.db.QueryRow(
+		"SELECT user_ids, updated_at FROM members WHERE channel_id = ?",
+		channelID,
+	).Scan(&userIDs, &updatedAt)
+
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+
+	if userIDs == "" {
+		return []string{}, time.Unix(updatedAt, 0), true
+	}
+
+	return strings.Split(userIDs, ","), time.Unix(updatedAt, 0), true
+}
+
+// SetMembers will persist the ids of the members of a channel
+func (c *UserCache) SetMembers(channelID string, userIDs []string) error {
+	return c.exec(
+		"INSERT OR REPLACE INTO members (channel_id, user_ids, updated_at) VALUES (?, ?, ?)",
+		channelID, strings.Join(userIDs, ","), time.Now().Unix(),
+	)
+}
+
+// GetTokens returns the rotated access and refresh token of an app, together
+// with the time the access token expires
+func (c *UserCache) GetTokens(clientID string) (string, string, time.Time, bool) {
+	var accessToken, refreshToken string
+	var expiresAt int64
+
+	err := c.db.QueryRow(
+		"SELECT access_token, refresh_token, expires_at FROM tokens WHERE client_id = ?",
+		clientID,
+	).Scan(&accessToken, &refreshToken, &expiresAt)
+
+	if err != nil {
+		return "", "", time.Time{}, false
+	}
+
+	return accessToken, refreshToken, time.Unix(expiresAt, 0), true
+}
+
+// SetTokens will persist the rotated access and refresh token of an app
+func (c *UserCache) SetTokens(clientID, accessToken, refreshToken string, expiresAt time.Time) error {
+	return c.exec(
+		"INSERT OR REPLACE INTO tokens (client_id, access_token, refresh_token, expires_at) VALUES (?, ?, ?, ?)",
+		clientID, accessToken, refreshToken, expiresAt.Unix(),
+	)
+}
+
+// DeleteTokens will remove the persisted tokens of an app
+func (c *UserCache) DeleteTokens(clientID string) error {
+	return c.exec("DELETE FROM tokens WHERE client_id = ?", clientID)
+}
+
+// exec will run a statement that writes to the persistent cache
+func (c *UserCache) exec(query string, args ...interface{}) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return errCacheClosed
+	}
+
+	_, err := c.db.Exec(query, args...)
 	return err
 }
 
+// transaction will run the writes of fn in a single transaction, which is
+// rolled back when fn returns an error
+func (c *UserCache) transaction(fn func(tx *sql.Tx) error) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.closed {
+		return errCacheClosed
+	}
+
+	tx, err := c.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Close will wait for the writes that are running to finish, and close the
+// database. Writes after it has been closed are discarded.
 func (c *UserCache) Close() error {
-	if c.db != nil {
-		return c.db.Close()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed || c.db == nil {
+		return nil
 	}
-	return nil
+	c.closed = true
+
+	return c.db.Close()
 }
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/slack-go/slack"
+)
+
+// GetCanvasLink will get the link of the canvas of a channel, it's empty
+// when the channel doesn't have a canvas. The canvas is a file, of which
+// the id is in the properties of the channel.
+//
+// https://api.slack.com/methods/conversations.info
+// https://api.slack.com/methods/files.info
+func (s *SlackService) GetCanvasLink(ctx context.Context, channelID string) (string, error) {
+	var info struct {
+		slack.SlackResponse
+		Channel struct {
+			Properties struct {
+				Canvas struct {
+					FileID  string `json:"file_id"`
+					IsEmpty bool   `json:"is_empty"`
+				} `json:"canvas"`
+			} `json:"properties"`
+		} `json:"channel"`
+	}
+
+	err := s.callAPI(
+		ctx, "conversations.info", url.Values{"channel": {channelID}}, &info,
+	)
+	if err != nil {
+		return "", err
+	}
+	if err := info.Err(); err != nil {
+		return "", err
+	}
+
+	canvas := info.Channel.Properties.Canvas
+	if canvas.FileID == "" || canvas.IsEmpty {
+		return "", nil
+	}
+
+	// Rate limit
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return "", err
+		}
+	}
+
+	file, _, _, err := s.Client().GetFileInfoContext(ctx, canvas.FileID, 0, 0)
+	if err != nil {
+		return "", err
+	}
+
+	return file.Permalink, nil
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/slack-go/slack"
+)
+
+// UnknownCommandError is returned when a slash command isn't installed in
+// the workspace, e.g. because of a typo
+type UnknownCommandError struct {
+	Command string
+}
+
+func (e *UnknownCommandError) Error() string {
+	return fmt.Sprintf("unknown command: %s", e.Command)
+}
+
+// getCommands returns the names of the slash commands of the workspace,
+// both the built-in commands and the ones of installed apps. They're
+// fetched once and cached for the rest of the session, when they can't be
+// fetched nil is returned and they're fetched again the next time.
+//
+// commands.list isn't documented, it's the endpoint the web client uses.
+func (s *SlackService) getCommands(ctx context.Context) (map[string]bool, error) {
+	s.commandsMu.Lock()
+	defer s.commandsMu.Unlock()
+
+	if s.commands != nil {
+		return s.commands, nil
+	}
+
+	var list struct {
+		slack.SlackResponse
+		Commands []struct {
+			Name string `json:"name"`
+		} `json:"commands"`
+	}
+
+	if err := s.callAPI(ctx, "commands.list", url.Values{}, &list); err != nil {
+		return nil, err
+	}
+	if err := list.Err(); err != nil {
+		return nil, err
+	}
+
+	s.commands = make(map[string]bool, len(list.Commands))
+	for _, cmd := range list.Commands {
+		s.commands[cmd.Name] = true
+	}
+
+	return s.commands, nil
+}
+
+// validateCommand returns an UnknownCommandError when the slash command
+// isn't one of the commands of the workspace. When the commands can't be
+// fetched the command is assumed to be valid.
+func (s *SlackService) validateCommand(ctx context.Context, cmd string) error {
+	commands, err := s.getCommands(ctx)
+	if err != nil || len(commands) == 0 {
+		return nil
+	}
+
+	if !commands[cmd] {
+		return &UnknownCommandError{Command: cmd}
+	}
+
+	return nil
+}
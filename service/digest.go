@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/erroneousboat/slack-term/components"
+)
+
+// digestChannels is the number of most active conversations in a digest
+const digestChannels = 5
+
+// Digest is a summary of the messages of a day
+type Digest struct {
+	Day time.Time
+
+	// Conversations is the number of conversations of which the history
+	// is stored in the persistent cache, the summary is limited to them
+	Conversations int
+
+	// Messages is the number of messages of the day, Sent the number of
+	// them that have been sent by the user
+	Messages int
+	Sent     int
+
+	// Active are the conversations with the most messages, the most
+	// active first
+	Active []DigestConversation
+
+	// Mentions are the messages that mention the user, oldest first
+	Mentions []components.MentionItem
+}
+
+// DigestConversation is the number of messages of a conversation in a
+// Digest
+type DigestConversation struct {
+	ID       string
+	Name     string
+	Messages int
+}
+
+// GetDigest will summarize the messages of the day of day, from the history
+// of the conversations that is stored in the persistent cache. No requests
+// are made.
+func (s *SlackService) GetDigest(ctx context.Context, day time.Time) (Digest, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+
+	digest := Digest{Day: start}
+	if s.PersistentCache == nil {
+		return digest, errors.New("the digest needs the persistent cache")
+	}
+
+	mention := fmt.Sprintf("<@%s>", s.CurrentUserID)
+	for _, chn := range s.Conversations {
+		data, _, ok := s.PersistentCache.GetHistory(chn.ID)
+		if !ok {
+			continue
+		}
+
+		var history []historyMessage
+		if err := json.Unmarshal(data, &history); err != nil {
+			continue
+		}
+		digest.Conversations++
+
+		conversation := DigestConversation{
+			ID:   chn.ID,
+			Name: s.getConversationName(ctx, chn),
+		}
+		for _, message := range history {
+			ts, err := strconv.ParseFloat(message.Timestamp, 64)
+			if err != nil {
+				continue
+			}
+
+			t := time.Unix(int64(ts), 0)
+			if t.Before(start) || !t.Before(end) {
+				continue
+			}
+
+			conversation.Messages++
+			if message.User == s.CurrentUserID {
+				digest.Sent++
+			} else if strings.Contains(message.Text, mention) {
+				name, _ := s.GetUserName(ctx, message.User)
+				digest.Mentions = append(digest.Mentions, components.MentionItem{
+					ChannelID:   chn.ID,
+					ChannelName: conversation.Name,
+					MessageID:   message.Timestamp,
+					Name:        name,
+					Content:     parseMessage(ctx, s, chn.ID, message.Text),
+					Time:        t,
+				})
+			}
+		}
+
+		if conversation.Messages > 0 {
+			digest.Messages += conversation.Messages
+			digest.Active = append(digest.Active, conversation)
+		}
+	}
+
+	sort.SliceStable(digest.Active, func(i, j int) bool {
+		return digest.Active[i].Messages > digest.Active[j].Messages
+	})
+	if len(digest.Active) > digestChannels {
+		digest.Active = digest.Active[:digestChannels]
+	}
+
+	sort.SliceStable(digest.Mentions, func(i, j int) bool {
+		return digest.Mentions[i].MessageID < digest.Mentions[j].MessageID
+	})
+
+	return digest, nil
+}
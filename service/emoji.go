@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/erroneousboat/slack-term/config"
+)
+
+// frequentEmojiCount is the number of emoji that are used most often, that
+// are listed first in the emoji picker and completion
+const frequentEmojiCount = 9
+
+// shortcodeRegex matches the emoji shortcodes in a message, e.g. ":smile:"
+var shortcodeRegex = regexp.MustCompile(`:([a-z0-9_+'-]+):`)
+
+// GetFrequentEmoji returns the names of the emoji that have been used most
+// often by the user in messages and reactions, the most used first
+func (s *SlackService) GetFrequentEmoji() []string {
+	s.emojiUsageMu.Lock()
+	defer s.emojiUsageMu.Unlock()
+
+	names := make([]string, 0, len(s.emojiUsage))
+	for name := range s.emojiUsage {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		a, b := s.emojiUsage[names[i]], s.emojiUsage[names[j]]
+		if a != b {
+			return a > b
+		}
+		return names[i] < names[j]
+	})
+
+	if len(names) > frequentEmojiCount {
+		names = names[:frequentEmojiCount]
+	}
+
+	return names
+}
+
+// useEmoji will count the use of emoji by the user, and persist it when
+// the persistent cache is available
+func (s *SlackService) useEmoji(names []string) {
+	if len(names) == 0 {
+		return
+	}
+
+	s.emojiUsageMu.Lock()
+	if s.emojiUsage == nil {
+		s.emojiUsage = make(map[string]int)
+	}
+	for _, name := range names {
+		s.emojiUsage[name]++
+	}
+	s.emojiUsageMu.Unlock()
+
+	if s.PersistentCache != nil {
+		s.PersistentCache.AddEmojiUsage(s.CurrentTeamID, names)
+	}
+}
+
+// getMessageEmoji returns the names of the emoji in a message that is sent,
+// only the shortcodes of known emoji are returned
+func (s *SlackService) getMessageEmoji(message string) []string {
+	var names []string
+	for _, match := range shortcodeRegex.FindAllStringSubmatch(message, -1) {
+		name := match[1]
+
+		_, ok := config.EmojiCodemap[fmt.Sprintf(":%s:", name)]
+		if !ok {
+			i := sort.SearchStrings(s.CustomEmoji, name)
+			ok = i < len(s.CustomEmoji) && s.CustomEmoji[i] == name
+		}
+
+		if ok {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,709 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/erroneousboat/slack-term/components"
+)
+
+// FakeService is a ChatService that keeps the conversations, messages and
+// replies in memory. Messages that are sent are received again as events,
+// like they would be from slack.
+type FakeService struct {
+	CurrentUserID string
+	TeamName      string
+	Channels      []components.ChannelItem
+	Public        []components.ChannelItem
+	Presence      map[string]string
+	Unread        map[string]int
+	Members       map[string][]string
+	Posting       map[string]PostingPolicy
+	UserGroups    []UserGroup
+	Users         []User
+	CustomEmoji   []string
+	FrequentEmoji []string
+	Timezones     map[string]*time.Location
+
+	// Messages are kept per channel id from oldest to newest, and the
+	// Replies per thread id
+	Messages map[string][]components.Message
+	Replies  map[string][]components.Message
+
+	// Scheduled are the messages that have been scheduled to be sent
+	// later
+	Scheduled []ScheduledMessage
+
+	// Files are kept per channel id from newest to oldest, with their
+	// content
+	Files    map[string][]components.FileItem
+	Contents map[string]string
+
+	// Bookmarks and the links of canvases are kept per channel id
+	Bookmarks map[string][]components.BookmarkItem
+	Canvases  map[string]string
+
+	Marks     map[string]string
+	Mutes     map[string]bool
+	Stars     map[string]bool
+	Mentions  []components.MentionItem
+	FollowUps []components.FollowUpItem
+	Events    chan slack.RTMEvent
+
+	mu        sync.Mutex
+	timestamp int64
+}
+
+// NewFakeService creates a FakeService with the conversations in channels
+func NewFakeService(userID string, channels []components.ChannelItem) *FakeService {
+	return &FakeService{
+		CurrentUserID: userID,
+		Channels:      channels,
+		Presence:      make(map[string]string),
+		Timezones:     make(map[string]*time.Location),
+		Unread:        make(map[string]int),
+		Members:       make(map[string][]string),
+		Posting:       make(map[string]PostingPolicy),
+		Messages:      make(map[string][]components.Message),
+		Replies:       make(map[string][]components.Message),
+		Files:         make(map[string][]components.FileItem),
+		Contents:      make(map[string]string),
+		Bookmarks:     make(map[string][]components.BookmarkItem),
+		Canvases:      make(map[string]string),
+		Marks:         make(map[string]string),
+		Mutes:         make(map[string]bool),
+		Stars:         make(map[string]bool),
+		Events:        make(chan slack.RTMEvent, 20),
+		timestamp:     time.Now().Unix(),
+	}
+}
+
+// AddMessage will add a message to a channel, and returns the message with
+// its timestamp set as id. When threadID is set it is added as a reply to
+// that thread.
+func (f *FakeService) AddMessage(channelID string, threadID string, name string, content string) components.Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.timestamp++
+	msg := components.Message{
+		ID:         fmt.Sprintf("%d.000000", f.timestamp),
+		Time:       time.Unix(f.timestamp, 0),
+		Name:       name,
+		UserID:     name,
+		Content:    content,
+		FormatTime: "15:04",
+	}
+
+	if threadID == "" {
+		f.Messages[channelID] = append(f.Messages[channelID], msg)
+		return msg
+	}
+
+	f.Replies[threadID] = append(f.Replies[threadID], msg)
+	for i, parent := range f.Messages[channelID] {
+		if parent.ID == threadID {
+			f.Messages[channelID][i].Thread = f.GetThreadPrefix(threadID)
+			f.Messages[channelID][i].ReplyCount++
+		}
+	}
+
+	return msg
+}
+
+func (f *FakeService) GetChannels(ctx context.Context) ([]components.ChannelItem, error) {
+	return f.Channels, nil
+}
+
+func (f *FakeService) GetChannelsPage(ctx context.Context, cursor string) ([]components.ChannelItem, string, error) {
+	return f.Channels, "", nil
+}
+
+func (f *FakeService) GetConversationsForUser(ctx context.Context) ([]components.ChannelItem, error) {
+	return f.Channels, nil
+}
+
+func (f *FakeService) GetConversations() []slack.Channel {
+	var conversations []slack.Channel
+	for _, chn := range f.Channels {
+		var conversation slack.Channel
+		conversation.ID = chn.ID
+		conversation.User = chn.UserID
+		conversation.IsIM = chn.Type == components.ChannelTypeIM
+		conversations = append(conversations, conversation)
+	}
+	return conversations
+}
+
+func (f *FakeService) GetPublicChannels(ctx context.Context, cursor string) ([]components.ChannelItem, string, error) {
+	return f.Public, "", nil
+}
+
+func (f *FakeService) JoinChannel(ctx context.Context, channelID string) (components.ChannelItem, error) {
+	for i, chn := range f.Public {
+		if chn.ID == channelID {
+			f.Public = append(f.Public[:i], f.Public[i+1:]...)
+			f.Channels = append(f.Channels, chn)
+			return chn, nil
+		}
+	}
+	return components.ChannelItem{}, errors.New("channel_not_found")
+}
+
+func (f *FakeService) CreateChannel(ctx context.Context, name string) (components.ChannelItem, error) {
+	for _, chn := range append(f.Channels, f.Public...) {
+		if chn.Name == name {
+			return components.ChannelItem{}, errors.New("name_taken")
+		}
+	}
+
+	chn := components.ChannelItem{
+		ID:   "C" + strings.ToUpper(name),
+		Name: name,
+		Type: components.ChannelTypeChannel,
+	}
+	f.Channels = append(f.Channels, chn)
+	return chn, nil
+}
+
+func (f *FakeService) LeaveChannel(ctx context.Context, channelID string) error {
+	for i, chn := range f.Channels {
+		if chn.ID == channelID {
+			f.Channels = append(f.Channels[:i], f.Channels[i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("channel_not_found")
+}
+
+func (f *FakeService) ArchiveChannel(ctx context.Context, channelID string) error {
+	return f.LeaveChannel(ctx, channelID)
+}
+
+func (f *FakeService) OpenDirectMessage(ctx context.Context, userID string) (components.ChannelItem, error) {
+	for _, chn := range f.Channels {
+		if chn.Type == components.ChannelTypeIM && chn.UserID == userID {
+			return chn, nil
+		}
+	}
+
+	for _, user := range f.Users {
+		if user.ID == userID {
+			chn := components.ChannelItem{
+				ID:       "D" + userID,
+				Name:     user.Name,
+				RealName: user.RealName,
+				UserID:   userID,
+				Type:     components.ChannelTypeIM,
+				Presence: "away",
+			}
+			f.Channels = append(f.Channels, chn)
+			return chn, nil
+		}
+	}
+
+	return components.ChannelItem{}, errors.New("user_not_found")
+}
+
+func (f *FakeService) GetChannelTeams(ctx context.Context, channelID string) ([]string, []string, error) {
+	return []string{}, []string{}, nil
+}
+
+func (f *FakeService) GetChannelMembers(ctx context.Context, channelID string) ([]string, error) {
+	return f.Members[channelID], nil
+}
+
+func (f *FakeService) GetMembersPage(ctx context.Context, channelID string, cursor string) ([]components.MemberItem, string, error) {
+	var members []components.MemberItem
+	for _, userID := range f.Members[channelID] {
+		member := components.MemberItem{
+			UserID:   userID,
+			Name:     userID,
+			Presence: f.Presence[userID],
+		}
+		for _, user := range f.Users {
+			if user.ID == userID {
+				member.Name = user.Name
+				member.RealName = user.RealName
+			}
+		}
+		members = append(members, member)
+	}
+	return members, "", nil
+}
+
+func (f *FakeService) GetPostingPolicy(ctx context.Context, channelID string) (PostingPolicy, error) {
+	return f.Posting[channelID], nil
+}
+
+func (f *FakeService) SubscribePresence(userIDs []string) {}
+
+func (f *FakeService) GetUnreadCounts(ctx context.Context, channelIDs []string, workers int) <-chan UnreadCount {
+	results := make(chan UnreadCount, len(channelIDs))
+	for _, channelID := range channelIDs {
+		results <- UnreadCount{ChannelID: channelID, Count: f.Unread[channelID]}
+	}
+	close(results)
+	return results
+}
+
+func (f *FakeService) GetStartupChannel(ctx context.Context) (string, error) {
+	for _, chn := range f.Channels {
+		if f.Unread[chn.ID] > 0 {
+			return chn.ID, nil
+		}
+	}
+	return "", nil
+}
+
+func (f *FakeService) MarkAsRead(ctx context.Context, channelItem components.ChannelItem) {
+	delete(f.Unread, channelItem.ID)
+}
+
+func (f *FakeService) SetChannelOrder(channelIDs []string) error {
+	for i, channelID := range channelIDs {
+		for j := range f.Channels {
+			if f.Channels[j].ID == channelID {
+				f.Channels[j].Position = i + 1
+			}
+		}
+	}
+	return nil
+}
+
+func (f *FakeService) SetMute(channelID string, muted bool) error {
+	f.Mutes[channelID] = muted
+	return nil
+}
+
+func (f *FakeService) SetStar(ctx context.Context, channelID string, starred bool) error {
+	f.Stars[channelID] = starred
+	return nil
+}
+
+func (f *FakeService) SetSlackMutes(mutes map[string]bool) {}
+
+func (f *FakeService) IsMuted(channel components.ChannelItem) bool {
+	return f.Mutes[channel.ID]
+}
+
+func (f *FakeService) GetCurrentUserID() string {
+	return f.CurrentUserID
+}
+
+func (f *FakeService) GetCurrentUsername() string {
+	return f.CurrentUserID
+}
+
+func (f *FakeService) GetCurrentTeamName() string {
+	return f.TeamName
+}
+
+func (f *FakeService) GetCurrentTeam(ctx context.Context) (Team, error) {
+	return Team{ID: "T00000000", Name: f.TeamName}, nil
+}
+
+func (f *FakeService) GetUserPresence(ctx context.Context, userID string) (string, error) {
+	if presence, ok := f.Presence[userID]; ok {
+		return presence, nil
+	}
+	return "away", nil
+}
+
+func (f *FakeService) GetUserGroups(ctx context.Context) ([]UserGroup, error) {
+	return f.UserGroups, nil
+}
+
+func (f *FakeService) SearchUsers(ctx context.Context, query string) ([]User, error) {
+	var users []User
+	for _, user := range f.Users {
+		if strings.HasPrefix(strings.ToLower(user.Name), strings.ToLower(query)) {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (f *FakeService) GetDoNotDisturb(ctx context.Context) (slack.DNDStatus, error) {
+	return slack.DNDStatus{}, nil
+}
+
+func (f *FakeService) SetUserPresence(ctx context.Context, presence string) error {
+	f.Presence[f.CurrentUserID] = presence
+	return nil
+}
+
+func (f *FakeService) GetMessages(ctx context.Context, channelID string, count int, daysToFetch int) ([]components.Message, []components.ChannelItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	messages := f.Messages[channelID]
+	if len(messages) > count {
+		messages = messages[len(messages)-count:]
+	}
+
+	// Threads are ordered from newest to oldest
+	var threads []components.ChannelItem
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Thread != "" {
+			threads = append(threads, f.CreateThreadItem(messages[i]))
+		}
+	}
+
+	return append([]components.Message{}, messages...), threads, nil
+}
+
+func (f *FakeService) GetMissedMessages(ctx context.Context, channelID string, oldest string, latest string, count int) ([]components.Message, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var messages []components.Message
+	for _, msg := range f.Messages[channelID] {
+		if msg.ID > oldest && msg.ID < latest {
+			messages = append(messages, msg)
+		}
+	}
+
+	if len(messages) > count {
+		return messages[len(messages)-count:], true, nil
+	}
+	return messages, false, nil
+}
+
+func (f *FakeService) AddReaction(ctx context.Context, channelID string, messageID string, name string) error {
+	return nil
+}
+
+func (f *FakeService) RemoveReaction(ctx context.Context, channelID string, messageID string, name string) error {
+	return nil
+}
+
+func (f *FakeService) GetReactionName(channelID string, name string) string {
+	return ":" + name + ":"
+}
+
+func (f *FakeService) GetCustomEmoji() []string {
+	return f.CustomEmoji
+}
+
+func (f *FakeService) GetFrequentEmoji() []string {
+	return f.FrequentEmoji
+}
+
+func (f *FakeService) GetDigest(ctx context.Context, day time.Time) (Digest, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+
+	digest := Digest{Day: start}
+	for _, chn := range f.Channels {
+		conversation := DigestConversation{ID: chn.ID, Name: chn.Name}
+		for _, msg := range f.Messages[chn.ID] {
+			if msg.Time.Before(start) || !msg.Time.Before(end) {
+				continue
+			}
+
+			conversation.Messages++
+			if msg.UserID == f.CurrentUserID {
+				digest.Sent++
+			}
+		}
+
+		digest.Conversations++
+		if conversation.Messages > 0 {
+			digest.Messages += conversation.Messages
+			digest.Active = append(digest.Active, conversation)
+		}
+	}
+
+	for _, mention := range f.Mentions {
+		if !mention.Time.Before(start) && mention.Time.Before(end) {
+			digest.Mentions = append(digest.Mentions, mention)
+		}
+	}
+
+	return digest, nil
+}
+
+func (f *FakeService) GetCachedMessages(ctx context.Context, channelID string) ([]components.Message, []components.ChannelItem, bool) {
+	return nil, nil, false
+}
+
+func (f *FakeService) PrefetchMessages(ctx context.Context, channelID string, count int, daysToFetch int) error {
+	return nil
+}
+
+func (f *FakeService) GetMessageByID(ctx context.Context, messageID string, channelID string) ([]components.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, msg := range f.Messages[channelID] {
+		if msg.ID == messageID {
+			msg.Messages = make(map[string]components.Message)
+			for _, reply := range f.Replies[messageID] {
+				msg.Messages[reply.ID] = reply
+			}
+			msg.RepliesLoaded = true
+			return []components.Message{msg}, nil
+		}
+	}
+	return nil, errors.New("message_not_found")
+}
+
+func (f *FakeService) CreateMessageFromMessageEvent(message *slack.MessageEvent, channelID string) (components.Message, error) {
+	if message.SubType == "message_replied" {
+		return components.Message{}, errors.New("ignoring reply events")
+	}
+
+	ts, _ := strconv.ParseFloat(message.Timestamp, 64)
+	return components.Message{
+		ID:         message.Timestamp,
+		Time:       time.Unix(int64(ts), 0),
+		Name:       message.User,
+		Content:    message.Text,
+		FormatTime: "15:04",
+	}, nil
+}
+
+func (f *FakeService) SendMessage(ctx context.Context, channelID string, message string) error {
+	return f.SendReply(ctx, channelID, "", message)
+}
+
+// ScheduledMessage is a message that has been scheduled with the
+// FakeService, it isn't sent
+type ScheduledMessage struct {
+	ChannelID string
+	Message   string
+	PostAt    time.Time
+}
+
+func (f *FakeService) ScheduleMessage(ctx context.Context, channelID string, message string, postAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.Scheduled = append(f.Scheduled, ScheduledMessage{
+		ChannelID: channelID,
+		Message:   message,
+		PostAt:    postAt,
+	})
+	return nil
+}
+
+func (f *FakeService) GetUserTimezone(ctx context.Context, userID string) (*time.Location, error) {
+	if loc, ok := f.Timezones[userID]; ok {
+		return loc, nil
+	}
+	return time.UTC, nil
+}
+
+func (f *FakeService) EditMessage(ctx context.Context, channelID string, messageID string, message string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, msg := range f.Messages[channelID] {
+		if msg.ID == messageID {
+			f.Messages[channelID][i].Content = message
+			f.Messages[channelID][i].Edited = true
+			return nil
+		}
+	}
+	return errors.New("message_not_found")
+}
+
+func (f *FakeService) DeleteMessage(ctx context.Context, channelID string, messageID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, msg := range f.Messages[channelID] {
+		if msg.ID == messageID {
+			f.Messages[channelID] = append(f.Messages[channelID][:i], f.Messages[channelID][i+1:]...)
+			return nil
+		}
+	}
+	return errors.New("message_not_found")
+}
+
+func (f *FakeService) SendReply(ctx context.Context, channelID string, threadID string, message string) error {
+	msg := f.AddMessage(channelID, threadID, f.CurrentUserID, message)
+
+	ev := &slack.MessageEvent{}
+	ev.Channel = channelID
+	ev.User = f.CurrentUserID
+	ev.Text = message
+	ev.Timestamp = msg.ID
+	ev.ThreadTimestamp = threadID
+
+	select {
+	case f.Events <- slack.RTMEvent{Type: "message", Data: ev}:
+	default:
+	}
+
+	return nil
+}
+
+func (f *FakeService) SendCommand(ctx context.Context, channelID string, message string) (bool, error) {
+	return strings.HasPrefix(message, "/"), nil
+}
+
+func (f *FakeService) PreviewMessage(ctx context.Context, channelID string, message string) components.Message {
+	return components.Message{
+		ID:         "preview",
+		Time:       time.Now(),
+		Name:       f.CurrentUserID,
+		Content:    message,
+		FormatTime: "15:04",
+	}
+}
+
+func (f *FakeService) LoadReplies(ctx context.Context, msg components.Message, channelID string) (components.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	msg.Messages = make(map[string]components.Message)
+	for _, reply := range f.Replies[msg.ID] {
+		msg.Messages[reply.ID] = reply
+	}
+	msg.RepliesCursor = ""
+	msg.RepliesLoaded = true
+
+	return msg, nil
+}
+
+func (f *FakeService) GetReplies(ctx context.Context, messageID string, channelID string, cursor string) ([]components.Message, string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var replies []components.Message
+	for _, reply := range f.Replies[messageID] {
+		if cursor == "" || reply.ID < cursor {
+			replies = append(replies, reply)
+		}
+	}
+	return replies, "", nil
+}
+
+func (f *FakeService) GetThreadPrefix(threadTimestamp string) string {
+	ts, _ := strconv.ParseFloat(threadTimestamp, 64)
+	return fmt.Sprintf("%s ", hashID(int(ts)))
+}
+
+func (f *FakeService) CreateThreadItem(parent components.Message) components.ChannelItem {
+	return components.ChannelItem{
+		ID:   parent.ID,
+		Name: parent.Thread,
+		Type: components.ChannelTypeGroup,
+	}
+}
+
+func (f *FakeService) GetMentions(ctx context.Context) ([]components.MentionItem, error) {
+	return f.Mentions, nil
+}
+
+func (f *FakeService) GetFollowUps() ([]components.FollowUpItem, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	followUps := append([]components.FollowUpItem{}, f.FollowUps...)
+	sortFollowUps(followUps)
+	return followUps, nil
+}
+
+func (f *FakeService) SetFollowUp(followUp components.FollowUpItem) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, item := range f.FollowUps {
+		if item.ChannelID == followUp.ChannelID && item.MessageID == followUp.MessageID {
+			f.FollowUps[i] = followUp
+			return nil
+		}
+	}
+
+	f.FollowUps = append(f.FollowUps, followUp)
+	return nil
+}
+
+func (f *FakeService) RemoveFollowUp(channelID string, messageID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, item := range f.FollowUps {
+		if item.ChannelID == channelID && item.MessageID == messageID {
+			f.FollowUps = append(f.FollowUps[:i], f.FollowUps[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (f *FakeService) GetFiles(ctx context.Context, channelID string) ([]components.FileItem, error) {
+	return append([]components.FileItem{}, f.Files[channelID]...), nil
+}
+
+func (f *FakeService) GetBookmarks(ctx context.Context, channelID string) ([]components.BookmarkItem, error) {
+	return append([]components.BookmarkItem{}, f.Bookmarks[channelID]...), nil
+}
+
+func (f *FakeService) GetChannelInfo(ctx context.Context, channelID string) (components.ChannelDetails, error) {
+	for _, chn := range f.Channels {
+		if chn.ID == channelID {
+			return components.ChannelDetails{
+				Topic:       chn.Topic,
+				Purpose:     chn.Purpose,
+				MemberCount: len(f.Members[channelID]),
+				IsExtShared: chn.IsExtShared,
+			}, nil
+		}
+	}
+	return components.ChannelDetails{}, errors.New("channel_not_found")
+}
+
+func (f *FakeService) GetCanvasLink(ctx context.Context, channelID string) (string, error) {
+	return f.Canvases[channelID], nil
+}
+
+func (f *FakeService) DownloadFile(ctx context.Context, file components.FileItem, w io.Writer) error {
+	_, err := io.WriteString(w, f.Contents[file.ID])
+	return err
+}
+
+func (f *FakeService) DeleteFile(ctx context.Context, fileID string) error {
+	for channelID, files := range f.Files {
+		for i, file := range files {
+			if file.ID == fileID {
+				f.Files[channelID] = append(files[:i], files[i+1:]...)
+				delete(f.Contents, fileID)
+				return nil
+			}
+		}
+	}
+	return errors.New("file_not_found")
+}
+
+func (f *FakeService) SetMark(mark string, channelID string) error {
+	f.Marks[mark] = channelID
+	return nil
+}
+
+func (f *FakeService) GetMark(mark string) (string, bool) {
+	channelID, ok := f.Marks[mark]
+	return channelID, ok
+}
+
+func (f *FakeService) IncomingEvents() chan slack.RTMEvent {
+	return f.Events
+}
+
+func (f *FakeService) Close() error {
+	return nil
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/erroneousboat/slack-term/components"
+)
+
+// imageDetails are the details of an image that is attached to a message,
+// see formatImage
+type imageDetails struct {
+	Name   string
+	Title  string
+	Alt    string
+	Width  int
+	Height int
+	URL    string
+}
+
+// formatImage returns how an image that is attached to a message is shown
+// in the Chat pane, instead of its url, e.g.:
+//
+//	[image] screenshot.png 1920×1080 "Login page"
+func (s *SlackService) formatImage(image imageDetails) string {
+	parts := []string{"[image]", image.Name}
+
+	if image.Width > 0 && image.Height > 0 {
+		parts = append(parts, fmt.Sprintf("%d×%d", image.Width, image.Height))
+	}
+
+	if image.Title != "" && image.Title != image.Name {
+		parts = append(parts, fmt.Sprintf("%q", image.Title))
+	}
+
+	if image.Alt != "" && image.Alt != image.Title {
+		parts = append(parts, fmt.Sprintf("alt: %s", image.Alt))
+	}
+
+	// Files that are shared in slack are opened or downloaded from the
+	// Files pane, other images by their url
+	if image.URL != "" {
+		parts = append(parts, image.URL)
+	} else {
+		parts = s.appendFilesHint(parts)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// formatMedia returns how an audio or video file that is attached to a
+// message is shown in the Chat pane, with an icon of its type, e.g.:
+//
+//	♪ recording.m4a 0:42 1.2 MB
+func (s *SlackService) formatMedia(file slack.File, duration time.Duration) string {
+	parts := []string{components.MediaIcon(file.Mimetype), file.Name}
+
+	if duration > 0 {
+		parts = append(parts, components.FormatDuration(duration))
+	}
+
+	parts = append(parts, components.FormatSize(file.Size))
+
+	if file.Title != "" && file.Title != file.Name {
+		parts = append(parts, fmt.Sprintf("%q", file.Title))
+	}
+
+	return strings.Join(s.appendFilesHint(parts), " ")
+}
+
+// appendFilesHint will append the key of the Files pane to parts, the files
+// that are shared in slack are opened or downloaded from there
+func (s *SlackService) appendFilesHint(parts []string) []string {
+	if key, ok := s.Config.GetKey("command", "mode-files"); ok {
+		parts = append(parts, fmt.Sprintf("(%s to open or download)", key))
+	}
+	return parts
+}
+
+// isImage returns whether a file that is shared is an image
+func isImage(file slack.File) bool {
+	return strings.HasPrefix(file.Mimetype, "image/")
+}
+
+// imageName returns the name of the file of an image url
+func imageName(url string) string {
+	name := path.Base(strings.SplitN(url, "?", 2)[0])
+	if name == "." || name == "/" {
+		return "image"
+	}
+	return name
+}
+
+// filesPageSize is the number of recent files that is fetched of a channel
+const filesPageSize = 100
+
+// mediaFile is a file together with the duration of audio and video files,
+// the slack package doesn't decode the duration
+type mediaFile struct {
+	slack.File
+	DurationMS int64 `json:"duration_ms,omitempty"`
+}
+
+type filesResponse struct {
+	slack.SlackResponse
+	Files []mediaFile `json:"files"`
+}
+
+// GetFiles returns the files that have been shared most recently in a
+// channel, newest first
+func (s *SlackService) GetFiles(ctx context.Context, channelID string) ([]components.FileItem, error) {
+	// https://api.slack.com/methods/files.list
+	values := url.Values{
+		"channel": {channelID},
+		"count":   {strconv.Itoa(filesPageSize)},
+	}
+
+	var response filesResponse
+	if err := s.callAPI(ctx, "files.list", values, &response); err != nil {
+		return nil, err
+	}
+	if err := response.Err(); err != nil {
+		return nil, err
+	}
+
+	items := make([]components.FileItem, 0, len(response.Files))
+	for _, file := range response.Files {
+		name, _ := s.GetUserName(ctx, file.User)
+		items = append(items, components.FileItem{
+			ID:        file.ID,
+			Name:      file.Name,
+			Title:     file.Title,
+			User:      name,
+			Filetype:  file.Filetype,
+			Mimetype:  file.Mimetype,
+			Size:      file.Size,
+			Width:     file.OriginalW,
+			Height:    file.OriginalH,
+			Duration:  time.Duration(file.DurationMS) * time.Millisecond,
+			Created:   file.Created.Time(),
+			URL:       file.URLPrivateDownload,
+			Permalink: file.Permalink,
+			Preview:   file.Preview,
+		})
+	}
+
+	return items, nil
+}
+
+// DownloadFile will write the content of the file to w
+func (s *SlackService) DownloadFile(ctx context.Context, file components.FileItem, w io.Writer) error {
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	// The download is made with the http client of the slack client, so
+	// the cookie is sent along, and it's cancelled with ctx
+	req, err := http.NewRequest("GET", file.URL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.accessToken())
+
+	resp, err := s.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unable to download %s: %s", file.Name, resp.Status)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// DeleteFile will delete a file, which is only allowed for the files that
+// have been shared by the user
+func (s *SlackService) DeleteFile(ctx context.Context, fileID string) error {
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	return s.Client().DeleteFileContext(ctx, fileID)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"github.com/erroneousboat/slack-term/components"
+)
+
+// GetFollowUps returns the messages that have been flagged for follow-up,
+// stored in the persistent cache. They're sorted by due time, the ones
+// without a due time last.
+func (s *SlackService) GetFollowUps() ([]components.FollowUpItem, error) {
+	if s.PersistentCache == nil {
+		return nil, errNoPersistentCache
+	}
+
+	cached, err := s.PersistentCache.GetFollowUps(s.CurrentTeamID)
+	if err != nil {
+		return nil, err
+	}
+
+	followUps := make([]components.FollowUpItem, 0, len(cached))
+	for _, f := range cached {
+		item := components.FollowUpItem{
+			ChannelID:   f.ChannelID,
+			ChannelName: f.ChannelID,
+			MessageID:   f.MessageID,
+			Name:        f.Name,
+			Content:     f.Content,
+			Reminded:    f.Reminded,
+		}
+		if f.Due > 0 {
+			item.Due = time.Unix(f.Due, 0)
+		}
+
+		followUps = append(followUps, item)
+	}
+
+	sortFollowUps(followUps)
+
+	return followUps, nil
+}
+
+// sortFollowUps will sort the follow-ups by due time, the ones without a
+// due time last by the time of their message
+func sortFollowUps(followUps []components.FollowUpItem) {
+	sort.SliceStable(followUps, func(i, j int) bool {
+		a, b := followUps[i], followUps[j]
+		if a.Due.IsZero() != b.Due.IsZero() {
+			return !a.Due.IsZero()
+		}
+		if !a.Due.Equal(b.Due) {
+			return a.Due.Before(b.Due)
+		}
+		return a.MessageID < b.MessageID
+	})
+}
+
+// SetFollowUp will flag a message for follow-up, or update the due time of
+// a message that has already been flagged
+func (s *SlackService) SetFollowUp(followUp components.FollowUpItem) error {
+	if s.PersistentCache == nil {
+		return errNoPersistentCache
+	}
+
+	var due int64
+	if !followUp.Due.IsZero() {
+		due = followUp.Due.Unix()
+	}
+
+	return s.PersistentCache.SetFollowUp(s.CurrentTeamID, CachedFollowUp{
+		ChannelID: followUp.ChannelID,
+		MessageID: followUp.MessageID,
+		Name:      followUp.Name,
+		Content:   followUp.Content,
+		Due:       due,
+		Reminded:  followUp.Reminded,
+	})
+}
+
+// RemoveFollowUp will remove the follow-up flag of a message
+func (s *SlackService) RemoveFollowUp(channelID string, messageID string) error {
+	if s.PersistentCache == nil {
+		return errNoPersistentCache
+	}
+
+	return s.PersistentCache.DeleteFollowUp(s.CurrentTeamID, channelID, messageID)
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/erroneousboat/slack-term/components"
+)
+
+// GetChannelInfo will get the details of a channel, that are shown in the
+// info popup
+//
+// https://api.slack.com/methods/conversations.info
+func (s *SlackService) GetChannelInfo(ctx context.Context, channelID string) (components.ChannelDetails, error) {
+	var info struct {
+		slack.SlackResponse
+		Channel struct {
+			Topic       slack.Topic   `json:"topic"`
+			Purpose     slack.Purpose `json:"purpose"`
+			Created     int64         `json:"created"`
+			NumMembers  int           `json:"num_members"`
+			IsShared    bool          `json:"is_shared"`
+			IsOrgShared bool          `json:"is_org_shared"`
+			IsExtShared bool          `json:"is_ext_shared"`
+		} `json:"channel"`
+	}
+
+	values := url.Values{
+		"channel":             {channelID},
+		"include_num_members": {"true"},
+	}
+
+	err := s.callAPI(ctx, "conversations.info", values, &info)
+	if err != nil {
+		return components.ChannelDetails{}, err
+	}
+	if err := info.Err(); err != nil {
+		return components.ChannelDetails{}, err
+	}
+
+	channel := info.Channel
+	details := components.ChannelDetails{
+		Topic:       channel.Topic.Value,
+		Purpose:     channel.Purpose.Value,
+		MemberCount: channel.NumMembers,
+		IsShared:    channel.IsShared || channel.IsOrgShared,
+		IsExtShared: channel.IsExtShared,
+	}
+	if channel.Created > 0 {
+		details.Created = time.Unix(channel.Created, 0)
+	}
+
+	return details, nil
+}
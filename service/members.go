@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/erroneousboat/slack-term/components"
+)
+
+// membersMaxAge is the age after which the cached members of a channel are
+// fetched again
+const membersMaxAge = time.Hour
+
+// membersPageSize is the number of members that is fetched per request
+const membersPageSize = 1000
+
+// membersPanePageSize is the number of members that is fetched per page of
+// the members pane
+const membersPanePageSize = 100
+
+type channelMembers struct {
+	userIDs   []string
+	updatedAt time.Time
+}
+
+// GetChannelMembers returns the ids of the members of a channel. The
+// members are cached, when they're older than membersMaxAge the cached
+// members are returned while they're refreshed in the background.
+func (s *SlackService) GetChannelMembers(ctx context.Context, channelID string) ([]string, error) {
+	s.membersMu.Lock()
+	members, ok := s.members[channelID]
+	s.membersMu.Unlock()
+
+	if !ok && s.PersistentCache != nil {
+		userIDs, updatedAt, found := s.PersistentCache.GetMembers(channelID)
+		if found {
+			members = channelMembers{userIDs: userIDs, updatedAt: updatedAt}
+			ok = true
+			s.setChannelMembers(channelID, members)
+		}
+	}
+
+	if !ok {
+		return s.refreshChannelMembers(ctx, channelID)
+	}
+
+	if time.Since(members.updatedAt) > membersMaxAge {
+		go s.refreshChannelMembers(context.Background(), channelID)
+	}
+
+	return members.userIDs, nil
+}
+
+// refreshChannelMembers will fetch the members of a channel, and update
+// the cache with them
+func (s *SlackService) refreshChannelMembers(ctx context.Context, channelID string) ([]string, error) {
+	var userIDs []string
+	var cursor string
+	for {
+		if s.RateLimiter != nil {
+			if err := s.RateLimiter.WaitContext(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		page, nextCursor, err := s.Client().GetUsersInConversationContext(
+			ctx,
+			&slack.GetUsersInConversationParameters{
+				ChannelID: channelID,
+				Cursor:    cursor,
+				Limit:     membersPageSize,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		userIDs = append(userIDs, page...)
+
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	s.setChannelMembers(channelID, channelMembers{
+		userIDs:   userIDs,
+		updatedAt: time.Now(),
+	})
+
+	if s.PersistentCache != nil {
+		s.PersistentCache.SetMembers(channelID, userIDs)
+	}
+
+	return userIDs, nil
+}
+
+// GetMembersPage returns a page of the members of a channel, starting at
+// cursor, with their names resolved. It also returns the cursor of the
+// next page, which is empty when it's the last page.
+func (s *SlackService) GetMembersPage(ctx context.Context, channelID string, cursor string) ([]components.MemberItem, string, error) {
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return nil, "", err
+		}
+	}
+
+	userIDs, nextCursor, err := s.Client().GetUsersInConversationContext(
+		ctx,
+		&slack.GetUsersInConversationParameters{
+			ChannelID: channelID,
+			Cursor:    cursor,
+			Limit:     membersPanePageSize,
+		},
+	)
+	if err != nil {
+		return nil, "", err
+	}
+
+	members := make([]components.MemberItem, 0, len(userIDs))
+	for _, userID := range userIDs {
+		name, err := s.GetUserName(ctx, userID)
+		if err != nil {
+			continue
+		}
+
+		members = append(members, components.MemberItem{
+			UserID:   userID,
+			Name:     name,
+			RealName: s.getRealName(userID),
+			Presence: components.PresenceAway,
+		})
+	}
+
+	return members, nextCursor, nil
+}
+
+// SubscribePresence will subscribe to the presence changes of the users,
+// next to the users of the direct messages. A subscription replaces the
+// previous one.
+func (s *SlackService) SubscribePresence(userIDs []string) {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, chn := range s.Conversations {
+		if chn.IsIM && !seen[chn.User] {
+			seen[chn.User] = true
+			ids = append(ids, chn.User)
+		}
+	}
+
+	for _, userID := range userIDs {
+		if !seen[userID] {
+			seen[userID] = true
+			ids = append(ids, userID)
+		}
+	}
+
+	rtm := s.RTM()
+	rtm.SendMessage(rtm.NewSubscribeUserPresence(ids))
+}
+
+func (s *SlackService) setChannelMembers(channelID string, members channelMembers) {
+	s.membersMu.Lock()
+	defer s.membersMu.Unlock()
+
+	s.members[channelID] = members
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/erroneousboat/slack-term/components"
+)
+
+// mentionsCount is the number of recent mentions that is fetched
+const mentionsCount = 50
+
+// GetMentions returns the messages that mention the user most recently
+// across the workspace, newest first. They're found with search.messages,
+// which isn't available to bot tokens.
+func (s *SlackService) GetMentions(ctx context.Context) ([]components.MentionItem, error) {
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	params := slack.NewSearchParameters()
+	params.Sort = "timestamp"
+	params.SortDirection = "desc"
+	params.Count = mentionsCount
+
+	result, err := s.Client().SearchMessagesContext(
+		ctx, fmt.Sprintf("<@%s>", s.CurrentUserID), params,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	mentions := make([]components.MentionItem, 0, len(result.Matches))
+	for _, match := range result.Matches {
+		name, _ := s.GetUserName(ctx, match.User)
+		ts, _ := strconv.ParseFloat(match.Timestamp, 64)
+
+		mentions = append(mentions, components.MentionItem{
+			ChannelID:   match.Channel.ID,
+			ChannelName: match.Channel.Name,
+			MessageID:   match.Timestamp,
+			Name:        name,
+			Content:     parseMessage(ctx, s, match.Channel.ID, match.Text),
+			Time:        time.Unix(int64(ts), 0),
+		})
+	}
+
+	return mentions, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/erroneousboat/slack-term/components"
+	"github.com/slack-go/slack"
+)
+
+type userPrefsResponse struct {
+	slack.SlackResponse
+	Prefs struct {
+		MutedChannels string `json:"muted_channels"`
+	} `json:"prefs"`
+}
+
+// getSlackMutes returns the ids of the channels that the user has muted in
+// slack, they're stored in the preferences of the user as a comma separated
+// list.
+//
+// users.prefs.get isn't documented, it's the endpoint the web client uses.
+func (s *SlackService) getSlackMutes(ctx context.Context) (map[string]bool, error) {
+	var resp userPrefsResponse
+	if err := s.callAPI(ctx, "users.prefs.get", url.Values{}, &resp); err != nil {
+		return nil, err
+	}
+	if err := resp.Err(); err != nil {
+		return nil, err
+	}
+
+	return ParseMutedChannels(resp.Prefs.MutedChannels), nil
+}
+
+// ParseMutedChannels returns the ids of the channels in the comma separated
+// list of the muted_channels preference
+func ParseMutedChannels(value string) map[string]bool {
+	mutes := make(map[string]bool)
+	for _, channelID := range strings.Split(value, ",") {
+		if channelID = strings.TrimSpace(channelID); channelID != "" {
+			mutes[channelID] = true
+		}
+	}
+	return mutes
+}
+
+// SetSlackMutes will replace the channels that are muted in slack, e.g.
+// when the preference has been changed in another client
+func (s *SlackService) SetSlackMutes(mutes map[string]bool) {
+	s.prefsMu.Lock()
+	defer s.prefsMu.Unlock()
+
+	s.slackMutes = mutes
+}
+
+// IsMuted returns whether the channel is muted: in slack, with SetMute, or
+// by its name or id in muted_channels of the config
+func (s *SlackService) IsMuted(channel components.ChannelItem) bool {
+	s.prefsMu.RLock()
+	muted := s.Mutes[channel.ID] || s.slackMutes[channel.ID]
+	s.prefsMu.RUnlock()
+	if muted {
+		return true
+	}
+
+	for _, name := range s.Config.MutedChannels {
+		if strings.TrimLeft(name, "#@") == channel.Name || name == channel.ID {
+			return true
+		}
+	}
+
+	return false
+}
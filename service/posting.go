@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/slack-go/slack"
+)
+
+// PostingPolicy is whether the current user is allowed to post in a
+// channel, announcement channels only allow some users to post
+type PostingPolicy struct {
+	// ReadOnly is set when messages can't be posted in the channel, and
+	// ThreadsReadOnly when replies can't be posted in its threads
+	ReadOnly        bool
+	ThreadsReadOnly bool
+}
+
+type postingRestriction struct {
+	Type []string `json:"type"`
+	User []string `json:"user"`
+}
+
+// GetPostingPolicy returns whether the current user is allowed to post in a
+// channel. It's fetched once per channel and cached for the rest of the
+// session.
+//
+// https://api.slack.com/methods/conversations.info
+func (s *SlackService) GetPostingPolicy(ctx context.Context, channelID string) (PostingPolicy, error) {
+	s.postingMu.Lock()
+	policy, ok := s.posting[channelID]
+	s.postingMu.Unlock()
+
+	if ok {
+		return policy, nil
+	}
+
+	var info struct {
+		slack.SlackResponse
+		Channel struct {
+			IsReadOnly   bool `json:"is_read_only"`
+			IsThreadOnly bool `json:"is_thread_only"`
+			IsArchived   bool `json:"is_archived"`
+			Properties   struct {
+				PostingRestrictedTo *postingRestriction `json:"posting_restricted_to"`
+				ThreadsRestrictedTo *postingRestriction `json:"threads_restricted_to"`
+			} `json:"properties"`
+		} `json:"channel"`
+	}
+
+	err := s.callAPI(
+		ctx, "conversations.info", url.Values{"channel": {channelID}}, &info,
+	)
+	if err != nil {
+		return policy, err
+	}
+	if err := info.Err(); err != nil {
+		return policy, err
+	}
+
+	channel := info.Channel
+	policy.ReadOnly = channel.IsReadOnly || channel.IsThreadOnly || channel.IsArchived ||
+		!s.isAllowedToPost(ctx, channel.Properties.PostingRestrictedTo)
+	policy.ThreadsReadOnly = channel.IsReadOnly || channel.IsArchived ||
+		!s.isAllowedToPost(ctx, channel.Properties.ThreadsRestrictedTo)
+
+	s.postingMu.Lock()
+	s.posting[channelID] = policy
+	s.postingMu.Unlock()
+
+	return policy, nil
+}
+
+// isAllowedToPost returns whether the current user is one of the users, or
+// has one of the roles, that posting is restricted to
+func (s *SlackService) isAllowedToPost(ctx context.Context, restriction *postingRestriction) bool {
+	if restriction == nil || (len(restriction.Type) == 0 && len(restriction.User) == 0) {
+		return true
+	}
+
+	for _, userID := range restriction.User {
+		if userID == s.CurrentUserID {
+			return true
+		}
+	}
+
+	if len(restriction.Type) == 0 {
+		return false
+	}
+
+	// When the role of the current user can't be determined we'll let the
+	// user try, slack will reject the message when it isn't allowed
+	user, err := s.getCurrentUser(ctx)
+	if err != nil {
+		return true
+	}
+
+	for _, role := range restriction.Type {
+		switch role {
+		case "admin":
+			if user.IsAdmin || user.IsOwner || user.IsPrimaryOwner {
+				return true
+			}
+		case "owner":
+			if user.IsOwner || user.IsPrimaryOwner {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// getCurrentUser returns the profile of the current user, it's fetched once
+// and cached for the rest of the session
+func (s *SlackService) getCurrentUser(ctx context.Context) (*slack.User, error) {
+	s.postingMu.Lock()
+	user := s.currentUser
+	s.postingMu.Unlock()
+
+	if user != nil {
+		return user, nil
+	}
+
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.Client().GetUserInfoContext(ctx, s.CurrentUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.postingMu.Lock()
+	s.currentUser = user
+	s.postingMu.Unlock()
+
+	return user, nil
+}
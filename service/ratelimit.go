@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"sync"
 	"time"
 )
@@ -22,7 +23,9 @@ func NewRateLimiter(maxTokens int, refillRate time.Duration) *RateLimiter {
 	}
 }
 
-func (r *RateLimiter) Wait() {
+// WaitContext will wait until a request can be made, it stops waiting when
+// ctx is cancelled
+func (r *RateLimiter) WaitContext(ctx context.Context) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -43,11 +46,17 @@ func (r *RateLimiter) Wait() {
 	if r.tokens <= 0 {
 		waitTime := r.refillRate
 		r.mu.Unlock()
-		time.Sleep(waitTime)
+		select {
+		case <-time.After(waitTime):
+		case <-ctx.Done():
+			r.mu.Lock()
+			return ctx.Err()
+		}
 		r.mu.Lock()
 		r.tokens = 1
 		r.lastRefill = time.Now()
 	}
 
 	r.tokens--
+	return nil
 }
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// GetUserTimezone returns the timezone of a user, as it's set in their
+// profile
+func (s *SlackService) GetUserTimezone(ctx context.Context, userID string) (*time.Location, error) {
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := s.Client().GetUserInfoContext(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The offset is used when the timezone database doesn't know the
+	// timezone
+	if user.TZ != "" {
+		if loc, err := time.LoadLocation(user.TZ); err == nil {
+			return loc, nil
+		}
+	}
+
+	return time.FixedZone(user.TZLabel, user.TZOffset), nil
+}
+
+// ScheduleMessage will schedule a message to be sent at postAt, see:
+// https://api.slack.com/methods/chat.scheduleMessage
+func (s *SlackService) ScheduleMessage(ctx context.Context, channelID string, message string, postAt time.Time) error {
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	postParams := slack.MsgOptionPostMessageParameters(slack.PostMessageParameters{
+		AsUser:    true,
+		Username:  s.CurrentUsername,
+		LinkNames: 1,
+	})
+
+	text := slack.MsgOptionText(s.encodeMessage(ctx, message), false)
+
+	_, _, _, err := s.Client().SendMessageContext(
+		ctx, channelID,
+		slack.MsgOptionSchedule(strconv.FormatInt(postAt.Unix(), 10)),
+		text, postParams,
+	)
+	return err
+}
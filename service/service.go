@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/erroneousboat/slack-term/components"
+)
+
+// ChatService is what the user interface uses to communicate with slack. It
+// is implemented by SlackService, and by FakeService which keeps everything
+// in memory so the user interface can be used without network access.
+type ChatService interface {
+	// Conversations
+	GetChannels(ctx context.Context) ([]components.ChannelItem, error)
+	GetChannelsPage(ctx context.Context, cursor string) ([]components.ChannelItem, string, error)
+	GetConversationsForUser(ctx context.Context) ([]components.ChannelItem, error)
+	GetConversations() []slack.Channel
+	GetPublicChannels(ctx context.Context, cursor string) ([]components.ChannelItem, string, error)
+	JoinChannel(ctx context.Context, channelID string) (components.ChannelItem, error)
+	CreateChannel(ctx context.Context, name string) (components.ChannelItem, error)
+	LeaveChannel(ctx context.Context, channelID string) error
+	ArchiveChannel(ctx context.Context, channelID string) error
+	OpenDirectMessage(ctx context.Context, userID string) (components.ChannelItem, error)
+	GetChannelTeams(ctx context.Context, channelID string) ([]string, []string, error)
+	GetChannelMembers(ctx context.Context, channelID string) ([]string, error)
+	GetMembersPage(ctx context.Context, channelID string, cursor string) ([]components.MemberItem, string, error)
+	GetPostingPolicy(ctx context.Context, channelID string) (PostingPolicy, error)
+	SubscribePresence(userIDs []string)
+	GetUnreadCounts(ctx context.Context, channelIDs []string, workers int) <-chan UnreadCount
+	GetStartupChannel(ctx context.Context) (string, error)
+	MarkAsRead(ctx context.Context, channelItem components.ChannelItem)
+	SetChannelOrder(channelIDs []string) error
+	SetMute(channelID string, muted bool) error
+	SetSlackMutes(mutes map[string]bool)
+	IsMuted(channel components.ChannelItem) bool
+	SetStar(ctx context.Context, channelID string, starred bool) error
+
+	// Users
+	GetCurrentUserID() string
+	GetCurrentUsername() string
+	GetCurrentTeamName() string
+	GetCurrentTeam(ctx context.Context) (Team, error)
+	GetUserPresence(ctx context.Context, userID string) (string, error)
+	GetUserGroups(ctx context.Context) ([]UserGroup, error)
+	SetUserPresence(ctx context.Context, presence string) error
+
+	// Messages
+	GetMessages(ctx context.Context, channelID string, count int, daysToFetch int) ([]components.Message, []components.ChannelItem, error)
+	GetCachedMessages(ctx context.Context, channelID string) ([]components.Message, []components.ChannelItem, bool)
+	GetReactionName(channelID string, name string) string
+	GetCustomEmoji() []string
+	GetFrequentEmoji() []string
+	SearchUsers(ctx context.Context, query string) ([]User, error)
+	GetDoNotDisturb(ctx context.Context) (slack.DNDStatus, error)
+	AddReaction(ctx context.Context, channelID string, messageID string, name string) error
+	RemoveReaction(ctx context.Context, channelID string, messageID string, name string) error
+	GetDigest(ctx context.Context, day time.Time) (Digest, error)
+	GetMissedMessages(ctx context.Context, channelID string, oldest string, latest string, count int) ([]components.Message, bool, error)
+	PrefetchMessages(ctx context.Context, channelID string, count int, daysToFetch int) error
+	GetMessageByID(ctx context.Context, messageID string, channelID string) ([]components.Message, error)
+	CreateMessageFromMessageEvent(message *slack.MessageEvent, channelID string) (components.Message, error)
+	SendMessage(ctx context.Context, channelID string, message string) error
+	ScheduleMessage(ctx context.Context, channelID string, message string, postAt time.Time) error
+	GetUserTimezone(ctx context.Context, userID string) (*time.Location, error)
+	EditMessage(ctx context.Context, channelID string, messageID string, message string) error
+	DeleteMessage(ctx context.Context, channelID string, messageID string) error
+	SendReply(ctx context.Context, channelID string, threadID string, message string) error
+	SendCommand(ctx context.Context, channelID string, message string) (bool, error)
+	PreviewMessage(ctx context.Context, channelID string, message string) components.Message
+
+	// Threads
+	LoadReplies(ctx context.Context, msg components.Message, channelID string) (components.Message, error)
+	GetReplies(ctx context.Context, messageID string, channelID string, cursor string) ([]components.Message, string, error)
+	GetThreadPrefix(threadTimestamp string) string
+	CreateThreadItem(parent components.Message) components.ChannelItem
+
+	// Mentions
+	GetMentions(ctx context.Context) ([]components.MentionItem, error)
+
+	// Follow-ups
+	GetFollowUps() ([]components.FollowUpItem, error)
+	SetFollowUp(followUp components.FollowUpItem) error
+	RemoveFollowUp(channelID string, messageID string) error
+
+	// Files
+	GetFiles(ctx context.Context, channelID string) ([]components.FileItem, error)
+	DownloadFile(ctx context.Context, file components.FileItem, w io.Writer) error
+	DeleteFile(ctx context.Context, fileID string) error
+
+	// Bookmarks
+	GetBookmarks(ctx context.Context, channelID string) ([]components.BookmarkItem, error)
+	GetCanvasLink(ctx context.Context, channelID string) (string, error)
+	GetChannelInfo(ctx context.Context, channelID string) (components.ChannelDetails, error)
+
+	// Marks
+	SetMark(mark string, channelID string) error
+	GetMark(mark string) (string, bool)
+
+	// IncomingEvents returns the channel on which the real time events
+	// are received
+	IncomingEvents() chan slack.RTMEvent
+
+	// Close will release the resources of the service
+	Close() error
+}
+
+var (
+	_ ChatService = (*SlackService)(nil)
+	_ ChatService = (*FakeService)(nil)
+)
+
+// GetConversations returns the conversations the user is a member of
+func (s *SlackService) GetConversations() []slack.Channel {
+	return s.Conversations
+}
+
+// GetCurrentUserID returns the id of the user associated with the token
+func (s *SlackService) GetCurrentUserID() string {
+	return s.CurrentUserID
+}
+
+// GetCurrentUsername returns the name of the user associated with the token
+func (s *SlackService) GetCurrentUsername() string {
+	return s.CurrentUsername
+}
+
+// GetCurrentTeamName returns the name of the workspace associated with the
+// token
+func (s *SlackService) GetCurrentTeamName() string {
+	s.teamsMu.Lock()
+	defer s.teamsMu.Unlock()
+
+	return s.TeamNames[s.CurrentTeamID]
+}
+
+// IncomingEvents returns the channel on which the RTM events are received
+func (s *SlackService) IncomingEvents() chan slack.RTMEvent {
+	return s.RTM().IncomingEvents
+}
+
+// disconnectTimeout is how long Close waits for the websocket to be
+// disconnected
+const disconnectTimeout = 2 * time.Second
+
+// Close will stop the rotation of the tokens, disconnect the websocket of
+// the RTM, and close the persistent cache once the writes that are running
+// have finished
+func (s *SlackService) Close() error {
+	s.stopRotating()
+
+	if rtm := s.RTM(); rtm != nil {
+		disconnected := make(chan struct{})
+		go func() {
+			rtm.Disconnect()
+			close(disconnected)
+		}()
+
+		select {
+		case <-disconnected:
+		case <-time.After(disconnectTimeout):
+		}
+	}
+
+	if s.PersistentCache != nil {
+		return s.PersistentCache.Close()
+	}
+	return nil
+}
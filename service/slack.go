@@ -1,6 +1,8 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"html"
@@ -20,17 +22,110 @@ import (
 	"github.com/erroneousboat/slack-term/config"
 )
 
+// repliesPageSize is the number of thread replies that are fetched at once
+const repliesPageSize = 100
+
+// channelsPageSize is the number of conversations that are fetched at once
+const channelsPageSize = 200
+
+// historyMaxAge is the age after which prefetched channel history is fetched
+// again
+const historyMaxAge = 5 * time.Minute
+
 type SlackService struct {
 	Config          *config.Config
-	Client          *slack.Client
-	RTM             *slack.RTM
 	Conversations   []slack.Channel
-	UserCache       map[string]string
 	PersistentCache *UserCache
-	ThreadCache     map[string]string
 	RateLimiter     *RateLimiter
+	CustomEmoji     []string
 	CurrentUserID   string
 	CurrentUsername string
+	CurrentTeamID   string
+
+	// UserCache, RealNameCache and DeletedUsers are the names of the users
+	// that have been looked up, and whether they've been deactivated
+	UserCache     map[string]string
+	RealNameCache map[string]string
+	DeletedUsers  map[string]bool
+	usersMu       sync.RWMutex
+
+	// ThreadCache are the thread timestamps keyed by their shortened
+	// identifier, see GetThreadPrefix
+	ThreadCache map[string]string
+	threadsMu   sync.Mutex
+
+	// TeamNames are the names of the teams that have been looked up, see
+	// GetTeamName
+	TeamNames map[string]string
+	teamsMu   sync.Mutex
+
+	// Marks, ChannelOrder, Mutes and Stars are the preferences of the
+	// channels, slackMutes are the channels that are muted in slack
+	Marks        map[string]string
+	ChannelOrder map[string]int
+	Mutes        map[string]bool
+	slackMutes   map[string]bool
+	Stars        map[string]bool
+	prefsMu      sync.RWMutex
+
+	// client and rtm are replaced when the access token is refreshed,
+	// see Client and RTM
+	client   *slack.Client
+	rtm      *slack.RTM
+	token    string
+	clientMu sync.RWMutex
+
+	httpClient     *http.Client
+	apiURL         string
+	clientOptions  []slack.Option
+	tokenExpiresAt time.Time
+
+	// stopRotation is closed to stop the rotation of the tokens, see
+	// rotateTokens
+	stopRotation chan struct{}
+	stopOnce     sync.Once
+
+	// members are the cached members of channels, see GetChannelMembers
+	members   map[string]channelMembers
+	membersMu sync.Mutex
+
+	// userGroups are the cached user groups, see GetUserGroups
+	userGroups   []UserGroup
+	userGroupsMu sync.Mutex
+
+	// mentions are the ids of the users that have been found with
+	// SearchUsers, keyed by their name, they're encoded as mentions in the
+	// messages that are sent. usersListed is set once all the users have
+	// been fetched.
+	mentions    map[string]string
+	usersListed bool
+	mentionsMu  sync.Mutex
+
+	// posting are the cached posting policies of channels, see
+	// GetPostingPolicy
+	posting     map[string]PostingPolicy
+	currentUser *slack.User
+	postingMu   sync.Mutex
+
+	// commands are the cached slash commands of the workspace, see
+	// getCommands
+	commands   map[string]bool
+	commandsMu sync.Mutex
+
+	// emojiUsage is how often the user has used emoji, keyed by their
+	// name, see GetFrequentEmoji
+	emojiUsage   map[string]int
+	emojiUsageMu sync.Mutex
+}
+
+// AuthError is returned when the client isn't able to authorize with the
+// slack token and cookie
+type AuthError struct {
+	Err error
+}
+
+func (e *AuthError) Error() string {
+	return "not able to authorize client, check your connection and if your slack-token is set correctly"
 }
 
 type cookieTransport struct {
@@ -45,16 +140,103 @@ func (t *cookieTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 // NewSlackService is the constructor for the SlackService and will initialize
 // the RTM and a Client
 func NewSlackService(config *config.Config) (*SlackService, error) {
+	svc, err := newSlackService(config)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.Connect()
+
+	// Creation of user cache this speeds up
+	// the uncovering of usernames of messages
+	// Note: Disabled bulk user fetch to avoid rate limits
+	// Users are now fetched on-demand and cached persistently
+	// if !config.IsEnterprise {
+	// 	users, _ := svc.Client().GetUsers()
+	// 	for _, user := range users {
+	// 		if !user.Deleted {
+	// 			svc.UserCache[user.ID] = user.Name
+	// 		}
+	// 	}
+	// }
+
+	// Get name of current user, and set presence to active
+	currentUsername, err := svc.GetUserName(context.Background(), svc.CurrentUserID)
+	if err != nil {
+		svc.CurrentUsername = "slack-term"
+	}
+	svc.CurrentUsername = currentUsername
+	svc.SetUserPresence(context.Background(), "auto")
+
+	return svc, nil
+}
+
+// Connect will create the RTM, and connect to the real time api. The events
+// are received on IncomingEvents.
+func (s *SlackService) Connect() {
+	s.clientMu.Lock()
+	s.rtm = s.client.NewRTM()
+	s.clientMu.Unlock()
+
+	go s.rtm.ManageConnection()
+}
+
+// Client returns the client of the slack web api, it uses the current
+// access token
+func (s *SlackService) Client() *slack.Client {
+	s.clientMu.RLock()
+	defer s.clientMu.RUnlock()
+
+	return s.client
+}
+
+// RTM returns the connection to the real time api, it's nil when the
+// service isn't connected
+func (s *SlackService) RTM() *slack.RTM {
+	s.clientMu.RLock()
+	defer s.clientMu.RUnlock()
+
+	return s.rtm
+}
+
+// accessToken returns the current access token
+func (s *SlackService) accessToken() string {
+	s.clientMu.RLock()
+	defer s.clientMu.RUnlock()
+
+	return s.token
+}
+
+// NewOfflineSlackService is the constructor for a SlackService without the
+// RTM, for the commands that run without the user interface. The presence
+// of the user isn't changed.
+func NewOfflineSlackService(config *config.Config) (*SlackService, error) {
+	svc, err := newSlackService(config)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.CurrentUsername, _ = svc.GetUserName(context.Background(), svc.CurrentUserID)
+
+	return svc, nil
+}
+
+// newSlackService will create and authorize the Client, and loads the state
+// of previous sessions from the persistent cache
+func newSlackService(config *config.Config) (*SlackService, error) {
 	var args []slack.Option
 
+	httpClient := http.DefaultClient
 	if config.SlackCookie != "" {
-		httpClient := &http.Client{
+		httpClient = &http.Client{
 			Transport: &cookieTransport{cookie: config.SlackCookie},
 		}
 		args = append(args, slack.OptionHTTPClient(httpClient))
 	}
 
+	apiURL := slack.APIURL
 	if config.SlackApiUrl != "" {
+		apiURL = config.SlackApiUrl
 		args = append(args, slack.OptionAPIURL(config.SlackApiUrl))
 	}
 
@@ -71,88 +253,187 @@ func NewSlackService(config *config.Config) (*SlackService, error) {
 
 	svc := &SlackService{
 		Config:          config,
-		Client:          slackClient,
+		client:          slackClient,
+		token:           config.SlackToken,
 		UserCache:       make(map[string]string),
+		RealNameCache:   make(map[string]string),
+		DeletedUsers:    make(map[string]bool),
 		PersistentCache: persistentCache,
 		ThreadCache:     make(map[string]string),
 		RateLimiter:     rateLimiter,
+		Marks:           make(map[string]string),
+		ChannelOrder:    make(map[string]int),
+		Mutes:           make(map[string]bool),
+		Stars:           make(map[string]bool),
+		members:         make(map[string]channelMembers),
+		posting:         make(map[string]PostingPolicy),
+		TeamNames:       make(map[string]string),
+		httpClient:      httpClient,
+		apiURL:          apiURL,
+		clientOptions:   args,
+		stopRotation:    make(chan struct{}),
+	}
+
+	// With token rotation, the access token of a previous session is used
+	// or a new one is requested
+	if svc.rotatesTokens() {
+		if err := svc.loadTokens(); err != nil {
+			svc.Close()
+			return nil, &AuthError{Err: err}
+		}
 	}
 
 	// Get user associated with token, mainly
 	// used to identify user when new messages
 	// arrives
-	authTest, err := svc.Client.AuthTest()
+	authTest, err := svc.Client().AuthTest()
 	if err != nil {
-		return nil, errors.New("not able to authorize client, check your connection and if your slack-token is set correctly")
+		svc.Close()
+		return nil, &AuthError{Err: err}
 	}
 	svc.CurrentUserID = authTest.UserID
+	svc.CurrentTeamID = authTest.TeamID
 
-	// Create RTM
-	svc.RTM = svc.Client.NewRTM()
-	go svc.RTM.ManageConnection()
+	// The tokens are only rotated once the access token has been accepted,
+	// otherwise the token is entered again and another service is created
+	if svc.rotatesTokens() {
+		go svc.rotateTokens()
+	}
+	svc.setTeamName(authTest.TeamID, authTest.Team)
 
-	// Creation of user cache this speeds up
-	// the uncovering of usernames of messages
-	// Note: Disabled bulk user fetch to avoid rate limits
-	// Users are now fetched on-demand and cached persistently
-	// if !config.IsEnterprise {
-	// 	users, _ := svc.Client.GetUsers()
-	// 	for _, user := range users {
-	// 		if !user.Deleted {
-	// 			svc.UserCache[user.ID] = user.Name
-	// 		}
-	// 	}
-	// }
+	// The channels that are muted in slack are shown as muted as well
+	if mutes, err := svc.getSlackMutes(context.Background()); err == nil {
+		svc.SetSlackMutes(mutes)
+	}
 
-	// Get name of current user, and set presence to active
-	currentUsername, err := svc.GetUserName(svc.CurrentUserID)
-	if err != nil {
-		svc.CurrentUsername = "slack-term"
+	// Starred channels are shown at the top of the channels
+	if stars, err := svc.getStarredChannels(context.Background()); err == nil {
+		svc.prefsMu.Lock()
+		svc.Stars = stars
+		svc.prefsMu.Unlock()
+	}
+
+	// Load the channel marks of previous sessions
+	if svc.PersistentCache != nil {
+		svc.prefsMu.Lock()
+		marks, err := svc.PersistentCache.GetMarks(svc.CurrentTeamID)
+		if err == nil {
+			svc.Marks = marks
+		}
+
+		order, err := svc.PersistentCache.GetChannelOrder(svc.CurrentTeamID)
+		if err == nil {
+			svc.ChannelOrder = order
+		}
+
+		mutes, err := svc.PersistentCache.GetMutes(svc.CurrentTeamID)
+		if err == nil {
+			svc.Mutes = mutes
+		}
+		svc.prefsMu.Unlock()
+
+		emoji, err := svc.PersistentCache.GetEmoji(svc.CurrentTeamID)
+		if err == nil {
+			addEmojiAliases(emoji)
+			svc.CustomEmoji = getCustomEmojiNames(emoji)
+		}
+
+		usage, err := svc.PersistentCache.GetEmojiUsage(svc.CurrentTeamID)
+		if err == nil {
+			svc.emojiUsage = usage
+		}
 	}
-	svc.CurrentUsername = currentUsername
-	svc.SetUserAsActive()
 
 	return svc, nil
 }
 
-func (s *SlackService) GetUserName(userID string) (string, error) {
+func (s *SlackService) GetUserName(ctx context.Context, userID string) (string, error) {
 	// Check memory cache first
-	if user, ok := s.UserCache[userID]; ok {
-		return user, nil
+	s.usersMu.RLock()
+	name, ok := s.UserCache[userID]
+	s.usersMu.RUnlock()
+	if ok {
+		return name, nil
 	}
 
 	// Check persistent cache
 	if s.PersistentCache != nil {
 		if user, ok := s.PersistentCache.Get(userID); ok {
-			s.UserCache[userID] = user
+			realName, _ := s.PersistentCache.GetRealName(userID)
+			deleted, _ := s.PersistentCache.IsDeleted(userID)
+			s.cacheUser(userID, user, realName, deleted)
 			return user, nil
 		}
 	}
 
+	// The user isn't cached as unknown when the lookup is cancelled, e.g.
+	// because another channel has been selected
+	placeholderName := fmt.Sprintf("unknown (%s)", userID)
+
 	// Rate limit API call
 	if s.RateLimiter != nil {
-		s.RateLimiter.Wait()
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return placeholderName, err
+		}
 	}
 
-	user, err := s.Client.GetUserInfo(userID)
+	user, err := s.Client().GetUserInfoContext(ctx, userID)
+	if ctx.Err() != nil {
+		return placeholderName, ctx.Err()
+	}
 	if err == nil {
-		s.UserCache[user.ID] = user.Name
+		s.cacheUser(user.ID, user.Name, user.RealName, user.Deleted)
 		if s.PersistentCache != nil {
-			s.PersistentCache.Set(user.ID, user.Name)
+			s.PersistentCache.Set(user.ID, user.Name, user.RealName, user.Deleted)
 		}
 		return user.Name, nil
 	}
 
 	// If error, return user ID
-	placeholderName := fmt.Sprintf("unknown (%s)", userID)
+	s.usersMu.Lock()
 	s.UserCache[userID] = placeholderName
+	s.usersMu.Unlock()
 	return placeholderName, err
 }
 
-func (s *SlackService) GetConversationsForUser() ([]components.ChannelItem, error) {
+// cacheUser will keep the name of a user in memory, the real name is only
+// kept when it's known
+func (s *SlackService) cacheUser(userID string, name string, realName string, deleted bool) {
+	s.usersMu.Lock()
+	defer s.usersMu.Unlock()
+
+	s.UserCache[userID] = name
+	if realName != "" {
+		s.RealNameCache[userID] = realName
+	}
+	if deleted {
+		s.DeletedUsers[userID] = true
+	}
+}
+
+// getRealName returns the real name of a user that has been looked up
+func (s *SlackService) getRealName(userID string) string {
+	s.usersMu.RLock()
+	defer s.usersMu.RUnlock()
+
+	return s.RealNameCache[userID]
+}
+
+// isDeletedUser returns whether a user that has been looked up has been
+// deactivated
+func (s *SlackService) isDeletedUser(userID string) bool {
+	s.usersMu.RLock()
+	defer s.usersMu.RUnlock()
+
+	return s.DeletedUsers[userID]
+}
+
+func (s *SlackService) GetConversationsForUser(ctx context.Context) ([]components.ChannelItem, error) {
 	// Rate limit
 	if s.RateLimiter != nil {
-		s.RateLimiter.Wait()
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return nil, err
+		}
 	}
 
 	slackChans := make([]slack.Channel, 0)
@@ -163,7 +444,8 @@ func (s *SlackService) GetConversationsForUser() ([]components.ChannelItem, erro
 		"mpim",
 	}
 
-	slackChans, _, err := s.Client.GetConversationsForUser(
+	slackChans, _, err := s.Client().GetConversationsForUserContext(
+		ctx,
 		&slack.GetConversationsForUserParameters{
 		Limit:           1000,
 		Types:           convTypes,
@@ -173,53 +455,120 @@ func (s *SlackService) GetConversationsForUser() ([]components.ChannelItem, erro
 	}
 
 	var chans []components.ChannelItem
-	s.Conversations, chans = s.getSortedChannels(slackChans, false)
+	s.Conversations, chans = s.getSortedChannels(ctx, slackChans, false)
 	return chans, nil
 }
 
-// Note: includePublic=true will be SLOW for organizations with a large number of public channels!
-func (s *SlackService) GetChannels(includePublic bool) ([]components.ChannelItem, error) {
+// GetChannels will get all the conversations the user is a member of. Public
+// channels the user isn't a member of can be paged through with
+// GetPublicChannels.
+func (s *SlackService) GetChannels(ctx context.Context) ([]components.ChannelItem, error) {
 	slackChans := make([]slack.Channel, 0)
-	convTypes := []string{
-		"private_channel",
-		"im",
-		"mpim",
+
+	// Paginate over all the conversations
+	cursor := ""
+	for {
+		channels, nextCursor, err := s.getConversationsPage(ctx, cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		slackChans = append(slackChans, channels...)
+
+		if nextCursor == "" {
+			break
+		}
+		cursor = nextCursor
 	}
 
-	if includePublic {
-		convTypes = append([]string{"public_channel"}, convTypes...)
+	// Return sorted conversations
+	var chans []components.ChannelItem
+	s.Conversations, chans = s.getSortedChannels(ctx, slackChans, false)
+	return chans, nil
+}
+
+// GetChannelsPage will get a single page of the conversations the user is a
+// member of, starting at cursor. It returns the channels and the cursor of
+// the next page, which is empty when there are no more pages. The first page
+// is fetched with an empty cursor.
+func (s *SlackService) GetChannelsPage(ctx context.Context, cursor string) ([]components.ChannelItem, string, error) {
+	channels, nextCursor, err := s.getConversationsPage(ctx, cursor)
+	if err != nil {
+		return nil, "", err
 	}
 
-	// Initial request
-	params := &slack.GetConversationsParameters{
-		ExcludeArchived: "true",
-		Limit:           1000,
-		Types:           convTypes,
+	slackChans, chans := s.getSortedChannels(ctx, channels, false)
+	if cursor == "" {
+		s.Conversations = slackChans
+	} else {
+		s.Conversations = append(s.Conversations, slackChans...)
 	}
-	initChans, initCur, err := s.Client.GetConversations(params)
-	if err != nil {
-		return nil, err
+
+	return chans, nextCursor, nil
+}
+
+// getConversationsPage will get a single page of the conversations the user
+// is a member of
+func (s *SlackService) getConversationsPage(ctx context.Context, cursor string) ([]slack.Channel, string, error) {
+	// Rate limit
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return nil, "", err
+		}
 	}
 
-	slackChans = append(slackChans, initChans...)
+	return s.Client().GetConversationsForUserContext(
+		ctx,
+		&slack.GetConversationsForUserParameters{
+			Cursor:          cursor,
+			ExcludeArchived: true,
+			Limit:           channelsPageSize,
+			Types: []string{
+				"public_channel",
+				"private_channel",
+				"im",
+				"mpim",
+			},
+		},
+	)
+}
 
-	// Paginate over additional channels
-	nextCur := initCur
-	for nextCur != "" {
-		params.Cursor = nextCur
-		channels, cursor, err := s.Client.GetConversations(params)
-		if err != nil {
-			return nil, err
+// GetPublicChannels will get a single page of the public channels the user
+// isn't a member of, starting at cursor. It returns the channels and the
+// cursor of the next page, which is empty when there are no more pages.
+func (s *SlackService) GetPublicChannels(ctx context.Context, cursor string) ([]components.ChannelItem, string, error) {
+	// Rate limit
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return nil, "", err
 		}
+	}
 
-		slackChans = append(slackChans, channels...)
-		nextCur = cursor
+	channels, nextCursor, err := s.Client().GetConversationsContext(
+		ctx,
+		&slack.GetConversationsParameters{
+			Cursor:          cursor,
+			ExcludeArchived: "true",
+			Limit:           200,
+			Types:           []string{"public_channel"},
+		},
+	)
+	if err != nil {
+		return nil, "", err
 	}
 
-	// Return sorted conversations 
-	var chans []components.ChannelItem
-	s.Conversations, chans = s.getSortedChannels(slackChans, true)
-	return chans, nil
+	chans := make([]components.ChannelItem, 0)
+	for _, chn := range channels {
+		if chn.IsMember {
+			continue
+		}
+
+		chanItem := s.createChannelItem(chn)
+		chanItem.Type = components.ChannelTypeChannel
+		chans = append(chans, chanItem)
+	}
+
+	return chans, nextCursor, nil
 }
 
 // We're creating tempChan, because we want to be able to
@@ -234,6 +583,7 @@ type bucket map[string]*tempChan
 func makeBuckets() map[int]bucket  {
 	// Initialize buckets
 	buckets := make(map[int]bucket)
+	buckets[-1] = make(bucket) // Starred
 	buckets[0] = make(bucket) // Channels
 	buckets[1] = make(bucket) // Group
 	buckets[2] = make(bucket) // MpIM
@@ -241,7 +591,7 @@ func makeBuckets() map[int]bucket  {
 	return buckets
 }
 
-func (s *SlackService) sortIntoBuckets(buckets map[int]bucket, chn slack.Channel, keepOnlyIsMember bool) {
+func (s *SlackService) sortIntoBuckets(ctx context.Context, buckets map[int]bucket, chn slack.Channel, keepOnlyIsMember bool) {
 	chanItem := s.createChannelItem(chn)
 	if chn.IsChannel {
 		if keepOnlyIsMember && !chn.IsMember {
@@ -271,6 +621,7 @@ func (s *SlackService) sortIntoBuckets(buckets map[int]bucket, chn slack.Channel
 				return
 			}
 
+			chanItem.Name = s.getMpIMName(ctx, chn)
 			chanItem.Type = components.ChannelTypeMpIM
 
 			if chn.UnreadCount > 0 {
@@ -301,12 +652,14 @@ func (s *SlackService) sortIntoBuckets(buckets map[int]bucket, chn slack.Channel
 	if chn.IsIM {
 		// Check if user is deleted, we do this by checking the user id,
 		// and see if we have the user in the UserCache
-		name, err := s.GetUserName(chn.User)
+		name, err := s.GetUserName(ctx, chn.User)
 		if err != nil {
 			return
 		}
 
 		chanItem.Name = name
+		chanItem.RealName = s.getRealName(chn.User)
+		chanItem.Deactivated = s.isDeletedUser(chn.User)
 		chanItem.Type = components.ChannelTypeIM
 		chanItem.Presence = "away"
 
@@ -322,12 +675,26 @@ func (s *SlackService) sortIntoBuckets(buckets map[int]bucket, chn slack.Channel
 }
 
 // GetConversationsForUser will omit IsMember since it's implied the user belongs to those conversations
-func (s *SlackService) getSortedChannels(slackChans[] slack.Channel, keepOnlyIsMember bool) ([]slack.Channel, []components.ChannelItem) {
+func (s *SlackService) getSortedChannels(ctx context.Context, slackChans[] slack.Channel, keepOnlyIsMember bool) ([]slack.Channel, []components.ChannelItem) {
 	buckets := makeBuckets()
 
 	var wg sync.WaitGroup
 	for _, chn := range slackChans {
-		s.sortIntoBuckets(buckets, chn, keepOnlyIsMember )
+		s.sortIntoBuckets(ctx, buckets, chn, keepOnlyIsMember )
+	}
+
+	// Starred channels of every type are moved to a bucket of their own,
+	// which is shown first
+	for k, bucket := range buckets {
+		if k < 0 {
+			continue
+		}
+		for key, v := range bucket {
+			if v.channelItem.Starred {
+				buckets[-1][key] = v
+				delete(bucket, key)
+			}
+		}
 	}
 
 	wg.Wait()
@@ -347,11 +714,14 @@ func (s *SlackService) getSortedChannels(slackChans[] slack.Channel, keepOnlyIsM
 		// Sort channels in every bucket
 		tcArr := make([]tempChan, 0)
 		for _, v := range bucket {
+			v.channelItem.Position = s.getChannelPosition(v.channelItem)
+			v.channelItem.Muted = s.IsMuted(v.channelItem)
+			s.setChannelStyle(&v.channelItem)
 			tcArr = append(tcArr, *v)
 		}
 
 		sort.Slice(tcArr, func(i, j int) bool {
-			return tcArr[i].channelItem.Name < tcArr[j].channelItem.Name
+			return tcArr[i].channelItem.Before(tcArr[j].channelItem)
 		})
 
 		// Add ChannelItem and SlackChannel to the SlackService struct
@@ -365,9 +735,372 @@ func (s *SlackService) getSortedChannels(slackChans[] slack.Channel, keepOnlyIsM
 }
 
 
+// getChannelPosition returns the custom position of a channel, which is
+// either set at runtime with SetChannelOrder, or by channel name in the
+// config
+func (s *SlackService) getChannelPosition(channel components.ChannelItem) int {
+	s.prefsMu.RLock()
+	position, ok := s.ChannelOrder[channel.ID]
+	s.prefsMu.RUnlock()
+	if ok {
+		return position
+	}
+
+	for i, name := range s.Config.ChannelOrder {
+		if name == channel.Name || name == channel.ID {
+			return i + 1
+		}
+	}
+
+	return 0
+}
+
+// setChannelStyle will override the style of a channel with the one that
+// is set in the theme by its name, e.g. "#alerts", or its id
+func (s *SlackService) setChannelStyle(channel *components.ChannelItem) {
+	for name, style := range s.Config.Theme.Channels {
+		if strings.TrimLeft(name, "#@") != channel.Name && name != channel.ID {
+			continue
+		}
+
+		if style.Text != "" {
+			channel.StyleText = style.Text
+		}
+		channel.StyleHeader = style.Header
+		channel.StyleBorder = style.Border
+		return
+	}
+}
+
+// SetChannelOrder will set the custom positions of channels in the order
+// of channelIDs, the positions are persisted so that they are available
+// across sessions
+func (s *SlackService) SetChannelOrder(channelIDs []string) error {
+	order := make(map[string]int)
+	s.prefsMu.Lock()
+	for i, channelID := range channelIDs {
+		order[channelID] = i + 1
+		s.ChannelOrder[channelID] = i + 1
+	}
+	s.prefsMu.Unlock()
+
+	if s.PersistentCache != nil {
+		return s.PersistentCache.SetChannelOrder(s.CurrentTeamID, order)
+	}
+
+	return nil
+}
+
+// SetMute will set whether a channel is muted, this is independent of the
+// notification preferences in slack, a channel that is muted in slack stays
+// muted. It's persisted so that it is available across sessions.
+func (s *SlackService) SetMute(channelID string, muted bool) error {
+	s.prefsMu.Lock()
+	if muted {
+		s.Mutes[channelID] = true
+	} else {
+		delete(s.Mutes, channelID)
+	}
+	s.prefsMu.Unlock()
+
+	if s.PersistentCache != nil {
+		return s.PersistentCache.SetMute(s.CurrentTeamID, channelID, muted)
+	}
+
+	return nil
+}
+
+// SetMark will let the mark point to the channel with channelID, the mark
+// is persisted so that it is available across sessions
+func (s *SlackService) SetMark(mark string, channelID string) error {
+	s.prefsMu.Lock()
+	s.Marks[mark] = channelID
+	s.prefsMu.Unlock()
+
+	if s.PersistentCache != nil {
+		return s.PersistentCache.SetMark(s.CurrentTeamID, mark, channelID)
+	}
+
+	return nil
+}
+
+// GetMark will return the channel id that the mark points to
+func (s *SlackService) GetMark(mark string) (string, bool) {
+	s.prefsMu.RLock()
+	defer s.prefsMu.RUnlock()
+
+	channelID, ok := s.Marks[mark]
+	return channelID, ok
+}
+
+// JoinChannel will join the public channel with channelID, and returns
+// the ChannelItem of the joined channel
+func (s *SlackService) JoinChannel(ctx context.Context, channelID string) (components.ChannelItem, error) {
+	chn, _, _, err := s.Client().JoinConversationContext(ctx, channelID)
+	if err != nil {
+		return components.ChannelItem{}, err
+	}
+
+	s.Conversations = append(s.Conversations, *chn)
+
+	chanItem := s.createChannelItem(*chn)
+	chanItem.Type = components.ChannelTypeChannel
+	s.setChannelStyle(&chanItem)
+
+	return chanItem, nil
+}
+
+// CreateChannel will create a public channel with name, of which the
+// current user is a member, and returns the ChannelItem of the channel
+func (s *SlackService) CreateChannel(ctx context.Context, name string) (components.ChannelItem, error) {
+	// Rate limit
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return components.ChannelItem{}, err
+		}
+	}
+
+	chn, err := s.Client().CreateConversationContext(ctx, name, false)
+	if err != nil {
+		return components.ChannelItem{}, err
+	}
+
+	s.Conversations = append(s.Conversations, *chn)
+
+	chanItem := s.createChannelItem(*chn)
+	chanItem.Type = components.ChannelTypeChannel
+	s.setChannelStyle(&chanItem)
+
+	return chanItem, nil
+}
+
+// LeaveChannel will leave the channel with channelID
+func (s *SlackService) LeaveChannel(ctx context.Context, channelID string) error {
+	// Rate limit
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	_, err := s.Client().LeaveConversationContext(ctx, channelID)
+	return err
+}
+
+// ArchiveChannel will archive the channel with channelID
+func (s *SlackService) ArchiveChannel(ctx context.Context, channelID string) error {
+	// Rate limit
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	return s.Client().ArchiveConversationContext(ctx, channelID)
+}
+
+// OpenDirectMessage will open the direct message with the user with userID,
+// it's created when there hasn't been one yet. It returns the ChannelItem
+// of the direct message.
+func (s *SlackService) OpenDirectMessage(ctx context.Context, userID string) (components.ChannelItem, error) {
+	// Rate limit
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return components.ChannelItem{}, err
+		}
+	}
+
+	chn, _, _, err := s.Client().OpenConversationContext(
+		ctx,
+		&slack.OpenConversationParameters{
+			Users:    []string{userID},
+			ReturnIM: true,
+		},
+	)
+	if err != nil {
+		return components.ChannelItem{}, err
+	}
+
+	name, err := s.GetUserName(ctx, userID)
+	if err != nil {
+		return components.ChannelItem{}, err
+	}
+
+	s.Conversations = append(s.Conversations, *chn)
+
+	chanItem := s.createChannelItem(*chn)
+	chanItem.Name = name
+	chanItem.RealName = s.getRealName(userID)
+	chanItem.UserID = userID
+	chanItem.Type = components.ChannelTypeIM
+	chanItem.Presence = "away"
+	s.setChannelStyle(&chanItem)
+
+	return chanItem, nil
+}
+
+// GetChannelTeams will get the names of the workspaces a channel belongs to,
+// which is mainly of interest in an Enterprise Grid organization, and the
+// names of the external organizations a channel is shared with.
+//
+// https://api.slack.com/methods/conversations.info
+// https://api.slack.com/methods/team.info
+func (s *SlackService) GetChannelTeams(ctx context.Context, channelID string) ([]string, []string, error) {
+	var info struct {
+		slack.SlackResponse
+		Channel struct {
+			ContextTeamID    string   `json:"context_team_id"`
+			InternalTeamIDs  []string `json:"internal_team_ids"`
+			ConnectedTeamIDs []string `json:"connected_team_ids"`
+			SharedTeamIDs    []string `json:"shared_team_ids"`
+			IsExtShared      bool     `json:"is_ext_shared"`
+		} `json:"channel"`
+	}
+
+	err := s.callAPI(
+		ctx, "conversations.info", url.Values{"channel": {channelID}}, &info,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := info.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	workspaceIDs := info.Channel.InternalTeamIDs
+	if len(workspaceIDs) == 0 && info.Channel.ContextTeamID != "" {
+		workspaceIDs = []string{info.Channel.ContextTeamID}
+	}
+
+	externalIDs := info.Channel.ConnectedTeamIDs
+	if len(externalIDs) == 0 && info.Channel.IsExtShared {
+		externalIDs = info.Channel.SharedTeamIDs
+	}
+
+	workspaces := make([]string, 0)
+	for _, teamID := range workspaceIDs {
+		workspaces = append(workspaces, s.getTeamNameOrID(ctx, teamID))
+	}
+
+	external := make([]string, 0)
+	for _, teamID := range externalIDs {
+		if teamID == s.CurrentTeamID {
+			continue
+		}
+		external = append(external, s.getTeamNameOrID(ctx, teamID))
+	}
+
+	return workspaces, external, nil
+}
+
+func (s *SlackService) getTeamNameOrID(ctx context.Context, teamID string) string {
+	name, err := s.GetTeamName(ctx, teamID)
+	if err != nil {
+		return teamID
+	}
+	return name
+}
+
+// GetTeamName will get the name of a team (workspace or organization)
+func (s *SlackService) GetTeamName(ctx context.Context, teamID string) (string, error) {
+	s.teamsMu.Lock()
+	name, ok := s.TeamNames[teamID]
+	s.teamsMu.Unlock()
+	if ok {
+		return name, nil
+	}
+
+	var info struct {
+		slack.SlackResponse
+		Team slack.TeamInfo `json:"team"`
+	}
+
+	err := s.callAPI(ctx, "team.info", url.Values{"team": {teamID}}, &info)
+	if err != nil {
+		return "", err
+	}
+	if err := info.Err(); err != nil {
+		return "", err
+	}
+
+	s.setTeamName(teamID, info.Team.Name)
+	return info.Team.Name, nil
+}
+
+// setTeamName will keep the name of a team that has been looked up
+func (s *SlackService) setTeamName(teamID string, name string) {
+	s.teamsMu.Lock()
+	defer s.teamsMu.Unlock()
+
+	s.TeamNames[teamID] = name
+}
+
+// GetUnreadCount will get the number of unread messages of a conversation,
+// this isn't returned when listing the conversations of a user
+func (s *SlackService) GetUnreadCount(ctx context.Context, channelID string) (int, error) {
+	// Rate limit
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	chn, err := s.Client().GetConversationInfoContext(ctx, channelID, false)
+	if err != nil {
+		return 0, err
+	}
+
+	return chn.UnreadCountDisplay, nil
+}
+
+// UnreadCount is the result of fetching the unread count of a conversation
+// with GetUnreadCounts
+type UnreadCount struct {
+	ChannelID string
+	Count     int
+	Err       error
+}
+
+// GetUnreadCounts will get the unread counts of the conversations with a
+// bounded number of workers. Every result is sent on the returned channel as
+// soon as it is fetched, the channel is closed when all the unread counts
+// have been fetched.
+func (s *SlackService) GetUnreadCounts(ctx context.Context, channelIDs []string, workers int) <-chan UnreadCount {
+	jobs := make(chan string)
+	results := make(chan UnreadCount)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for channelID := range jobs {
+				count, err := s.GetUnreadCount(ctx, channelID)
+				results <- UnreadCount{ChannelID: channelID, Count: count, Err: err}
+			}
+		}()
+	}
+
+	go func() {
+	loop:
+		for _, channelID := range channelIDs {
+			select {
+			case jobs <- channelID:
+			case <-ctx.Done():
+				break loop
+			}
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
 // GetUserPresence will get the presence of a specific user
-func (s *SlackService) GetUserPresence(userID string) (string, error) {
-	presence, err := s.Client.GetUserPresence(userID)
+func (s *SlackService) GetUserPresence(ctx context.Context, userID string) (string, error) {
+	presence, err := s.Client().GetUserPresenceContext(ctx, userID)
 	if err != nil {
 		return "", err
 	}
@@ -375,39 +1108,46 @@ func (s *SlackService) GetUserPresence(userID string) (string, error) {
 	return presence.Presence, nil
 }
 
-// Set current user presence to active
-func (s *SlackService) SetUserAsActive() {
-	s.Client.SetUserPresence("auto")
+// SetUserPresence will set the presence of the current user to either
+// "auto" or "away"
+func (s *SlackService) SetUserPresence(ctx context.Context, presence string) error {
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	return s.Client().SetUserPresenceContext(ctx, presence)
 }
 
 // MarkAsRead will set the channel as read
-func (s *SlackService) MarkAsRead(channelItem components.ChannelItem) {
+func (s *SlackService) MarkAsRead(ctx context.Context, channelItem components.ChannelItem) {
 	switch channelItem.Type {
 	case components.ChannelTypeChannel:
-		s.Client.SetChannelReadMark(
-			channelItem.ID, fmt.Sprintf("%f",
+		s.Client().SetChannelReadMarkContext(
+			ctx, channelItem.ID, fmt.Sprintf("%f",
 				float64(time.Now().Unix())),
 		)
 	case components.ChannelTypeGroup:
-		s.Client.SetGroupReadMark(
-			channelItem.ID, fmt.Sprintf("%f",
+		s.Client().SetGroupReadMarkContext(
+			ctx, channelItem.ID, fmt.Sprintf("%f",
 				float64(time.Now().Unix())),
 		)
 	case components.ChannelTypeMpIM:
-		s.Client.MarkIMChannel(
-			channelItem.ID, fmt.Sprintf("%f",
+		s.Client().MarkIMChannelContext(
+			ctx, channelItem.ID, fmt.Sprintf("%f",
 				float64(time.Now().Unix())),
 		)
 	case components.ChannelTypeIM:
-		s.Client.MarkIMChannel(
-			channelItem.ID, fmt.Sprintf("%f",
+		s.Client().MarkIMChannelContext(
+			ctx, channelItem.ID, fmt.Sprintf("%f",
 				float64(time.Now().Unix())),
 		)
 	}
 }
 
 // SendMessage will send a message to a particular channel
-func (s *SlackService) SendMessage(channelID string, message string) error {
+func (s *SlackService) SendMessage(ctx context.Context, channelID string, message string) error {
 
 	// https://godoc.org/github.com/nlopes/slack#PostMessageParameters
 	postParams := slack.MsgOptionPostMessageParameters(slack.PostMessageParameters{
@@ -416,21 +1156,39 @@ func (s *SlackService) SendMessage(channelID string, message string) error {
 		LinkNames: 1,
 	})
 
-	text := slack.MsgOptionText(message, true)
+	text := slack.MsgOptionText(s.encodeMessage(ctx, message), false)
 
 	// https://godoc.org/github.com/nlopes/slack#Client.PostMessage
-	_, _, err := s.Client.PostMessage(channelID, text, postParams)
+	_, _, err := s.Client().PostMessageContext(ctx, channelID, text, postParams)
 	if err != nil {
 		return err
 	}
 
+	s.useEmoji(s.getMessageEmoji(message))
+
 	return nil
 }
 
+// EditMessage will replace the text of a message, see:
+// https://api.slack.com/methods/chat.update
+func (s *SlackService) EditMessage(ctx context.Context, channelID string, messageID string, message string) error {
+	text := slack.MsgOptionText(s.encodeMessage(ctx, message), false)
+
+	_, _, _, err := s.Client().UpdateMessageContext(ctx, channelID, messageID, text)
+	return err
+}
+
+// DeleteMessage will delete a message, see:
+// https://api.slack.com/methods/chat.delete
+func (s *SlackService) DeleteMessage(ctx context.Context, channelID string, messageID string) error {
+	_, _, err := s.Client().DeleteMessageContext(ctx, channelID, messageID)
+	return err
+}
+
 // SendReply will send a message to a particular thread, specifying the
 // ThreadTimestamp will make it reply to that specific thread. (see:
 // https://api.slack.com/docs/message-threading, 'Posting replies')
-func (s *SlackService) SendReply(channelID string, threadID string, message string) error {
+func (s *SlackService) SendReply(ctx context.Context, channelID string, threadID string, message string) error {
 	// https://godoc.org/github.com/nlopes/slack#PostMessageParameters
 	postParams := slack.MsgOptionPostMessageParameters(slack.PostMessageParameters{
 		AsUser:          true,
@@ -439,14 +1197,16 @@ func (s *SlackService) SendReply(channelID string, threadID string, message stri
 		ThreadTimestamp: threadID,
 	})
 
-	text := slack.MsgOptionText(message, true)
+	text := slack.MsgOptionText(s.encodeMessage(ctx, message), false)
 
 	// https://godoc.org/github.com/nlopes/slack#Client.PostMessage
-	_, _, err := s.Client.PostMessage(channelID, text, postParams)
+	_, _, err := s.Client().PostMessageContext(ctx, channelID, text, postParams)
 	if err != nil {
 		return err
 	}
 
+	s.useEmoji(s.getMessageEmoji(message))
+
 	return nil
 }
 
@@ -459,7 +1219,7 @@ func (s *SlackService) SendReply(channelID string, threadID string, message stri
 // correct api endpoint.
 //
 // https://github.com/ErikKalkoken/slackApiDoc/blob/master/chat.command.md
-func (s *SlackService) SendCommand(channelID string, message string) (bool, error) {
+func (s *SlackService) SendCommand(ctx context.Context, channelID string, message string) (bool, error) {
 	// First check if it begins with slash and a command
 	r, err := regexp.Compile(`^/\w+`)
 	if err != nil {
@@ -481,16 +1241,24 @@ func (s *SlackService) SendCommand(channelID string, message string) (bool, erro
 			return false, errors.New("'/thread' command malformed")
 		}
 
+		s.threadsMu.Lock()
 		threadID := s.ThreadCache[subMatch[2]]
+		s.threadsMu.Unlock()
 		msg := subMatch[3]
 
-		err := s.SendReply(channelID, threadID, msg)
+		err := s.SendReply(ctx, channelID, threadID, msg)
 		if err != nil {
 			return false, err
 		}
 
 		return true, nil
 	default:
+		// Typos are reported instead of being sent, since slack doesn't
+		// respond to unknown commands
+		if err := s.validateCommand(ctx, r.FindString(message)); err != nil {
+			return false, err
+		}
+
 		r := regexp.MustCompile(`(?P<cmd>^/\w+) (?P<text>.*)`)
 		subMatch := r.FindStringSubmatch(message)
 
@@ -509,59 +1277,214 @@ func (s *SlackService) SendCommand(channelID string, message string) (bool, erro
 			},
 		)
 
-		_, _, err := s.Client.PostMessage(channelID, msgOption)
+		_, _, err := s.Client().PostMessageContext(ctx, channelID, msgOption)
 		if err != nil {
 			return false, err
 		}
 
 		return true, nil
 	}
+}
 
-	return false, nil
+// PreviewMessage will create the message that is shown once message is
+// sent to a channel, without sending it. It's encoded like a message that
+// is sent, and rendered like a message that is received.
+func (s *SlackService) PreviewMessage(ctx context.Context, channelID string, message string) components.Message {
+	return s.CreateMessage(
+		ctx,
+		slack.Message{
+			Msg: slack.Msg{
+				User:      s.CurrentUserID,
+				Text:      s.encodeMessage(ctx, message),
+				Timestamp: fmt.Sprintf("%d", time.Now().Unix()),
+			},
+		},
+		channelID,
+	)
 }
 
 // GetMessages will get messages for a channel, group or im channel delimited
 // by a count. It will return the messages, the thread identifiers
-// (as ChannelItem), and and error.
+// (as ChannelItem), and and error. The replies of the threads aren't
+// fetched, use LoadReplies for that.
 // By default, only fetches messages from the last {daysToFetch} days to reduce API load.
-func (s *SlackService) GetMessages(channelID string, count int, daysToFetch int) ([]components.Message, []components.ChannelItem, error) {
-	// Rate limit
-	if s.RateLimiter != nil {
-		s.RateLimiter.Wait()
+func (s *SlackService) GetMessages(ctx context.Context, channelID string, count int, daysToFetch int) ([]components.Message, []components.ChannelItem, error) {
+	history, err := s.getHistory(ctx, channelID, count, daysToFetch)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	messages, threads := s.createMessages(ctx, history, channelID)
+	return messages, threads, nil
+}
+
+// GetMissedMessages will get the messages of a channel that have been posted
+// after oldest and before latest, the newest first up to count. It returns
+// whether more messages have been posted in between. They aren't stored in
+// the persistent cache.
+func (s *SlackService) GetMissedMessages(ctx context.Context, channelID string, oldest string, latest string, count int) ([]components.Message, bool, error) {
+	// https://api.slack.com/methods/conversations.history
+	values := url.Values{
+		"channel":   {channelID},
+		"limit":     {strconv.Itoa(count)},
+		"inclusive": {"0"},
+		"oldest":    {oldest},
+		"latest":    {latest},
+	}
+
+	var history historyResponse
+	if err := s.callAPI(ctx, "conversations.history", values, &history); err != nil {
+		return nil, false, err
+	}
+	if err := history.Err(); err != nil {
+		return nil, false, err
+	}
+
+	messages, _ := s.createMessages(ctx, history.Messages, channelID)
+	return messages, history.HasMore, nil
+}
+
+// GetCachedMessages will construct the messages and threads of a channel
+// from the history that is stored in the persistent cache, it returns false
+// when there is no history stored for the channel.
+func (s *SlackService) GetCachedMessages(ctx context.Context, channelID string) ([]components.Message, []components.ChannelItem, bool) {
+	if s.PersistentCache == nil {
+		return nil, nil, false
+	}
+
+	data, _, ok := s.PersistentCache.GetHistory(channelID)
+	if !ok {
+		return nil, nil, false
+	}
+
+	var history []historyMessage
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, nil, false
+	}
+
+	messages, threads := s.createMessages(ctx, history, channelID)
+	return messages, threads, true
+}
+
+// PrefetchMessages will fetch the history of a channel and store it in the
+// persistent cache, so it can be shown without delay when the channel is
+// selected. Nothing is fetched when the stored history is recent enough.
+func (s *SlackService) PrefetchMessages(ctx context.Context, channelID string, count int, daysToFetch int) error {
+	if s.PersistentCache == nil {
+		return nil
 	}
 
+	_, updatedAt, ok := s.PersistentCache.GetHistory(channelID)
+	if ok && time.Since(updatedAt) < historyMaxAge {
+		return nil
+	}
+
+	_, err := s.getHistory(ctx, channelID, count, daysToFetch)
+	return err
+}
+
+// historyMessage is a message of the history of a channel, together with
+// the timestamp of the latest reply when it's the parent of a thread, and
+// the durations of its audio and video files in milliseconds keyed by file
+// id. The slack package doesn't decode them.
+type historyMessage struct {
+	slack.Message
+	LatestReply string           `json:"latest_reply,omitempty"`
+	Durations   map[string]int64 `json:"durations,omitempty"`
+}
+
+// UnmarshalJSON will decode the message, and the durations of its files
+func (m *historyMessage) UnmarshalJSON(data []byte) error {
+	type message historyMessage
+	if err := json.Unmarshal(data, (*message)(m)); err != nil {
+		return err
+	}
+
+	var files struct {
+		Files []mediaFile `json:"files"`
+	}
+	if err := json.Unmarshal(data, &files); err != nil {
+		return err
+	}
+
+	for _, file := range files.Files {
+		if file.DurationMS == 0 {
+			continue
+		}
+		if m.Durations == nil {
+			m.Durations = make(map[string]int64)
+		}
+		m.Durations[file.ID] = file.DurationMS
+	}
+
+	return nil
+}
+
+type historyResponse struct {
+	slack.SlackResponse
+	Messages []historyMessage `json:"messages"`
+	HasMore  bool             `json:"has_more"`
+}
+
+// getHistory will fetch the most recent messages of a channel, and store
+// them in the persistent cache
+func (s *SlackService) getHistory(ctx context.Context, channelID string, count int, daysToFetch int) ([]historyMessage, error) {
 	oldest := time.Now().AddDate(0, 0, -daysToFetch).Unix()
 
-	// https://godoc.org/github.com/nlopes/slack#GetConversationHistoryParameters
-	historyParams := slack.GetConversationHistoryParameters{
-		ChannelID: channelID,
-		Limit:     count,
-		Inclusive: false,
-		Oldest:    fmt.Sprintf("%d", oldest),
+	// https://api.slack.com/methods/conversations.history
+	values := url.Values{
+		"channel":   {channelID},
+		"limit":     {strconv.Itoa(count)},
+		"inclusive": {"0"},
+		"oldest":    {fmt.Sprintf("%d", oldest)},
 	}
 
-	history, err := s.Client.GetConversationHistory(&historyParams)
-	if err != nil {
-		return nil, nil, err
+	var history historyResponse
+	if err := s.callAPI(ctx, "conversations.history", values, &history); err != nil {
+		return nil, err
+	}
+	if err := history.Err(); err != nil {
+		return nil, err
+	}
+
+	if s.PersistentCache != nil {
+		if data, err := json.Marshal(history.Messages); err == nil {
+			s.PersistentCache.SetHistory(channelID, data)
+		}
 	}
 
+	return history.Messages, nil
+}
+
+// createMessages will construct the messages, with the newest in the last
+// place, and the thread items from the history of a channel
+func (s *SlackService) createMessages(ctx context.Context, history []historyMessage, channelID string) ([]components.Message, []components.ChannelItem) {
 	// Construct the messages
 	var messages []components.Message
 	var threads []components.ChannelItem
-	for _, message := range history.Messages {
-		msg := s.CreateMessage(message, channelID)
+	for _, message := range history {
+		msg := s.CreateMessage(ctx, message.Message, channelID)
+
+		// Audio and video files are shown with their duration
+		if len(message.Durations) > 0 {
+			for _, file := range s.createFileMessages(message.Files, message.Durations) {
+				msg.Messages[file.ID] = file
+			}
+		}
+
+		// The replies of threads are only fetched when the thread is
+		// opened, until then a summary of them is shown
+		if msg.Thread != "" && message.LatestReply != "" {
+			if ts, err := strconv.ParseFloat(message.LatestReply, 64); err == nil {
+				msg.LatestReply = time.Unix(int64(ts), 0)
+			}
+		}
+
 		messages = append(messages, msg)
 
 		// FIXME: create boolean isThread
 		if msg.Thread != "" {
-			threads = append(threads, components.ChannelItem{
-				ID:          msg.ID,
-				Name:        msg.Thread,
-				Type:        components.ChannelTypeGroup,
-				StylePrefix: s.Config.Theme.Channel.Prefix,
-				StyleIcon:   s.Config.Theme.Channel.Icon,
-				StyleText:   s.Config.Theme.Channel.Text,
-			})
+			threads = append(threads, s.CreateThreadItem(msg))
 		}
 	}
 
@@ -572,7 +1495,7 @@ func (s *SlackService) GetMessages(channelID string, count int, daysToFetch int)
 		messagesReversed = append(messagesReversed, messages[i])
 	}
 
-	return messagesReversed, threads, nil
+	return messagesReversed, threads
 }
 
 // CreateMessageByID will construct an array of components.Message with only
@@ -580,7 +1503,7 @@ func (s *SlackService) GetMessages(channelID string, count int, daysToFetch int)
 //
 // For the choice of history parameters see:
 // https://api.slack.com/messaging/retrieving
-func (s *SlackService) GetMessageByID(messageID string, channelID string) ([]components.Message, error) {
+func (s *SlackService) GetMessageByID(ctx context.Context, messageID string, channelID string) ([]components.Message, error) {
 
 	var msgs []components.Message
 
@@ -592,14 +1515,19 @@ func (s *SlackService) GetMessageByID(messageID string, channelID string) ([]com
 		Latest:    messageID,
 	}
 
-	history, err := s.Client.GetConversationHistory(&historyParams)
+	history, err := s.Client().GetConversationHistoryContext(ctx, &historyParams)
 	if err != nil {
 		return msgs, err
 	}
 
 	// We break because we're only asking for 1 message
 	for _, message := range history.Messages {
-		msgs = append(msgs, s.CreateMessage(message, channelID))
+		msg, err := s.LoadReplies(ctx, s.CreateMessage(ctx, message, channelID), channelID)
+		if err != nil {
+			return msgs, err
+		}
+
+		msgs = append(msgs, msg)
 		break
 	}
 
@@ -610,11 +1538,11 @@ func (s *SlackService) GetMessageByID(messageID string, channelID string) ([]com
 // in the Chat pane.
 //
 // [23:59] <erroneousboat> Hello world!
-func (s *SlackService) CreateMessage(message slack.Message, channelID string) components.Message {
+func (s *SlackService) CreateMessage(ctx context.Context, message slack.Message, channelID string) components.Message {
 	var name string
 
 	// Get username from cache
-	name, err := s.GetUserName(message.User)
+	name, err := s.GetUserName(ctx, message.User)
 
 	if err != nil && name == "" {
 		if message.BotID != "" {
@@ -637,12 +1565,33 @@ func (s *SlackService) CreateMessage(message slack.Message, channelID string) co
 		Messages:    make(map[string]components.Message),
 		Time:        time.Unix(intTime, 0),
 		Name:        name,
-		Content:     parseMessage(s, message.Text),
+		UserID:      message.User,
+		Content:     parseMessage(ctx, s, channelID, message.Text),
+		Edited:      message.Edited != nil,
 		StyleTime:   s.Config.Theme.Message.Time,
 		StyleThread: s.Config.Theme.Message.Thread,
 		StyleName:   s.Config.Theme.Message.Name,
 		StyleText:   s.Config.Theme.Message.Text,
 		FormatTime:  s.Config.Theme.Message.TimeFormat,
+
+		StyleMention: s.Config.Theme.Message.Mention,
+	}
+
+	// Add the reactions on the message
+	for _, reaction := range message.Reactions {
+		var reacted bool
+		for _, userID := range reaction.Users {
+			if userID == s.CurrentUserID {
+				reacted = true
+				break
+			}
+		}
+
+		msg.Reactions = append(msg.Reactions, components.Reaction{
+			Name:    parseReaction(s, channelID, reaction.Name),
+			Count:   reaction.Count,
+			Reacted: reacted,
+		})
 	}
 
 	// When there are attachments, add them to Messages
@@ -666,6 +1615,8 @@ func (s *SlackService) CreateMessage(message slack.Message, channelID string) co
 		}
 	}
 
+	msg.Attachments = len(message.Attachments) + len(message.Files)
+
 	// When the message timestamp and thread timestamp are the same, we
 	// have a parent message. This means it contains a thread with replies.
 	//
@@ -676,26 +1627,75 @@ func (s *SlackService) CreateMessage(message slack.Message, channelID string) co
 	// reference in the cache.
 	if message.ThreadTimestamp != "" && message.ThreadTimestamp == message.Timestamp {
 
-		// Set the thread identifier for thread cache
-		f, _ := strconv.ParseFloat(message.ThreadTimestamp, 64)
-		threadID := hashID(int(f))
-		s.ThreadCache[threadID] = message.ThreadTimestamp
-
 		// Set thread prefix for message
-		msg.Thread = fmt.Sprintf("%s ", threadID)
+		msg.Thread = s.GetThreadPrefix(message.ThreadTimestamp)
 
-		// Create the message replies from the thread
-		replies := s.CreateMessageFromReplies(message.ThreadTimestamp, channelID)
-		for _, reply := range replies {
-			msg.Messages[reply.ID] = reply
-		}
+		// The replies aren't fetched here, because that would mean an
+		// additional request for every thread, see LoadReplies
+		msg.ReplyCount = message.ReplyCount
 	}
 
 	return msg
 }
 
+// LoadReplies will add the latest page of replies to a message that is the
+// parent of a thread, and returns the updated message. When the replies
+// can't be fetched the message is returned unchanged, together with the
+// error, so that loading the replies can be retried.
+func (s *SlackService) LoadReplies(ctx context.Context, msg components.Message, channelID string) (components.Message, error) {
+	if msg.Thread == "" || msg.ReplyCount == 0 {
+		return msg, nil
+	}
+
+	replies, cursor, err := s.CreateMessageFromReplies(ctx, msg.ID, channelID)
+	if err != nil {
+		return msg, err
+	}
+
+	for _, reply := range replies {
+		msg.Messages[reply.ID] = reply
+	}
+	msg.RepliesCursor = cursor
+	msg.RepliesLoaded = true
+
+	return msg, nil
+}
+
+// GetThreadPrefix will return the shortened thread identifier, that is used
+// as the prefix of a thread parent message, and sets it in the thread cache.
+func (s *SlackService) GetThreadPrefix(threadTimestamp string) string {
+	f, _ := strconv.ParseFloat(threadTimestamp, 64)
+	threadID := hashID(int(f))
+	s.threadsMu.Lock()
+	s.ThreadCache[threadID] = threadTimestamp
+	s.threadsMu.Unlock()
+
+	return fmt.Sprintf("%s ", threadID)
+}
+
+// CreateThreadItem will create the ChannelItem that identifies a thread,
+// from the parent message of the thread
+func (s *SlackService) CreateThreadItem(parent components.Message) components.ChannelItem {
+	return components.ChannelItem{
+		ID:          parent.ID,
+		Name:        parent.Thread,
+		Type:        components.ChannelTypeGroup,
+		StylePrefix: s.Config.Theme.Channel.Prefix,
+		StyleIcon:   s.Config.Theme.Channel.Icon,
+		StyleText:   s.Config.Theme.Channel.Text,
+
+		StyleUnread:  s.Config.Theme.Channel.Unread,
+		StyleMention: s.Config.Theme.Channel.Mention,
+		IconUnread:   s.Config.Theme.Channel.UnreadIcon,
+		IconMention:  s.Config.Theme.Channel.MentionIcon,
+	}
+}
+
 // CreateMessageFromReplies will create components.Message struct from
-// the conversation replies from slack.
+// the latest page of the conversation replies from slack. It returns the
+// replies, the cursor of the older replies, which is empty when all the
+// replies have been fetched, and an error. Older pages can be fetched on
+// demand with GetReplies.
 //
 // Useful documentation:
 //
@@ -703,52 +1703,66 @@ func (s *SlackService) CreateMessage(message slack.Message, channelID string) co
 // https://api.slack.com/methods/conversations.replies
 // https://godoc.org/github.com/nlopes/slack#Client.GetConversationReplies
 // https://godoc.org/github.com/nlopes/slack#GetConversationRepliesParameters
-func (s *SlackService) CreateMessageFromReplies(messageID string, channelID string) []components.Message {
-	msgs := make([]slack.Message, 0)
-
-	initReplies, _, initCur, err := s.Client.GetConversationReplies(
-		&slack.GetConversationRepliesParameters{
-			ChannelID: channelID,
-			Timestamp: messageID,
-			Limit:     200,
-		},
-	)
-	if err != nil {
-		log.Fatal(err) // FIXME
-	}
+func (s *SlackService) CreateMessageFromReplies(ctx context.Context, messageID string, channelID string) ([]components.Message, string, error) {
+	return s.GetReplies(ctx, messageID, channelID, "")
+}
 
-	msgs = append(msgs, initReplies...)
+// GetReplies will get a single page of the replies of a thread, the latest
+// replies that were posted before cursor. It returns the replies, and the
+// cursor of the older replies. The cursor is the timestamp of the oldest
+// reply that has been fetched, all replies are fetched when it is empty.
+//
+// NOTE: the conversations api returns the replies from oldest to newest,
+// there is no way to start at the newest page. So the pages are fetched
+// up to cursor, and only the last page is kept.
+func (s *SlackService) GetReplies(ctx context.Context, messageID string, channelID string, cursor string) ([]components.Message, string, error) {
+	params := &slack.GetConversationRepliesParameters{
+		ChannelID: channelID,
+		Timestamp: messageID,
+		Latest:    cursor,
+		Limit:     repliesPageSize,
+	}
 
-	nextCur := initCur
-	for nextCur != "" {
-		conversationReplies, _, cursor, err := s.Client.GetConversationReplies(&slack.GetConversationRepliesParameters{
-			ChannelID: channelID,
-			Timestamp: messageID,
-			Cursor:    nextCur,
-			Limit:     200,
-		})
+	var msgs []slack.Message
+	var older bool
+	for {
+		// Rate limit
+		if s.RateLimiter != nil {
+			if err := s.RateLimiter.WaitContext(ctx); err != nil {
+				return nil, "", err
+			}
+		}
 
+		page, _, nextCur, err := s.Client().GetConversationRepliesContext(ctx, params)
 		if err != nil {
-			log.Fatal(err) // FIXME
+			return nil, "", err
+		}
+
+		for _, reply := range page {
+			// Because the conversations api returns an entire thread
+			// (a message plus all the messages in reply), we need to
+			// check if one of the replies isn't the parent that we
+			// started with.
+			if reply.ThreadTimestamp != "" && reply.ThreadTimestamp == reply.Timestamp {
+				continue
+			}
+			msgs = append(msgs, reply)
+		}
+
+		if len(msgs) > repliesPageSize {
+			msgs = append([]slack.Message{}, msgs[len(msgs)-repliesPageSize:]...)
+			older = true
 		}
 
-		msgs = append(msgs, conversationReplies...)
-		nextCur = cursor
+		if nextCur == "" {
+			break
+		}
+		params.Cursor = nextCur
 	}
 
 	var replies []components.Message
 	for _, reply := range msgs {
-		// Because the conversations api returns an entire thread (a
-		// message plus all the messages in reply), we need to check if
-		// one of the replies isn't the parent that we started with.
-		//
-		// Keep in mind that the api returns the replies with the latest
-		// as the first element.
-		if reply.ThreadTimestamp != "" && reply.ThreadTimestamp == reply.Timestamp {
-			continue
-		}
-
-		msg := s.CreateMessage(reply, channelID)
+		msg := s.CreateMessage(ctx, reply, channelID)
 
 		// Set the thread separator
 		msg.Thread = "  "
@@ -756,7 +1770,12 @@ func (s *SlackService) CreateMessageFromReplies(messageID string, channelID stri
 		replies = append(replies, msg)
 	}
 
-	return replies
+	var olderCur string
+	if older {
+		olderCur = msgs[0].Timestamp
+	}
+
+	return replies, olderCur, nil
 }
 
 // CreateMessageFromAttachments will construct an array of strings from the
@@ -808,7 +1827,25 @@ func (s *SlackService) CreateMessageFromAttachments(atts []slack.Attachment) []c
 			)
 		}
 
-		if att.Title != "" {
+		// The title of an image is shown with it
+		if att.ImageURL != "" {
+			msgs = append(
+				msgs,
+				components.Message{
+					Content: s.formatImage(imageDetails{
+						Name:  imageName(att.ImageURL),
+						Title: att.Title,
+						Alt:   att.Fallback,
+						URL:   att.ImageURL,
+					}),
+					StyleTime:   s.Config.Theme.Message.Time,
+					StyleThread: s.Config.Theme.Message.Thread,
+					StyleName:   s.Config.Theme.Message.Name,
+					StyleText:   s.Config.Theme.Message.Text,
+					FormatTime:  s.Config.Theme.Message.TimeFormat,
+				},
+			)
+		} else if att.Title != "" {
 			msgs = append(
 				msgs,
 				components.Message{
@@ -829,13 +1866,33 @@ func (s *SlackService) CreateMessageFromAttachments(atts []slack.Attachment) []c
 // CreateMessageFromFiles will create components.Message struct from
 // conversation attached files
 func (s *SlackService) CreateMessageFromFiles(files []slack.File) []components.Message {
+	return s.createFileMessages(files, nil)
+}
+
+// createFileMessages will create the messages of attached files, durations
+// are the durations of audio and video files in milliseconds keyed by file
+// id, when they're known
+func (s *SlackService) createFileMessages(files []slack.File, durations map[string]int64) []components.Message {
 	var msgs []components.Message
 
 	for _, file := range files {
+		content := fmt.Sprintf("%s %s", file.Title, file.URLPrivate)
+		if components.MediaIcon(file.Mimetype) != "" {
+			content = s.formatMedia(
+				file, time.Duration(durations[file.ID])*time.Millisecond,
+			)
+		} else if isImage(file) {
+			content = s.formatImage(imageDetails{
+				Name:   file.Name,
+				Title:  file.Title,
+				Width:  file.OriginalW,
+				Height: file.OriginalH,
+			})
+		}
+
 		msgs = append(msgs, components.Message{
-			Content: fmt.Sprintf(
-				"%s %s", file.Title, file.URLPrivate,
-			),
+			ID:          file.ID,
+			Content:     content,
 			StyleTime:   s.Config.Theme.Message.Time,
 			StyleThread: s.Config.Theme.Message.Thread,
 			StyleName:   s.Config.Theme.Message.Name,
@@ -849,30 +1906,37 @@ func (s *SlackService) CreateMessageFromFiles(files []slack.File) []components.M
 }
 
 func (s *SlackService) CreateMessageFromMessageEvent(message *slack.MessageEvent, channelID string) (components.Message, error) {
+	// The events aren't part of a request, the users of the messages are
+	// looked up in the background
+	bg := context.Background()
 	msg := slack.Message{Msg: message.Msg}
 
 	switch message.SubType {
 	case "message_changed":
-		// Append (edited) when an edited message is received
+		// Mark the message as edited when an edited message is received
 		msg = slack.Message{Msg: *message.SubMessage}
-		msg.Text = fmt.Sprintf("%s (edited)", msg.Text)
+		created := s.CreateMessage(bg, msg, channelID)
+		created.Edited = true
+		return created, nil
 	case "message_replied":
 		return components.Message{}, errors.New("ignoring reply events")
 	}
 
-	return s.CreateMessage(msg, channelID), nil
+	return s.CreateMessage(bg, msg, channelID), nil
 }
 
 // parseMessage will parse a message string and find and replace:
 //	- emoji's
 //	- mentions
+//	- user group mentions
 //	- html unescape
-func parseMessage(s *SlackService, msg string) string {
-	if s.Config.Emoji {
+func parseMessage(ctx context.Context, s *SlackService, channelID string, msg string) string {
+	if s.emojiEnabled(channelID) {
 		msg = parseEmoji(msg)
 	}
 
-	msg = parseMentions(s, msg)
+	msg = parseMentions(ctx, s, msg)
+	msg = parseUserGroupMentions(ctx, s, msg)
 
 	msg = html.UnescapeString(msg)
 
@@ -885,7 +1949,7 @@ func parseMessage(s *SlackService, msg string) string {
 // Mentions have the following format:
 //	<@U12345|erroneousboat>
 // 	<@U12345>
-func parseMentions(s *SlackService, msg string) string {
+func parseMentions(ctx context.Context, s *SlackService, msg string) string {
 	r := regexp.MustCompile(`\<@(\w+\|*\w+)\>`)
 
 	return r.ReplaceAllStringFunc(
@@ -903,16 +1967,86 @@ func parseMentions(s *SlackService, msg string) string {
 				userID = rs[1]
 			}
 
-			name, _ := s.GetUserName(userID)
+			name, _ := s.GetUserName(ctx, userID)
 			return "@" + name
 		},
 	)
 }
 
+// AddReaction will add a reaction to a message, see:
+// https://api.slack.com/methods/reactions.add
+func (s *SlackService) AddReaction(ctx context.Context, channelID string, messageID string, name string) error {
+	err := s.Client().AddReactionContext(ctx, name, slack.NewRefToMessage(channelID, messageID))
+	if err != nil {
+		return err
+	}
+
+	s.useEmoji([]string{name})
+
+	return nil
+}
+
+// RemoveReaction will remove a reaction from a message, see:
+// https://api.slack.com/methods/reactions.remove
+func (s *SlackService) RemoveReaction(ctx context.Context, channelID string, messageID string, name string) error {
+	return s.Client().RemoveReactionContext(ctx, name, slack.NewRefToMessage(channelID, messageID))
+}
+
+// GetDoNotDisturb returns the do not disturb status of the current user,
+// see: https://api.slack.com/methods/dnd.info
+func (s *SlackService) GetDoNotDisturb(ctx context.Context) (slack.DNDStatus, error) {
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return slack.DNDStatus{}, err
+		}
+	}
+
+	status, err := s.Client().GetDNDInfoContext(ctx, &s.CurrentUserID)
+	if err != nil {
+		return slack.DNDStatus{}, err
+	}
+	return *status, nil
+}
+
+// GetCustomEmoji returns the names of the custom emoji of the workspace
+// that are images, once they've been stored in the persistent cache with
+// the warm command
+func (s *SlackService) GetCustomEmoji() []string {
+	return s.CustomEmoji
+}
+
+// GetReactionName returns the name of a reaction as it's shown under a
+// message, e.g. when it's received over the rtm connection
+func (s *SlackService) GetReactionName(channelID string, name string) string {
+	return parseReaction(s, channelID, name)
+}
+
+// parseReaction will create the emoji placeholder of a reaction, and replace
+// it with the unicode equivalent when emoji are enabled
+func parseReaction(s *SlackService, channelID string, name string) string {
+	reaction := fmt.Sprintf(":%s:", name)
+	if s.emojiEnabled(channelID) {
+		reaction = parseEmoji(reaction)
+	}
+	return reaction
+}
+
+// emojiEnabled returns whether emoji are rendered in the channel
+func (s *SlackService) emojiEnabled(channelID string) bool {
+	var name string
+	for _, chn := range s.Conversations {
+		if chn.ID == channelID {
+			name = chn.Name
+			break
+		}
+	}
+	return s.Config.EmojiEnabled(channelID, name)
+}
+
 // parseEmoji will try to find emoji placeholders in the message
 // string and replace them with the correct unicode equivalent
 func parseEmoji(msg string) string {
-	r := regexp.MustCompile("(:\\w+:)")
+	r := regexp.MustCompile("(:[\\w+-]+:)")
 
 	return r.ReplaceAllStringFunc(
 		msg, func(str string) string {
@@ -925,15 +2059,59 @@ func parseEmoji(msg string) string {
 	)
 }
 
+// getMpIMName will return the names of the members of a group conversation
+// without the current user, e.g. "alice, bob". When the members aren't
+// part of the conversation, the names are taken from its name, which is
+// formatted like "mpdm-alice--bob--carol-1".
+func (s *SlackService) getMpIMName(ctx context.Context, chn slack.Channel) string {
+	var names []string
+	if len(chn.Members) > 0 {
+		for _, userID := range chn.Members {
+			name, _ := s.GetUserName(ctx, userID)
+			names = append(names, name)
+		}
+	} else {
+		name := strings.TrimPrefix(chn.Name, "mpdm-")
+		if i := strings.LastIndex(name, "-"); i > 0 {
+			name = name[:i]
+		}
+		names = strings.Split(name, "--")
+	}
+
+	members := make([]string, 0, len(names))
+	for _, name := range names {
+		if name != s.CurrentUsername {
+			members = append(members, name)
+		}
+	}
+
+	if len(members) == 0 {
+		return chn.Name
+	}
+
+	return strings.Join(members, ", ")
+}
+
 func (s *SlackService) createChannelItem(chn slack.Channel) components.ChannelItem {
 	return components.ChannelItem{
 		ID:          chn.ID,
 		Name:        chn.Name,
 		Topic:       chn.Topic.Value,
+		Purpose:     chn.Purpose.Value,
+		IsExtShared: chn.IsExtShared,
 		UserID:      chn.User,
+		UnreadCount: chn.UnreadCountDisplay,
+		Muted:       s.IsMuted(components.ChannelItem{ID: chn.ID, Name: chn.Name}),
+		Starred:     s.isStarred(chn.ID),
 		StylePrefix: s.Config.Theme.Channel.Prefix,
 		StyleIcon:   s.Config.Theme.Channel.Icon,
 		StyleText:   s.Config.Theme.Channel.Text,
+
+		StyleUnread:  s.Config.Theme.Channel.Unread,
+		StyleMention: s.Config.Theme.Channel.Mention,
+		StyleMuted:   s.Config.Theme.Channel.Muted,
+		IconUnread:   s.Config.Theme.Channel.UnreadIcon,
+		IconMention:  s.Config.Theme.Channel.MentionIcon,
 	}
 }
 
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+
+	"github.com/slack-go/slack"
+)
+
+// getStarredChannels returns the ids of the conversations the user has
+// starred, starred messages and files are ignored
+func (s *SlackService) getStarredChannels(ctx context.Context) (map[string]bool, error) {
+	// Rate limit
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	items, err := s.Client().ListAllStarsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	stars := make(map[string]bool)
+	for _, item := range items {
+		if isConversationItem(item.Type) {
+			stars[item.Channel] = true
+		}
+	}
+
+	return stars, nil
+}
+
+// isConversationItem returns whether the type of a starred item is that of
+// a conversation, instead of e.g. a message
+func isConversationItem(itemType string) bool {
+	switch itemType {
+	case slack.TYPE_CHANNEL, slack.TYPE_GROUP, slack.TYPE_IM, "mpim":
+		return true
+	}
+	return false
+}
+
+// SetStar will star or unstar the conversation with channelID
+func (s *SlackService) SetStar(ctx context.Context, channelID string, starred bool) error {
+	// Rate limit
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if starred {
+		err = s.Client().AddStarContext(ctx, channelID, slack.ItemRef{})
+	} else {
+		err = s.Client().RemoveStarContext(ctx, channelID, slack.ItemRef{})
+	}
+	if err != nil {
+		return err
+	}
+
+	s.prefsMu.Lock()
+	if starred {
+		s.Stars[channelID] = true
+	} else {
+		delete(s.Stars, channelID)
+	}
+	s.prefsMu.Unlock()
+
+	return nil
+}
+
+// isStarred returns whether the channel with channelID is starred
+func (s *SlackService) isStarred(channelID string) bool {
+	s.prefsMu.RLock()
+	defer s.prefsMu.RUnlock()
+
+	return s.Stars[channelID]
+}
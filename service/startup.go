@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/slack-go/slack"
+)
+
+type conversationCounts struct {
+	ID           string `json:"id"`
+	LastRead     string `json:"last_read"`
+	Latest       string `json:"latest"`
+	HasUnreads   bool   `json:"has_unreads"`
+	MentionCount int    `json:"mention_count"`
+}
+
+type clientCounts struct {
+	slack.SlackResponse
+	Channels []conversationCounts `json:"channels"`
+	MpIMs    []conversationCounts `json:"mpims"`
+	IMs      []conversationCounts `json:"ims"`
+}
+
+// getClientCounts returns whether the conversations of the user have unread
+// messages, and the number of unread mentions, with a single request.
+//
+// client.counts isn't documented, it's the endpoint the web client uses.
+func (s *SlackService) getClientCounts(ctx context.Context) (clientCounts, error) {
+	var counts clientCounts
+	if err := s.callAPI(ctx, "client.counts", url.Values{}, &counts); err != nil {
+		return counts, err
+	}
+
+	return counts, counts.Err()
+}
+
+// GetStartupChannel returns the id of the conversation that is most
+// important to open: the conversation with the oldest unread mention, or
+// when there is none the direct message with the most recent message. It's
+// empty when there are no direct messages either.
+func (s *SlackService) GetStartupChannel(ctx context.Context) (string, error) {
+	counts, err := s.getClientCounts(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	all := append(append(counts.Channels, counts.MpIMs...), counts.IMs...)
+
+	// The oldest unread mention is in the conversation that has been
+	// read least recently
+	var mentioned conversationCounts
+	for _, chn := range all {
+		if chn.MentionCount == 0 {
+			continue
+		}
+		if mentioned.ID == "" || chn.LastRead < mentioned.LastRead {
+			mentioned = chn
+		}
+	}
+	if mentioned.ID != "" {
+		return mentioned.ID, nil
+	}
+
+	var recent conversationCounts
+	for _, chn := range counts.IMs {
+		if chn.Latest > recent.Latest {
+			recent = chn
+		}
+	}
+
+	return recent.ID, nil
+}
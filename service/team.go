@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/slack-go/slack"
+)
+
+// Team is the workspace of the current user
+type Team struct {
+	ID     string
+	Name   string
+	Domain string
+
+	// Plan is the paid plan of the workspace, e.g. "std" or "plus", it's
+	// empty for the free plan
+	Plan string
+
+	// EnterpriseID and EnterpriseName are set when the workspace is part
+	// of an Enterprise Grid organization
+	EnterpriseID   string
+	EnterpriseName string
+}
+
+// GetCurrentTeam returns the workspace of the current user
+func (s *SlackService) GetCurrentTeam(ctx context.Context) (Team, error) {
+	var info struct {
+		slack.SlackResponse
+		Team struct {
+			ID             string `json:"id"`
+			Name           string `json:"name"`
+			Domain         string `json:"domain"`
+			Plan           string `json:"plan"`
+			EnterpriseID   string `json:"enterprise_id"`
+			EnterpriseName string `json:"enterprise_name"`
+		} `json:"team"`
+	}
+
+	err := s.callAPI(ctx, "team.info", url.Values{}, &info)
+	if err != nil {
+		return Team{}, err
+	}
+	if err := info.Err(); err != nil {
+		return Team{}, err
+	}
+
+	return Team{
+		ID:             info.Team.ID,
+		Name:           info.Team.Name,
+		Domain:         info.Team.Domain,
+		Plan:           info.Team.Plan,
+		EnterpriseID:   info.Team.EnterpriseID,
+		EnterpriseName: info.Team.EnterpriseName,
+	}, nil
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// tokenRefreshMargin is how long before it expires the access token is
+// refreshed
+const tokenRefreshMargin = 5 * time.Minute
+
+// tokenRetryInterval is how long to wait before retrying a failed refresh
+const tokenRetryInterval = time.Minute
+
+type tokenResponse struct {
+	slack.SlackResponse
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// rotatesTokens returns whether token rotation has been configured
+//
+// https://api.slack.com/authentication/rotation
+func (s *SlackService) rotatesTokens() bool {
+	return s.Config.SlackRefreshToken != "" &&
+		s.Config.SlackClientID != "" &&
+		s.Config.SlackClientSecret != ""
+}
+
+// loadTokens will use the tokens of a previous session when they are
+// available, and refresh the access token when it has (almost) expired.
+// When the slack token has been entered in the prompt, only the refresh
+// token of the previous session is used, and the entered access token is
+// refreshed once it has been accepted, see rotateTokens.
+func (s *SlackService) loadTokens() error {
+	accessToken, refreshToken, expiresAt, ok := s.getTokens()
+	if ok {
+		s.Config.SlackRefreshToken = refreshToken
+	}
+
+	if s.Config.TokenEntered {
+		return nil
+	}
+
+	if ok {
+		s.setToken(accessToken)
+		s.tokenExpiresAt = expiresAt
+	}
+
+	if time.Until(s.tokenExpiresAt) < tokenRefreshMargin {
+		return s.refreshToken()
+	}
+
+	return nil
+}
+
+// rotateTokens will refresh the access token before it expires, until the
+// rotation is stopped, see stopRotating
+func (s *SlackService) rotateTokens() {
+	for {
+		select {
+		case <-time.After(time.Until(s.tokenExpiresAt.Add(-tokenRefreshMargin))):
+		case <-s.stopRotation:
+			return
+		}
+
+		if err := s.refreshToken(); err != nil {
+			select {
+			case <-time.After(tokenRetryInterval):
+			case <-s.stopRotation:
+				return
+			}
+		}
+	}
+}
+
+// stopRotating will stop the rotation of the tokens
+func (s *SlackService) stopRotating() {
+	s.stopOnce.Do(func() {
+		close(s.stopRotation)
+	})
+}
+
+// refreshToken will exchange the refresh token for a new access token, and
+// persist the new tokens. The refresh token can only be used once, so it is
+// replaced as well.
+func (s *SlackService) refreshToken() error {
+	values := url.Values{
+		"client_id":     {s.Config.SlackClientID},
+		"client_secret": {s.Config.SlackClientSecret},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {s.Config.SlackRefreshToken},
+	}
+
+	var resp tokenResponse
+	if err := s.postAPI(context.Background(), "oauth.v2.access", values, &resp); err != nil {
+		return err
+	}
+	if err := resp.Err(); err != nil {
+		return err
+	}
+
+	s.setToken(resp.AccessToken)
+	s.Config.SlackRefreshToken = resp.RefreshToken
+	s.tokenExpiresAt = time.Now().Add(time.Duration(resp.ExpiresIn) * time.Second)
+
+	return s.saveTokens(resp.AccessToken, resp.RefreshToken)
+}
+
+// getTokens returns the persisted tokens of a previous session. When the
+// secrets are kept out of the config file, only tokens that are sealed with
+// the key of the encrypted_secrets are used.
+func (s *SlackService) getTokens() (string, string, time.Time, bool) {
+	if s.PersistentCache == nil {
+		return "", "", time.Time{}, false
+	}
+
+	accessToken, refreshToken, expiresAt, ok := s.PersistentCache.GetTokens(
+		s.Config.SlackClientID,
+	)
+	if !ok || !s.Config.HasSecrets() {
+		return accessToken, refreshToken, expiresAt, ok
+	}
+
+	if !s.Config.CanSealSecrets() {
+		return "", "", time.Time{}, false
+	}
+
+	accessToken, err := s.Config.OpenSecret(accessToken)
+	if err != nil {
+		return "", "", time.Time{}, false
+	}
+	refreshToken, err = s.Config.OpenSecret(refreshToken)
+	if err != nil {
+		return "", "", time.Time{}, false
+	}
+
+	return accessToken, refreshToken, expiresAt, true
+}
+
+// saveTokens will persist the tokens, so they're used by the next session.
+// When the secrets are kept out of the config file, the tokens are sealed
+// with the key of the encrypted_secrets. With secrets_command there is no
+// key, and the tokens aren't persisted.
+func (s *SlackService) saveTokens(accessToken string, refreshToken string) error {
+	if s.PersistentCache == nil {
+		return nil
+	}
+
+	if s.Config.HasSecrets() {
+		if !s.Config.CanSealSecrets() {
+			return s.PersistentCache.DeleteTokens(s.Config.SlackClientID)
+		}
+
+		var err error
+		if accessToken, err = s.Config.SealSecret(accessToken); err != nil {
+			return err
+		}
+		if refreshToken, err = s.Config.SealSecret(refreshToken); err != nil {
+			return err
+		}
+	}
+
+	return s.PersistentCache.SetTokens(
+		s.Config.SlackClientID,
+		accessToken,
+		refreshToken,
+		s.tokenExpiresAt,
+	)
+}
+
+// setToken will replace the Client with one that uses the access token.
+// When the service is connected, the RTM is replaced as well, as it would
+// keep using the previous access token when it reconnects. The events of
+// the new RTM are received on the same IncomingEvents.
+func (s *SlackService) setToken(accessToken string) {
+	client := slack.New(accessToken, s.clientOptions...)
+
+	s.clientMu.Lock()
+	s.token = accessToken
+	s.client = client
+
+	prev := s.rtm
+	if prev != nil {
+		s.rtm = client.NewRTM()
+		s.rtm.IncomingEvents = prev.IncomingEvents
+	}
+	rtm := s.rtm
+	s.clientMu.Unlock()
+
+	if prev != nil {
+		prev.Disconnect()
+		go rtm.ManageConnection()
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/slack-go/slack"
+)
+
+// UnreadConversation is a conversation of the user that has unread
+// messages or unread mentions
+type UnreadConversation struct {
+	ID       string
+	Name     string
+	Unread   int
+	Mentions int
+}
+
+// GetUnreadConversations returns the conversations of the user that have
+// unread messages or unread mentions, the ones with the most mentions
+// first. Only the conversations that are unread are requested, the names
+// of the users are taken from the persistent cache when possible.
+func (s *SlackService) GetUnreadConversations(ctx context.Context) ([]UnreadConversation, error) {
+	counts, err := s.getClientCounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	all := append(append(counts.Channels, counts.MpIMs...), counts.IMs...)
+
+	var unread []UnreadConversation
+	for _, chn := range all {
+		if !chn.HasUnreads && chn.MentionCount == 0 {
+			continue
+		}
+
+		if s.RateLimiter != nil {
+			if err := s.RateLimiter.WaitContext(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		info, err := s.Client().GetConversationInfoContext(ctx, chn.ID, false)
+		if err != nil {
+			return nil, err
+		}
+
+		unread = append(unread, UnreadConversation{
+			ID:       chn.ID,
+			Name:     s.getConversationName(ctx, *info),
+			Unread:   info.UnreadCountDisplay,
+			Mentions: chn.MentionCount,
+		})
+	}
+
+	sort.SliceStable(unread, func(i, j int) bool {
+		if unread[i].Mentions != unread[j].Mentions {
+			return unread[i].Mentions > unread[j].Mentions
+		}
+		return unread[i].Name < unread[j].Name
+	})
+
+	return unread, nil
+}
+
+// getConversationName returns the name of a conversation as it's shown
+// outside of the sidebar, e.g. "#general" or "@erroneousboat"
+func (s *SlackService) getConversationName(ctx context.Context, chn slack.Channel) string {
+	switch {
+	case chn.IsIM:
+		name, _ := s.GetUserName(ctx, chn.User)
+		return "@" + name
+	case chn.IsMpIM:
+		return s.getMpIMName(ctx, chn)
+	default:
+		return "#" + chn.Name
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/slack-go/slack/slackutilsx"
+)
+
+// UserGroup is a group of users that can be mentioned by its handle
+type UserGroup struct {
+	ID     string
+	Handle string
+	Name   string
+}
+
+// GetUserGroups returns the user groups of the workspace, they're fetched
+// once and cached for the rest of the session
+func (s *SlackService) GetUserGroups(ctx context.Context) ([]UserGroup, error) {
+	s.userGroupsMu.Lock()
+	defer s.userGroupsMu.Unlock()
+
+	if s.userGroups != nil {
+		return s.userGroups, nil
+	}
+
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	groups, err := s.Client().GetUserGroupsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.userGroups = make([]UserGroup, 0, len(groups))
+	for _, group := range groups {
+		s.userGroups = append(s.userGroups, UserGroup{
+			ID:     group.ID,
+			Handle: group.Handle,
+			Name:   group.Name,
+		})
+	}
+
+	return s.userGroups, nil
+}
+
+// getUserGroupHandle returns the handle of a user group. When the user
+// groups can't be fetched, e.g. because the token misses the
+// usergroups:read scope, they aren't fetched again.
+func (s *SlackService) getUserGroupHandle(ctx context.Context, groupID string) (string, bool) {
+	groups, err := s.GetUserGroups(ctx)
+	if err != nil {
+		s.userGroupsMu.Lock()
+		s.userGroups = []UserGroup{}
+		s.userGroupsMu.Unlock()
+		return "", false
+	}
+
+	for _, group := range groups {
+		if group.ID == groupID {
+			return group.Handle, true
+		}
+	}
+
+	return "", false
+}
+
+// parseUserGroupMentions will replace the user group mentions in the
+// message with the handle of the group, with an @ symbol
+//
+// User group mentions have the following format:
+//
+//	<!subteam^S12345|@backend-team>
+//	<!subteam^S12345>
+func parseUserGroupMentions(ctx context.Context, s *SlackService, msg string) string {
+	r := regexp.MustCompile(`<!subteam\^(\w+)(?:\|([^>]*))?>`)
+
+	return r.ReplaceAllStringFunc(
+		msg, func(str string) string {
+			rs := r.FindStringSubmatch(str)
+
+			if handle, ok := s.getUserGroupHandle(ctx, rs[1]); ok {
+				return "@" + handle
+			}
+
+			if rs[2] != "" {
+				return "@" + strings.TrimPrefix(rs[2], "@")
+			}
+
+			return "@" + rs[1]
+		},
+	)
+}
+
+// encodeMessage will escape the message that is sent, and encode the
+// mentions of user groups and users in it, so that they're notified. Only
+// the users that have been found with SearchUsers are encoded.
+func (s *SlackService) encodeMessage(ctx context.Context, msg string) string {
+	msg = slackutilsx.EscapeMessage(msg)
+
+	handles := make(map[string]string)
+	if groups, err := s.GetUserGroups(ctx); err == nil {
+		for _, group := range groups {
+			handles[group.Handle] = group.ID
+		}
+	}
+
+	s.mentionsMu.Lock()
+	defer s.mentionsMu.Unlock()
+
+	if len(handles) == 0 && len(s.mentions) == 0 {
+		return msg
+	}
+
+	r := regexp.MustCompile(`(^|\s)@([\w.-]+)`)
+
+	return r.ReplaceAllStringFunc(
+		msg, func(str string) string {
+			rs := r.FindStringSubmatch(str)
+
+			if groupID, ok := handles[rs[2]]; ok {
+				return fmt.Sprintf("%s<!subteam^%s|@%s>", rs[1], groupID, rs[2])
+			}
+
+			if userID, ok := s.mentions[rs[2]]; ok {
+				return fmt.Sprintf("%s<@%s>", rs[1], userID)
+			}
+
+			return str
+		},
+	)
+}
@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/lithammer/fuzzysearch/fuzzy"
+)
+
+// searchUsersLimit is the maximum number of users that SearchUsers returns
+const searchUsersLimit = 10
+
+// User is a user of the workspace that can be mentioned
+type User struct {
+	ID       string
+	Name     string
+	RealName string
+}
+
+// SearchUsers returns the users of which the name or the real name fuzzy
+// matches query, the closest matches first. They're searched in the
+// persistent cache and the users that have been seen. When none of them
+// match, all the users of the workspace are fetched with users.list once,
+// and stored in the persistent cache.
+//
+// The users that are returned are encoded as mentions when "@name" is part
+// of a message that is sent.
+func (s *SlackService) SearchUsers(ctx context.Context, query string) ([]User, error) {
+	users := s.searchUsers(query)
+
+	s.mentionsMu.Lock()
+	listed := s.usersListed
+	s.usersListed = true
+	s.mentionsMu.Unlock()
+
+	if len(users) == 0 && !listed && s.PersistentCache != nil {
+		if _, err := s.WarmUsers(ctx); err != nil {
+			return nil, err
+		}
+		users = s.searchUsers(query)
+	}
+
+	s.mentionsMu.Lock()
+	defer s.mentionsMu.Unlock()
+
+	if s.mentions == nil {
+		s.mentions = make(map[string]string)
+	}
+	for _, user := range users {
+		s.mentions[user.Name] = user.ID
+	}
+
+	return users, nil
+}
+
+func (s *SlackService) searchUsers(query string) []User {
+	candidates := make(map[string]User)
+	if s.PersistentCache != nil {
+		if cached, err := s.PersistentCache.GetUsers(); err == nil {
+			for _, user := range cached {
+				candidates[user.ID] = User{
+					ID:       user.ID,
+					Name:     user.Username,
+					RealName: user.RealName,
+				}
+			}
+		}
+	}
+
+	s.usersMu.RLock()
+	for userID, name := range s.UserCache {
+		if _, ok := candidates[userID]; ok || s.DeletedUsers[userID] ||
+			strings.HasPrefix(name, "unknown") {
+			continue
+		}
+		candidates[userID] = User{
+			ID:       userID,
+			Name:     name,
+			RealName: s.RealNameCache[userID],
+		}
+	}
+	s.usersMu.RUnlock()
+
+	type match struct {
+		user     User
+		distance int
+	}
+
+	var matches []match
+	for _, user := range candidates {
+		distance := fuzzy.RankMatchFold(query, user.Name)
+		if d := fuzzy.RankMatchFold(query, user.RealName); d >= 0 && (distance < 0 || d < distance) {
+			distance = d
+		}
+		if distance >= 0 {
+			matches = append(matches, match{user, distance})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].user.Name < matches[j].user.Name
+	})
+
+	if len(matches) > searchUsersLimit {
+		matches = matches[:searchUsersLimit]
+	}
+
+	users := make([]User, 0, len(matches))
+	for _, m := range matches {
+		users = append(users, m.user)
+	}
+	return users
+}
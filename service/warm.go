@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/erroneousboat/slack-term/config"
+)
+
+// usersPageSize is the number of users that is fetched per request
+const usersPageSize = 200
+
+// errNoPersistentCache is returned when the persistent cache that is warmed
+// couldn't be opened
+var errNoPersistentCache = errors.New("the persistent cache isn't available")
+
+// WarmUsers will store all the users of the workspace, including bots, in
+// the persistent cache. It returns the number of users that are stored.
+func (s *SlackService) WarmUsers(ctx context.Context) (int, error) {
+	if s.PersistentCache == nil {
+		return 0, errNoPersistentCache
+	}
+
+	var count int
+	pages := s.Client().GetUsersPaginated(slack.GetUsersOptionLimit(usersPageSize))
+	for {
+		if s.RateLimiter != nil {
+			if err := s.RateLimiter.WaitContext(ctx); err != nil {
+				return count, err
+			}
+		}
+
+		var err error
+		pages, err = pages.Next(ctx)
+		if pages.Done(err) {
+			break
+		}
+		if err != nil {
+			return count, err
+		}
+
+		users := make([]CachedUser, 0, len(pages.Users))
+		for _, user := range pages.Users {
+			users = append(users, CachedUser{
+				ID:       user.ID,
+				Username: user.Name,
+				RealName: user.RealName,
+				Deleted:  user.Deleted,
+			})
+		}
+
+		if err := s.PersistentCache.SetUsers(users); err != nil {
+			return count, err
+		}
+		count += len(users)
+	}
+
+	return count, nil
+}
+
+// WarmEmoji will store the custom emoji of the workspace in the persistent
+// cache. It returns the number of emoji that are stored.
+func (s *SlackService) WarmEmoji(ctx context.Context) (int, error) {
+	if s.PersistentCache == nil {
+		return 0, errNoPersistentCache
+	}
+
+	if s.RateLimiter != nil {
+		if err := s.RateLimiter.WaitContext(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	emoji, err := s.Client().GetEmojiContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := s.PersistentCache.SetEmoji(s.CurrentTeamID, emoji); err != nil {
+		return 0, err
+	}
+
+	return len(emoji), nil
+}
+
+// WarmChannels will store the members and the recent history of the
+// conversations of the user in the persistent cache. Every conversation
+// that has been stored is passed to progress. It returns the number of
+// conversations that are stored.
+func (s *SlackService) WarmChannels(ctx context.Context, count int, daysToFetch int, progress func(name string, err error)) (int, error) {
+	if s.PersistentCache == nil {
+		return 0, errNoPersistentCache
+	}
+
+	channels, err := s.GetChannels(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var warmed int
+	for _, channel := range channels {
+		if ctx.Err() != nil {
+			return warmed, ctx.Err()
+		}
+
+		_, err := s.refreshChannelMembers(ctx, channel.ID)
+		if err == nil {
+			err = s.PrefetchMessages(ctx, channel.ID, count, daysToFetch)
+		}
+
+		progress(channel.GetChannelName(), err)
+		if err == nil {
+			warmed++
+		}
+	}
+
+	return warmed, nil
+}
+
+// getCustomEmojiNames returns the sorted names of the custom emoji that
+// aren't in the EmojiCodemap, these are images, see addEmojiAliases
+func getCustomEmojiNames(emoji map[string]string) []string {
+	names := make([]string, 0, len(emoji))
+	for name := range emoji {
+		if _, ok := config.EmojiCodemap[fmt.Sprintf(":%s:", name)]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// addEmojiAliases will add the custom emoji that are an alias of another
+// emoji to the EmojiCodemap, e.g. "alias:thumbsup". Custom emoji that are
+// images can't be shown in the terminal, they remain a shortcode.
+func addEmojiAliases(emoji map[string]string) {
+	for name, value := range emoji {
+		if !strings.HasPrefix(value, "alias:") {
+			continue
+		}
+
+		code := fmt.Sprintf(":%s:", name)
+		if _, ok := config.EmojiCodemap[code]; ok {
+			continue
+		}
+
+		alias := fmt.Sprintf(":%s:", strings.TrimPrefix(value, "alias:"))
+		if unicode, ok := config.EmojiCodemap[alias]; ok {
+			config.EmojiCodemap[code] = unicode
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bufio"
+	gocontext "context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/erroneousboat/slack-term/config"
+	"github.com/erroneousboat/slack-term/context"
+	"github.com/erroneousboat/slack-term/service"
+)
+
+// warmHistoryCount is the number of messages that is stored of every
+// conversation by warm-cache, when history_count isn't set
+const warmHistoryCount = 100
+
+// subcommands are run instead of the user interface, e.g.
+// "slack-term warm-cache". They receive the arguments that follow the
+// name of the subcommand.
+var subcommands = map[string]func(args []string) error{
+	"warm-cache":     warmCache,
+	"unreads":        unreads,
+	"tail":           tail,
+	"encrypt-config": encryptConfig,
+}
+
+// newOfflineService will create a service for a subcommand, without
+// connecting to the real time api
+func newOfflineService() (*service.SlackService, error) {
+	cfg, err := context.LoadConfig(
+		flgConfig, flgToken, flgCookie, flgApiUrl,
+		func(retry bool) (string, error) {
+			if retry {
+				return readPassphrase("The passphrase is wrong, try again: ")
+			}
+			return readPassphrase("Passphrase: ")
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := service.NewOfflineSlackService(cfg)
+	if authErr, ok := err.(*service.AuthError); ok {
+		return nil, fmt.Errorf("%s: %v", authErr.Error(), authErr.Err)
+	}
+
+	return svc, err
+}
+
+// readPassphrase will ask for a passphrase on the terminal, without showing
+// what is typed
+func readPassphrase(prompt string) (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", errors.New("there is no terminal to ask for the passphrase, set the askpass command")
+	}
+	defer tty.Close()
+
+	stty := func(arg string) error {
+		cmd := exec.Command("stty", arg)
+		cmd.Stdin = tty
+		return cmd.Run()
+	}
+
+	fmt.Fprint(tty, prompt)
+	if err := stty("-echo"); err != nil {
+		return "", err
+	}
+	passphrase, err := bufio.NewReader(tty).ReadString('\n')
+	stty("echo")
+	fmt.Fprintln(tty)
+
+	return strings.TrimRight(passphrase, "\r\n"), err
+}
+
+// interruptContext returns a context that is cancelled on an interrupt
+func interruptContext() gocontext.Context {
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		cancel()
+	}()
+
+	return ctx
+}
+
+// warmCache will store the users, the custom emoji, and the members and
+// history of the conversations of the user in the persistent cache, so
+// that the user interface doesn't have to fetch them. It's meant to be
+// run periodically, e.g. every night with cron.
+func warmCache(args []string) error {
+	if len(args) > 0 {
+		return errors.New("usage: slack-term warm-cache")
+	}
+
+	svc, err := newOfflineService()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	ctx := interruptContext()
+
+	users, err := svc.WarmUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't store users: %v", err)
+	}
+	fmt.Printf("stored %d users\n", users)
+
+	emoji, err := svc.WarmEmoji(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't store emoji: %v", err)
+	}
+	fmt.Printf("stored %d emoji\n", emoji)
+
+	count := svc.Config.HistoryCount
+	if count == 0 {
+		count = warmHistoryCount
+	}
+
+	channels, err := svc.WarmChannels(
+		ctx, count, svc.Config.HistoryDays,
+		func(name string, err error) {
+			if err != nil {
+				fmt.Printf("couldn't store %s: %v\n", name, err)
+			}
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("couldn't store conversations: %v", err)
+	}
+	fmt.Printf("stored %d conversations\n", channels)
+
+	return nil
+}
+
+// unreads will print the conversations that have unread messages or unread
+// mentions, one per line with the name, the number of unread messages and
+// the number of unread mentions separated by tabs. With -total only the
+// totals are printed, which is meant for shell prompts.
+func unreads(args []string) error {
+	flags := flag.NewFlagSet("unreads", flag.ExitOnError)
+	total := flags.Bool("total", false, "only print the total unread messages and mentions")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: slack-term unreads [-total]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	svc, err := newOfflineService()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	conversations, err := svc.GetUnreadConversations(interruptContext())
+	if err != nil {
+		return fmt.Errorf("couldn't get unread conversations: %v", err)
+	}
+
+	if *total {
+		var unread, mentions int
+		for _, conversation := range conversations {
+			unread += conversation.Unread
+			mentions += conversation.Mentions
+		}
+		fmt.Printf("%d\t%d\n", unread, mentions)
+		return nil
+	}
+
+	for _, conversation := range conversations {
+		fmt.Printf(
+			"%s\t%d\t%d\n",
+			conversation.Name, conversation.Unread, conversation.Mentions,
+		)
+	}
+
+	return nil
+}
+
+// tailMessage is a message as it's printed by tail with -json
+type tailMessage struct {
+	Channel  string    `json:"channel"`
+	ID       string    `json:"ts"`
+	ThreadID string    `json:"thread_ts,omitempty"`
+	Time     time.Time `json:"time"`
+	User     string    `json:"user"`
+	Text     string    `json:"text"`
+}
+
+// tail will print the new messages of a single conversation as they are
+// received, until it's interrupted. The conversation is given by its id or
+// its name, e.g. "#deploys" or "@alice". Every message is printed on a line
+// of its own, or as a json object with -json.
+func tail(args []string) error {
+	flags := flag.NewFlagSet("tail", flag.ExitOnError)
+	channel := flags.String("channel", "", "the id or the name of the conversation")
+	asJSON := flags.Bool("json", false, "print every message as a json object")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: slack-term tail -channel [conversation] [-json]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if *channel == "" || flags.NArg() > 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	svc, err := newOfflineService()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	ctx := interruptContext()
+
+	channels, err := svc.GetChannels(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't get conversations: %v", err)
+	}
+
+	var channelID string
+	name := strings.TrimLeft(*channel, "#@")
+	for _, chn := range channels {
+		if chn.ID == name || chn.Name == name {
+			channelID = chn.ID
+			break
+		}
+	}
+	if channelID == "" {
+		return fmt.Errorf("conversation %s not found", *channel)
+	}
+
+	svc.Connect()
+
+	encoder := json.NewEncoder(os.Stdout)
+	for {
+		var rtmEvent slack.RTMEvent
+		select {
+		case rtmEvent = <-svc.IncomingEvents():
+		case <-ctx.Done():
+			return nil
+		}
+
+		switch ev := rtmEvent.Data.(type) {
+		case *slack.InvalidAuthEvent:
+			return errors.New("invalid credentials")
+		case *slack.MessageEvent:
+			if ev.Channel != channelID || ev.SubType == "message_deleted" {
+				continue
+			}
+
+			msg, err := svc.CreateMessageFromMessageEvent(ev, channelID)
+			if err != nil {
+				continue
+			}
+
+			if *asJSON {
+				err = encoder.Encode(tailMessage{
+					Channel:  channelID,
+					ID:       msg.ID,
+					ThreadID: ev.ThreadTimestamp,
+					Time:     msg.Time,
+					User:     msg.Name,
+					Text:     msg.Content,
+				})
+			} else {
+				_, err = fmt.Printf(
+					"%s %s: %s\n",
+					msg.Time.Format("2006-01-02 15:04:05"),
+					msg.Name,
+					strings.Replace(msg.Content, "\n", "\n\t", -1),
+				)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// encryptConfig will encrypt the slack token, cookie, refresh token and
+// client secret of the config file with a passphrase, they're decrypted
+// with the passphrase on startup
+func encryptConfig(args []string) error {
+	if len(args) > 0 {
+		return errors.New("usage: slack-term encrypt-config")
+	}
+
+	passphrase, err := readPassphrase("New passphrase: ")
+	if err != nil {
+		return err
+	}
+	if passphrase == "" {
+		return errors.New("the passphrase can't be empty")
+	}
+
+	repeated, err := readPassphrase("Repeat the passphrase: ")
+	if err != nil {
+		return err
+	}
+	if passphrase != repeated {
+		return errors.New("the passphrases don't match")
+	}
+
+	count, err := config.EncryptConfigFile(flgConfig, passphrase)
+	if err != nil {
+		return fmt.Errorf("couldn't encrypt the config file: %v", err)
+	}
+	fmt.Printf("encrypted %d secrets in %s\n", count, flgConfig)
+
+	return nil
+}
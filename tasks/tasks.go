@@ -0,0 +1,135 @@
+package tasks
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Task is an operation that is in progress, e.g. fetching the history of
+// a channel or downloading a file
+type Task struct {
+	ID          int
+	Description string
+	Progress    string // e.g. "42%", empty when it isn't known
+	Started     time.Time
+
+	cancel context.CancelFunc
+}
+
+// Manager keeps track of the tasks that are in progress, so that they can
+// be listed and cancelled. A task is cancelled with the context that it's
+// started with.
+type Manager struct {
+	// OnChange is called when a task is started, stopped, or its progress
+	// changes
+	OnChange func()
+
+	tasks  []*Task
+	nextID int
+	mu     sync.Mutex
+}
+
+// NewManager is the constructor for the Manager
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Start will add a task with the description, it returns the context that
+// the task has to use, which is cancelled with Cancel, and the id of the
+// task. The task has to be stopped with Done.
+func (m *Manager) Start(parent context.Context, description string) (context.Context, int) {
+	ctx, cancel := context.WithCancel(parent)
+
+	m.mu.Lock()
+	m.nextID++
+	id := m.nextID
+	m.tasks = append(m.tasks, &Task{
+		ID:          id,
+		Description: description,
+		Started:     time.Now(),
+		cancel:      cancel,
+	})
+	m.mu.Unlock()
+
+	m.changed()
+
+	return ctx, id
+}
+
+// SetProgress will set the progress of the task with id
+func (m *Manager) SetProgress(id int, progress string) {
+	m.mu.Lock()
+	task := m.find(id)
+	if task != nil {
+		task.Progress = progress
+	}
+	m.mu.Unlock()
+
+	if task != nil {
+		m.changed()
+	}
+}
+
+// Done will remove the task with id, once it has finished or has been
+// cancelled. Its context is cancelled as well, so it's only cancelled in
+// the Tasks popup when its request failed because of it.
+func (m *Manager) Done(id int) {
+	m.mu.Lock()
+	var task *Task
+	for i, t := range m.tasks {
+		if t.ID == id {
+			task = t
+			m.tasks = append(m.tasks[:i], m.tasks[i+1:]...)
+			break
+		}
+	}
+	m.mu.Unlock()
+
+	if task != nil {
+		task.cancel()
+		m.changed()
+	}
+}
+
+// Cancel will cancel the context of the task with id, it returns whether
+// the task is in progress. The task is removed once it has stopped.
+func (m *Manager) Cancel(id int) bool {
+	m.mu.Lock()
+	task := m.find(id)
+	m.mu.Unlock()
+
+	if task == nil {
+		return false
+	}
+
+	task.cancel()
+	return true
+}
+
+// List returns the tasks that are in progress, the oldest first
+func (m *Manager) List() []Task {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	list := make([]Task, 0, len(m.tasks))
+	for _, task := range m.tasks {
+		list = append(list, *task)
+	}
+	return list
+}
+
+func (m *Manager) find(id int) *Task {
+	for _, task := range m.tasks {
+		if task.ID == id {
+			return task
+		}
+	}
+	return nil
+}
+
+func (m *Manager) changed() {
+	if m.OnChange != nil {
+		m.OnChange()
+	}
+}
@@ -0,0 +1,182 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	termbox "github.com/nsf/termbox-go"
+)
+
+// Reauthenticate will show a prompt explaining why the client couldn't be
+// authorized, in which a new slack token and cookie can be entered. It
+// returns the entered token and cookie, and false when the prompt was
+// cancelled.
+func Reauthenticate(reason string, token string, cookie string) (string, string, bool) {
+	fields := [][]rune{[]rune(token), []rune(cookie)}
+	labels := []string{"Token:  ", "Cookie: "}
+	selected := 0
+
+	defer termbox.HideCursor()
+
+	for {
+		drawReauthenticate(reason, labels, fields, selected)
+
+		ev := termbox.PollEvent()
+		if ev.Type != termbox.EventKey {
+			continue
+		}
+
+		switch ev.Key {
+		case termbox.KeyEsc, termbox.KeyCtrlC:
+			return token, cookie, false
+		case termbox.KeyTab, termbox.KeyArrowDown, termbox.KeyArrowUp:
+			selected = (selected + 1) % len(fields)
+		case termbox.KeyEnter:
+			if selected < len(fields)-1 {
+				selected++
+				continue
+			}
+			return string(fields[0]), string(fields[1]), true
+		case termbox.KeyBackspace, termbox.KeyBackspace2:
+			if len(fields[selected]) > 0 {
+				fields[selected] = fields[selected][:len(fields[selected])-1]
+			}
+		case termbox.KeyCtrlU:
+			fields[selected] = fields[selected][:0]
+		case termbox.KeySpace:
+			fields[selected] = append(fields[selected], ' ')
+		default:
+			if ev.Ch != 0 {
+				fields[selected] = append(fields[selected], ev.Ch)
+			}
+		}
+	}
+}
+
+func drawReauthenticate(reason string, labels []string, fields [][]rune, selected int) {
+	lines := []string{
+		fmt.Sprintf("Unable to sign in to slack: %s", reason),
+		"",
+		"The slack token or cookie has probably expired, enter new ones to continue.",
+		"<tab> switches fields, <enter> continues, <esc> quits.",
+		"",
+	}
+
+	w, h := termbox.Size()
+	termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
+
+	offset := 2
+	y := (h / 2) - ((len(lines) + len(fields)) / 2)
+
+	for _, line := range lines {
+		drawText(offset, y, line, termbox.ColorDefault)
+		y++
+	}
+
+	for i, field := range fields {
+		fg := termbox.ColorDefault
+		if i == selected {
+			fg = termbox.ColorGreen
+		}
+
+		value := maskSecret(string(field))
+		drawText(offset, y, labels[i]+value, fg)
+
+		if i == selected {
+			x := offset + len(labels[i]) + len([]rune(value))
+			if x > w-1 {
+				x = w - 1
+			}
+			termbox.SetCursor(x, y)
+		}
+		y++
+	}
+
+	termbox.Flush()
+}
+
+// maskSecret will hide all but the last 4 characters of a secret
+func maskSecret(secret string) string {
+	runes := []rune(secret)
+	if len(runes) <= 4 {
+		return secret
+	}
+	return strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-4:])
+}
+
+func drawText(x int, y int, text string, fg termbox.Attribute) {
+	for i, r := range []rune(text) {
+		termbox.SetCell(x+i, y, r, fg, termbox.ColorDefault)
+	}
+}
+
+// AskPassphrase will show a prompt in which the passphrase of the encrypted
+// secrets of the config file can be entered, reason is shown when the
+// previous passphrase was wrong. It returns false when the prompt was
+// cancelled.
+func AskPassphrase(reason string) (string, bool) {
+	var passphrase []rune
+
+	defer termbox.HideCursor()
+
+	for {
+		drawAskPassphrase(reason, len(passphrase))
+
+		ev := termbox.PollEvent()
+		if ev.Type != termbox.EventKey {
+			continue
+		}
+
+		switch ev.Key {
+		case termbox.KeyEsc, termbox.KeyCtrlC:
+			return "", false
+		case termbox.KeyEnter:
+			return string(passphrase), true
+		case termbox.KeyBackspace, termbox.KeyBackspace2:
+			if len(passphrase) > 0 {
+				passphrase = passphrase[:len(passphrase)-1]
+			}
+		case termbox.KeyCtrlU:
+			passphrase = passphrase[:0]
+		case termbox.KeySpace:
+			passphrase = append(passphrase, ' ')
+		default:
+			if ev.Ch != 0 {
+				passphrase = append(passphrase, ev.Ch)
+			}
+		}
+	}
+}
+
+func drawAskPassphrase(reason string, length int) {
+	lines := []string{
+		"The slack token and cookie in the config file are encrypted.",
+		"<enter> continues, <esc> quits.",
+		"",
+	}
+	if reason != "" {
+		lines = append([]string{reason, ""}, lines...)
+	}
+
+	w, h := termbox.Size()
+	termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
+
+	offset := 2
+	y := (h / 2) - ((len(lines) + 1) / 2)
+
+	for _, line := range lines {
+		drawText(offset, y, line, termbox.ColorDefault)
+		y++
+	}
+
+	label := "Passphrase: "
+	drawText(offset, y, label+strings.Repeat("*", length), termbox.ColorGreen)
+
+	x := offset + len(label) + length
+	if x > w-1 {
+		x = w - 1
+	}
+	termbox.SetCursor(x, y)
+
+	termbox.Flush()
+}
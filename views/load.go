@@ -1,20 +1,114 @@
 package views
 
 import (
+	"fmt"
+
 	termbox "github.com/nsf/termbox-go"
 )
 
-func Loading() {
+const (
+	stageRunning = iota
+	stageDone
+	stageFailed
+)
+
+type stage struct {
+	name   string
+	detail string
+	state  int
+}
+
+// Progress shows the stages of starting slack-term, so it's clear what is
+// being waited on
+type Progress struct {
+	stages []stage
+}
+
+// Loading creates the Progress and shows it
+func Loading() *Progress {
+	p := &Progress{}
+	p.Draw()
+	return p
+}
+
+// Start will finish the current stage, and start the stage with name
+func (p *Progress) Start(name string) {
+	p.finish(stageDone)
+	p.stages = append(p.stages, stage{name: name})
+	p.Draw()
+}
+
+// Update will set the detail of the current stage, e.g. the number of
+// items that have been loaded
+func (p *Progress) Update(detail string) {
+	if len(p.stages) == 0 {
+		return
+	}
+	p.stages[len(p.stages)-1].detail = detail
+	p.Draw()
+}
+
+// Done will finish the current stage
+func (p *Progress) Done() {
+	p.finish(stageDone)
+	p.Draw()
+}
+
+// Fail will mark the current stage as failed
+func (p *Progress) Fail() {
+	p.finish(stageFailed)
+	p.Draw()
+}
+
+func (p *Progress) finish(state int) {
+	if len(p.stages) == 0 {
+		return
+	}
+	if current := &p.stages[len(p.stages)-1]; current.state == stageRunning {
+		current.state = state
+	}
+}
+
+// Draw will show the stages in the center of the terminal
+func (p *Progress) Draw() {
 	const loading string = "LOADING"
 
+	lines := []string{loading, ""}
+	for _, s := range p.stages {
+		var icon string
+		switch s.state {
+		case stageRunning:
+			icon = "…"
+		case stageDone:
+			icon = "✓"
+		case stageFailed:
+			icon = "✗"
+		}
+
+		line := fmt.Sprintf("%s %s", icon, s.name)
+		if s.detail != "" {
+			line = fmt.Sprintf("%s (%s)", line, s.detail)
+		}
+		lines = append(lines, line)
+	}
+
+	// The stages are aligned with each other, and the block is centered
+	width := 0
+	for _, line := range lines {
+		if n := len([]rune(line)); n > width {
+			width = n
+		}
+	}
+
 	w, h := termbox.Size()
 	termbox.Clear(termbox.ColorDefault, termbox.ColorDefault)
 
-	offset := (w / 2) - (len(loading) / 2)
-	y := h / 2
+	offset := (w / 2) - (width / 2)
+	y := (h / 2) - (len(lines) / 2)
 
-	for x := 0; x < len(loading); x++ {
-		termbox.SetCell(offset+x, y, rune(loading[x]), termbox.ColorDefault, termbox.ColorDefault)
+	drawText((w/2)-(len(loading)/2), y, loading, termbox.ColorDefault)
+	for i, line := range lines[1:] {
+		drawText(offset, y+i+1, line, termbox.ColorDefault)
 	}
 
 	termbox.Flush()
@@ -0,0 +1,43 @@
+package views
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// TitleOutput is where the escape sequences of the title of the terminal
+// window are written to
+var TitleOutput io.Writer = os.Stdout
+
+// SaveTitle will save the title of the terminal window, so it can be
+// restored with RestoreTitle when slack-term exits
+func SaveTitle() {
+	fmt.Fprint(TitleOutput, "\033[22;0t")
+}
+
+// RestoreTitle will restore the title of the terminal window that was
+// saved with SaveTitle
+func RestoreTitle() {
+	fmt.Fprint(TitleOutput, "\033[23;0t")
+}
+
+// SetTitle will set the title of the terminal window, which is shown in
+// e.g. the taskbar and the window list of tmux. The title holds the names
+// of channels, so control characters are removed, they would end the
+// escape sequence.
+func SetTitle(title string) {
+	fmt.Fprintf(TitleOutput, "\033]0;%s\007", sanitizeTitle(title))
+}
+
+// sanitizeTitle will remove the control characters of the title
+func sanitizeTitle(title string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, title)
+}
@@ -0,0 +1,32 @@
+package views
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSetTitle(t *testing.T) {
+	var buf bytes.Buffer
+	output := TitleOutput
+	TitleOutput = &buf
+	defer func() { TitleOutput = output }()
+
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{"slack-term #general", "\033]0;slack-term #general\007"},
+		{"#evil\007\033]0;owned", "\033]0;#evil]0;owned\007"},
+		{"tab\tand\nnewline", "\033]0;tabandnewline\007"},
+		{"c1 \u009d control", "\033]0;c1  control\007"},
+		{"émoji 🎉", "\033]0;émoji 🎉\007"},
+	}
+
+	for _, test := range tests {
+		buf.Reset()
+		SetTitle(test.title)
+		if buf.String() != test.expected {
+			t.Errorf("SetTitle(%q) wrote %q, expected %q", test.title, buf.String(), test.expected)
+		}
+	}
+}
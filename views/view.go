@@ -1,6 +1,7 @@
 package views
 
 import (
+	"context"
 	"fmt"
 	"github.com/erroneousboat/termui"
 
@@ -10,35 +11,99 @@ import (
 )
 
 type View struct {
-	Config   *config.Config
-	Input    *components.Input
-	Chat     *components.Chat
-	Channels *components.Channels
-	Threads  *components.Threads
-	Mode     *components.Mode
-	Debug    *components.Debug
+	Config     *config.Config
+	Input      *components.Input
+	Chat       *components.Chat
+	Channels   *components.Channels
+	Threads    *components.Threads
+	Browser    *components.Browser
+	Files      *components.Files
+	Mentions   *components.Mentions
+	FollowUps  *components.FollowUps
+	Members    *components.Members
+	Emoji      *components.EmojiPicker
+	Completion *components.Completion
+	Switcher   *components.Switcher
+	Info       *components.ChannelInfo
+	Tasks      *components.Tasks
+	Confirm    *components.Confirm
+	Toast      *components.Toast
+	Mode       *components.Mode
+	Debug      *components.Debug
+
+	// The spinners show the operations that are in progress in the
+	// Channels, Chat, Threads and Files panes
+	ChannelsSpinner *components.Spinner
+	ChatSpinner     *components.Spinner
+	ThreadsSpinner  *components.Spinner
+	FilesSpinner    *components.Spinner
+
+	// ChannelsCursor is the cursor of the next page of conversations that
+	// hasn't been loaded into the Channels component yet
+	ChannelsCursor string
 }
 
-func CreateView(config *config.Config, svc *service.SlackService) (*View, error) {
+// selectStartupChannel will select the conversation with the oldest unread
+// mention, or the most recent direct message, when startup_channel is set
+// to unread. It's only selected when it's part of the channels that have
+// been loaded.
+func selectStartupChannel(cfg *config.Config, svc service.ChatService, channels *components.Channels, progress *Progress) {
+	if cfg.StartupChannel != config.StartupUnread {
+		return
+	}
+
+	progress.Start("Finding unread conversations")
+	channelID, err := svc.GetStartupChannel(context.Background())
+	if err != nil {
+		progress.Fail()
+		return
+	}
+	progress.Done()
+
+	if channelID == "" {
+		return
+	}
+
+	if index := channels.FindChannel(channelID); index != -1 {
+		channels.SetSelectedChannel(index)
+	}
+}
+
+// CreateView will create the components, and fill them with the channels
+// and the messages of the selected channel. The stages are shown on the
+// progress screen.
+func CreateView(config *config.Config, svc service.ChatService, progress *Progress) (*View, error) {
 	// Create Input component
 	input := components.CreateInputComponent()
 
 	// Channels: create the component
 	sideBarHeight := termui.TermHeight() - input.Par.Height
 	channels := components.CreateChannelsComponent(sideBarHeight)
+	channels.ShowDeactivated = config.ShowDeactivated
+	for _, channelType := range config.HiddenTypes {
+		if err := channels.SetTypeHidden(channelType, true); err != nil {
+			return nil, fmt.Errorf("unsupported setting for hidden_channel_types: %v", err)
+		}
+	}
 
 	// Channels: fill the component
+	progress.Start("Loading channels")
 	var slackChans []components.ChannelItem
+	var channelsCursor string
 	var err error
 	if config.IsEnterprise {
-		slackChans, err = svc.GetConversationsForUser()
+		slackChans, err = svc.GetConversationsForUser(context.Background())
 	} else {
-		slackChans, err = svc.GetChannels(true)
+		// Only the first page is fetched, the remaining pages are
+		// loaded after the view is shown
+		slackChans, channelsCursor, err = svc.GetChannelsPage(context.Background(), "")
 	}
 
 	if err != nil {
+		progress.Fail()
 		return nil, err
 	}
+	progress.Update(fmt.Sprintf("%d", len(slackChans)))
 
 	// Channels: set channels in component
 	channels.SetChannels(slackChans)
@@ -47,23 +112,43 @@ func CreateView(config *config.Config, svc *service.SlackService) (*View, error)
 		return nil, fmt.Errorf("no channels available")
 	}
 
+	// Channels: select the most important conversation
+	selectStartupChannel(config, svc, channels, progress)
+
 	selectedChannel := channels.GetSelectedChannel()
 
 	// Threads: create component
-	threads := components.CreateThreadsComponent(sideBarHeight)
+	threads := components.CreateThreadsComponent(input.Par.Height)
+
+	// Browser: create component
+	browser := components.CreateBrowserComponent(sideBarHeight)
 
 	// Chat: create the component
 	chat := components.CreateChatComponent(input.Par.Height)
+	chat.DateFormat = config.Theme.Message.DateFormat
+	chat.StyleHeader = config.Theme.Channel.Header
+	chat.GroupMinutes = config.GroupMinutes
+	chat.ShowSeconds = config.ShowSeconds
+	chat.ExpandAttachments = config.ExpandAttachments
 
 	// Chat: fill the component
+	progress.Start(fmt.Sprintf("Loading messages of %s", selectedChannel.GetChannelName()))
+	historyCount := config.HistoryCount
+	if historyCount == 0 {
+		historyCount = chat.GetMaxItems()
+	}
+
 	msgs, thr, err := svc.GetMessages(
+		context.Background(),
 		selectedChannel.ID,
-		chat.GetMaxItems(),
-		1,
+		historyCount,
+		config.HistoryDays,
 	)
 	if err != nil {
+		progress.Fail()
 		return nil, err
 	}
+	progress.Done()
 
 	// Chat: set messages in component
 	chat.SetMessages(msgs)
@@ -72,18 +157,45 @@ func CreateView(config *config.Config, svc *service.SlackService) (*View, error)
 		selectedChannel.GetChannelName(),
 	)
 
-	// Threads: set threads in component
+	// Threads: set threads in component, and show the most recent one
 	if len(thr) > 0 {
-
-		// Make the first thread the current Channel
-		threads.SetChannels(
-			append(
-				[]components.ChannelItem{channels.GetSelectedChannel()},
-				thr...,
-			),
-		)
+		threads.SetThreads(thr)
+		threads.SetThread(chat.Messages[threads.GetSelectedThread().ID])
 	}
 
+	// Files: create the component, it's filled when it's opened
+	files := components.CreateFilesComponent(input.Par.Height)
+
+	// Mentions: create the component, it's filled when it's opened
+	mentions := components.CreateMentionsComponent(input.Par.Height)
+
+	// FollowUps: create the component, it's filled when it's opened
+	followUps := components.CreateFollowUpsComponent(input.Par.Height)
+
+	// Members: create the component, it's filled when it's opened
+	members := components.CreateMembersComponent(input.Par.Height)
+
+	// Emoji: create the component, it's filled when it's opened
+	emoji := components.CreateEmojiPickerComponent(input.Par.Height)
+
+	// Completion: create the component, it's filled while typing
+	completion := components.CreateCompletionComponent()
+
+	// Switcher: create the component, it's filled when it's opened
+	switcher := components.CreateSwitcherComponent()
+
+	// Info: create the component, it's filled when it's opened
+	info := components.CreateChannelInfoComponent()
+
+	// Tasks: create the component, it's filled when it's opened
+	tasks := components.CreateTasksComponent()
+
+	// Confirm: create the component, it's filled when it's shown
+	confirm := components.CreateConfirmComponent()
+
+	// Toast: create the component, it's filled when an action is confirmed
+	toast := components.CreateToastComponent()
+
 	// Debug: create the component
 	debug := components.CreateDebugComponent(input.Par.Height)
 
@@ -91,13 +203,33 @@ func CreateView(config *config.Config, svc *service.SlackService) (*View, error)
 	mode := components.CreateModeComponent()
 
 	view := &View{
-		Config:   config,
-		Input:    input,
-		Channels: channels,
-		Threads:  threads,
-		Chat:     chat,
-		Mode:     mode,
-		Debug:    debug,
+		Config:     config,
+		Input:      input,
+		Channels:   channels,
+		Threads:    threads,
+		Browser:    browser,
+		Files:      files,
+		Mentions:   mentions,
+		FollowUps:  followUps,
+		Members:    members,
+		Emoji:      emoji,
+		Completion: completion,
+		Switcher:   switcher,
+		Info:       info,
+		Tasks:      tasks,
+		Confirm:    confirm,
+		Toast:      toast,
+		Chat:       chat,
+		Mode:       mode,
+		Debug:      debug,
+
+		// Spinners: create the components on the panes they're drawn on
+		ChannelsSpinner: components.CreateSpinnerComponent(&channels.List.Block),
+		ChatSpinner:     components.CreateSpinnerComponent(&chat.List.Block),
+		ThreadsSpinner:  components.CreateSpinnerComponent(&threads.List.Block),
+		FilesSpinner:    components.CreateSpinnerComponent(&files.List.Block),
+
+		ChannelsCursor: channelsCursor,
 	}
 
 	return view, nil